@@ -0,0 +1,10 @@
+// Package examples embeds the example hook scripts, which `wt setup --hooks`
+// copies into a repository
+package examples
+
+import "embed"
+
+// Hooks holds the scripts in hooks/
+//
+//go:embed hooks/*.sh
+var Hooks embed.FS
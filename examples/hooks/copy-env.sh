@@ -5,6 +5,11 @@
 #   hooks:
 #     post_create:
 #       - script: ./examples/hooks/copy-env.sh
+#
+# The built-in files config does the same without a script:
+#   files:
+#     copy:
+#       - .env*
 
 set -e
 
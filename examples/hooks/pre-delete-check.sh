@@ -10,8 +10,8 @@ set -e
 
 cd "$WT_PATH"
 
-# Check for uncommitted changes
-if [[ -n $(git status --porcelain) ]]; then
+# Check for uncommitted changes (WT_DIRTY is computed by wt before the hook runs)
+if [[ "$WT_DIRTY" == "true" ]]; then
   echo "Warning: Worktree '$WT_NAME' has uncommitted changes:"
   git status --short
   echo
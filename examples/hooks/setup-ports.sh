@@ -37,3 +37,8 @@ echo "Configured ports with offset $PORT_OFFSET (index #$WT_INDEX)"
 echo "  VITE_PORT=$((5173 + PORT_OFFSET))"
 echo "  API_PORT=$((3000 + PORT_OFFSET))"
 echo "Port config written to .wt-ports.env"
+
+# Also load the ports into the shell when entering the worktree (see wt env)
+if [ -n "$WT_ENV_FILE" ]; then
+    cat "$WT_PATH/.wt-ports.env" >> "$WT_ENV_FILE"
+fi
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 
 	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +21,8 @@ var cdCmd = &cobra.Command{
 	Long: `Output the path to a worktree directory.
 
 The shell integration wrapper will use this output to change
-to the worktree directory.
+to the worktree directory, then run any post_switch hooks
+defined in .wt.yaml.
 
 Note: This command requires shell integration. Add this to your
 shell rc file:
@@ -46,14 +49,44 @@ func runCd(cmd *cobra.Command, args []string) error {
 	}
 
 	// Determine the worktree path
-	worktreePath := filepath.Join(repoRoot, cfg.WorktreeDir, name)
+	worktreePath := filepath.Join(cfg.WorktreesDir(repoRoot), name)
 
 	// Check if worktree exists
-	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
+	if !git.IsWorktreeCheckout(worktreePath) {
 		return fmt.Errorf("worktree %q does not exist", name)
 	}
 
-	// Output the path to stdout (shell wrapper will handle the actual cd)
-	_, _ = fmt.Fprintln(cmd.OutOrStdout(), worktreePath)
+	switchToWorktree(cmd, repoRoot, cfg, name, worktreePath)
 	return nil
 }
+
+// switchToWorktree outputs a worktree's path for the shell wrapper to cd to and runs
+// post_switch hooks, or prints the path when invoked directly
+func switchToWorktree(cmd *cobra.Command, repoRoot string, cfg *config.Config, name, worktreePath string) {
+	cdFile := os.Getenv("WT_CD_FILE")
+	if cdFile == "" {
+		// Direct invocation: output the path to stdout
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), worktreePath)
+		return
+	}
+
+	// Shell wrapper mode: write path to file for cd, then run post-switch hooks
+	_ = os.WriteFile(cdFile, []byte(worktreePath+"\n"), 0600)
+
+	if len(cfg.Hooks.PostSwitch) > 0 {
+		branch, _ := git.GetCurrentBranch(worktreePath)
+		env := &hooks.Env{
+			Name:        name,
+			Path:        worktreePath,
+			Branch:      branch,
+			RepoRoot:    repoRoot,
+			WorktreeDir: cfg.WorktreeDir,
+		}
+		if idx, err := git.OpenMetadata(repoRoot).Index(name); err == nil {
+			env.Index = idx
+		}
+		if err := hooks.RunPostSwitch(cfg, env); err != nil {
+			cmd.Printf("Warning: post-switch hook failed: %v\n", err)
+		}
+	}
+}
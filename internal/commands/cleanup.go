@@ -45,9 +45,30 @@ type cleanupCandidate struct {
 	name   string
 	path   string
 	branch string
+	index  int
 	status *git.WorktreeStatus
 }
 
+// hookEnv builds the hook environment for a single candidate
+func (c cleanupCandidate) hookEnv(setup *CompareSetup) *hooks.Env {
+	env := &hooks.Env{
+		Name:        c.name,
+		Path:        c.path,
+		Branch:      c.branch,
+		RepoRoot:    setup.RepoRoot,
+		WorktreeDir: setup.Config.WorktreeDir,
+		Index:       c.index,
+		Force:       cleanupForce,
+	}
+	setHookStatus(env, setup.ComparisonRef, c.status)
+	return env
+}
+
+// ref returns the candidate's description for repo-wide cleanup hooks
+func (c cleanupCandidate) ref() hooks.WorktreeRef {
+	return hooks.WorktreeRef{Name: c.name, Path: c.path, Branch: c.branch, Index: c.index}
+}
+
 func runCleanup(cmd *cobra.Command, args []string) error {
 	// Setup comparison context (prints repo root, fetches if configured, prints comparison ref)
 	setup, err := SetupCompare(cmd)
@@ -55,73 +76,10 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Get all worktrees
-	worktrees, err := git.ListWorktrees(setup.RepoRoot)
-	if err != nil {
-		return fmt.Errorf("failed to list worktrees: %w", err)
-	}
-
-	worktreesDir := filepath.Join(setup.RepoRoot, setup.Config.WorktreeDir)
-
-	// Get merged branches cache for efficiency
-	mergedCache, err := git.GetMergedBranches(setup.RepoRoot, setup.ComparisonRef)
-	if err != nil {
-		cmd.Printf("Warning: could not get merged branches: %v\n", err)
-	}
-
 	// Find candidates for cleanup
-	var candidates []cleanupCandidate
-
-	for _, wt := range worktrees {
-		// Skip the main worktree
-		if wt.Path == setup.RepoRoot {
-			continue
-		}
-
-		// Check if this worktree is in our managed directory
-		if !strings.HasPrefix(wt.Path, worktreesDir) {
-			continue
-		}
-
-		// Get worktree name
-		name := git.GetWorktreeName(setup.RepoRoot, wt.Path, setup.Config.WorktreeDir)
-
-		// Skip if no branch (detached HEAD)
-		if wt.Branch == "" {
-			continue
-		}
-
-		// Get full worktree status
-		status, err := git.GetWorktreeStatus(setup.RepoRoot, wt.Path, name, wt.Branch, setup.ComparisonRef, mergedCache)
-		if err != nil {
-			cmd.Printf("Warning: could not get status for %s: %v\n", name, err)
-			continue
-		}
-
-		// Skip worktrees with uncommitted changes
-		if status.HasUncommittedChanges {
-			continue
-		}
-
-		// Skip new worktrees (no commits yet - still being worked on)
-		if status.IsNew {
-			continue
-		}
-
-		// Skip worktrees with commits ahead of main (unmerged work)
-		if status.CommitsAhead > 0 {
-			continue
-		}
-
-		// Only cleanup if merged
-		if status.IsMerged {
-			candidates = append(candidates, cleanupCandidate{
-				name:   name,
-				path:   wt.Path,
-				branch: wt.Branch,
-				status: status,
-			})
-		}
+	candidates, err := findCleanupCandidates(cmd, setup)
+	if err != nil {
+		return err
 	}
 
 	// No candidates found
@@ -177,32 +135,39 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Run post-merge hooks for candidates merged since we last looked
+	for _, c := range candidates {
+		notifyMerged(cmd, setup.Config, c.hookEnv(setup), c.status)
+	}
+
 	// Check if user is in any of the worktrees being deleted
 	cwd, _ := os.Getwd()
 	inDeletedWorktree := false
 	for _, c := range candidates {
-		if strings.HasPrefix(cwd, c.path) {
+		if cwd == c.path || strings.HasPrefix(cwd, c.path+string(filepath.Separator)) {
 			inDeletedWorktree = true
 			break
 		}
 	}
 
+	// Run pre-cleanup hooks once with the full candidate list
+	refs := make([]hooks.WorktreeRef, 0, len(candidates))
+	for _, c := range candidates {
+		refs = append(refs, c.ref())
+	}
+	if err := hooks.RunPreCleanup(setup.Config, cleanupHookEnv(setup, refs)); err != nil {
+		if !cleanupForce {
+			return fmt.Errorf("pre-cleanup hook failed: %w", err)
+		}
+		cmd.Printf("Warning: pre-cleanup hook failed: %v\n", err)
+	}
+
 	// Delete each candidate
 	var deleted int
+	deletedRefs := []hooks.WorktreeRef{}
 	for _, c := range candidates {
 		// Create hook environment
-		env := &hooks.Env{
-			Name:        c.name,
-			Path:        c.path,
-			Branch:      c.branch,
-			RepoRoot:    setup.RepoRoot,
-			WorktreeDir: setup.Config.WorktreeDir,
-		}
-
-		// Get index for hooks
-		if idx, err := git.GetWorktreeIndex(setup.RepoRoot, c.name); err == nil {
-			env.Index = idx
-		}
+		env := c.hookEnv(setup)
 
 		// Run pre-delete hooks
 		if err := hooks.RunPreDelete(setup.Config, env); err != nil {
@@ -219,6 +184,7 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 			cmd.Printf("Error: failed to delete %s: %v\n", c.name, err)
 			continue
 		}
+		git.RemoveEmptyParents(setup.Config.WorktreesDir(setup.RepoRoot), c.path)
 
 		// Delete the branch unless --keep-branch is specified
 		if !cleanupKeepBranch && c.branch != "" {
@@ -234,6 +200,12 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 		}
 
 		deleted++
+		deletedRefs = append(deletedRefs, c.ref())
+	}
+
+	// Run post-cleanup hooks once with the worktrees actually deleted
+	if err := hooks.RunPostCleanup(setup.Config, cleanupHookEnv(setup, deletedRefs)); err != nil {
+		cmd.Printf("Warning: post-cleanup hook failed: %v\n", err)
 	}
 
 	cmd.Printf("Cleaned up %d worktree(s)\n", deleted)
@@ -251,3 +223,84 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// findCleanupCandidates returns the managed worktrees that are merged, clean and not new
+func findCleanupCandidates(cmd *cobra.Command, setup *CompareSetup) ([]cleanupCandidate, error) {
+	// Get the managed worktrees
+	worktrees, err := managedWorktrees(setup.RepoRoot, setup.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	worktreesDir := setup.Config.WorktreesDir(setup.RepoRoot)
+
+	// Get merged branches cache for efficiency
+	mergedCache, err := git.GetMergedBranches(setup.RepoRoot, setup.ComparisonRef)
+	if err != nil {
+		cmd.Printf("Warning: could not get merged branches: %v\n", err)
+	}
+
+	var candidates []cleanupCandidate
+
+	for _, wt := range worktrees {
+		// Never remove a bare repository's default worktree
+		if wt.Path == setup.RepoRoot {
+			continue
+		}
+
+		// Get worktree name
+		name := git.GetWorktreeName(worktreesDir, wt.Path)
+
+		// Skip if no branch (detached HEAD)
+		if wt.Branch == "" {
+			continue
+		}
+
+		// Get full worktree status
+		status, err := git.GetWorktreeStatus(setup.RepoRoot, wt.Path, name, wt.Branch, setup.ComparisonRef, mergedCache)
+		if err != nil {
+			cmd.Printf("Warning: could not get status for %s: %v\n", name, err)
+			continue
+		}
+
+		// Skip worktrees with uncommitted changes
+		if status.HasUncommittedChanges {
+			continue
+		}
+
+		// Skip new worktrees (no commits yet - still being worked on)
+		if status.IsNew {
+			continue
+		}
+
+		// Skip worktrees with commits ahead of main (unmerged work)
+		if status.CommitsAhead > 0 {
+			continue
+		}
+
+		// Only cleanup if merged
+		if status.IsMerged {
+			idx, _ := git.OpenMetadata(setup.RepoRoot).Index(name)
+			candidates = append(candidates, cleanupCandidate{
+				name:   name,
+				path:   wt.Path,
+				branch: wt.Branch,
+				index:  idx,
+				status: status,
+			})
+		}
+	}
+
+	return candidates, nil
+}
+
+// cleanupHookEnv builds the hook environment for repo-wide cleanup hooks
+func cleanupHookEnv(setup *CompareSetup, refs []hooks.WorktreeRef) *hooks.Env {
+	return &hooks.Env{
+		RepoRoot:      setup.RepoRoot,
+		WorktreeDir:   setup.Config.WorktreeDir,
+		Force:         cleanupForce,
+		ComparisonRef: setup.ComparisonRef,
+		Worktrees:     refs,
+	}
+}
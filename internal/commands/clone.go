@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cloneBare  bool
+	cloneHooks bool
+)
+
+func init() {
+	cloneCmd.Flags().BoolVar(&cloneBare, "bare", false, "Clone as a bare repository with the default branch checked out next to it")
+	cloneCmd.Flags().BoolVar(&cloneHooks, "hooks", false, "Copy example hook scripts into scripts/ and configure them")
+	rootCmd.AddCommand(cloneCmd)
+}
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <url> [directory]",
+	Short: "Clone a repository and set it up for wt",
+	Long: `Clone a repository and, unless it has a .wt.yaml already, set it up as
+wt setup --yes would. The directory defaults to the repository's name.
+
+With --bare, the directory holds the bare repository, repo.git, and a
+worktree for the default branch, which becomes the default worktree.
+Worktrees are created next to it:
+
+  project/
+  ├── repo.git/
+  ├── main/
+  └── feature-x/
+
+The path of the checkout is printed, or with shell integration, changed to.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runClone,
+}
+
+func runClone(cmd *cobra.Command, args []string) error {
+	url := args[0]
+	dir := repoNameFromURL(url)
+	if len(args) > 1 {
+		dir = args[1]
+	}
+	if dir == "" {
+		return fmt.Errorf("can't tell the repository's name from %s; give a directory", url)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+
+	repoRoot := dir
+	if cloneBare {
+		if repoRoot, err = cloneBareLayout(url, dir); err != nil {
+			return err
+		}
+	} else if err := git.Clone(url, dir, false); err != nil {
+		return fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+
+	if config.Exists(repoRoot) {
+		cmd.Printf("Using the repository's %s\n", config.ConfigFileName)
+	} else {
+		opts := defaultSetupOptions(repoRoot)
+		opts.exampleHooks = cloneHooks
+		if err := setupRepo(cmd, repoRoot, opts); err != nil {
+			return err
+		}
+	}
+
+	if cdFile := os.Getenv("WT_CD_FILE"); cdFile != "" {
+		_ = os.WriteFile(cdFile, []byte(repoRoot+"\n"), 0600)
+	} else {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), repoRoot)
+	}
+	return nil
+}
+
+// cloneBareLayout clones url as dir/repo.git and checks out its default branch in a
+// worktree next to it, which it designates the default worktree. It returns the
+// worktree's path.
+func cloneBareLayout(url, dir string) (string, error) {
+	bareDir := filepath.Join(dir, "repo.git")
+	if err := git.Clone(url, bareDir, true); err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+	branch, err := git.GetDefaultBranch(bareDir)
+	if err != nil {
+		if branch, err = git.GetCurrentBranch(bareDir); err != nil {
+			return "", fmt.Errorf("failed to find the default branch: %w", err)
+		}
+	}
+
+	worktreePath := filepath.Join(dir, filepath.FromSlash(branch))
+	if err := git.CreateWorktreeFromBranch(bareDir, worktreePath, branch); err != nil {
+		return "", fmt.Errorf("failed to check out %s: %w", branch, err)
+	}
+	rel, err := filepath.Rel(bareDir, worktreePath)
+	if err != nil {
+		return "", err
+	}
+	if err := config.SetDefaultWorktree(bareDir, filepath.ToSlash(rel)); err != nil {
+		return "", fmt.Errorf("failed to set the default worktree: %w", err)
+	}
+	return worktreePath, nil
+}
+
+// repoNameFromURL returns the directory git clone would use for url: its last path
+// element without .git
+func repoNameFromURL(url string) string {
+	name := strings.TrimRight(url, "/")
+	if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, ".git")
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultListColumns is the compact list layout used when --columns isn't given
+const defaultListColumns = "name,index,branch,status"
+
+// hookColumnPrefix selects a field reported by a JSON info hook, e.g. hook.port
+const hookColumnPrefix = "hook."
+
+// listColumn describes a column of the compact list table
+type listColumn struct {
+	header     string
+	rightAlign bool
+	value      func(wt worktreeInfo) string
+}
+
+// builtinListColumns maps --columns names to the built-in columns
+var builtinListColumns = map[string]listColumn{
+	"name":   {header: "NAME", value: func(wt worktreeInfo) string { return wt.name }},
+	"branch": {header: "BRANCH", value: func(wt worktreeInfo) string { return wt.branch }},
+	"path":   {header: "PATH", value: func(wt worktreeInfo) string { return wt.path }},
+	"status": {header: "STATUS", value: func(wt worktreeInfo) string { return FormatCompactStatus(wt.status) }},
+	"index": {header: "INDEX", rightAlign: true, value: func(wt worktreeInfo) string {
+		if wt.index > 0 {
+			return fmt.Sprintf("%d", wt.index)
+		}
+		return "-"
+	}},
+	"created": {header: "CREATED", value: func(wt worktreeInfo) string {
+		if wt.status == nil || wt.status.CreatedAt.IsZero() {
+			return "-"
+		}
+		return wt.status.CreatedAt.Format("2006-01-02")
+	}},
+}
+
+// listColumnNames returns the built-in column names in a stable order for help and errors
+func listColumnNames() []string {
+	return []string{"name", "index", "branch", "status", "path", "created"}
+}
+
+// parseListColumns parses a --columns value. needsHooks reports whether any column
+// comes from info hooks, so callers only run them when required.
+func parseListColumns(spec string) (columns []listColumn, needsHooks bool, err error) {
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if key, ok := strings.CutPrefix(name, hookColumnPrefix); ok && key != "" {
+			columns = append(columns, hookColumn(key))
+			needsHooks = true
+			continue
+		}
+
+		col, ok := builtinListColumns[strings.ToLower(name)]
+		if !ok {
+			return nil, false, fmt.Errorf("unknown column %q (valid: %s, or hook.<field>)", name, strings.Join(listColumnNames(), ", "))
+		}
+		columns = append(columns, col)
+	}
+
+	if len(columns) == 0 {
+		return nil, false, fmt.Errorf("no columns specified")
+	}
+	return columns, needsHooks, nil
+}
+
+// hookColumn returns a column showing the named field from JSON info hooks
+func hookColumn(key string) listColumn {
+	return listColumn{
+		header: strings.ToUpper(key),
+		value: func(wt worktreeInfo) string {
+			if f, ok := wt.hookInfo.Field(key); ok {
+				return FormatHookField(f)
+			}
+			return "-"
+		},
+	}
+}
+
+// printWorktreeTable prints worktrees as a table with the given columns.
+// Every column but the last is padded to its widest value.
+func printWorktreeTable(out io.Writer, worktrees []worktreeInfo, columns []listColumn) {
+	// Render cells up front so widths account for hook values and escape codes
+	cells := make([][]string, len(worktrees))
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = visibleWidth(col.header)
+	}
+	for r, wt := range worktrees {
+		cells[r] = make([]string, len(columns))
+		for i, col := range columns {
+			cells[r][i] = col.value(wt)
+			if w := visibleWidth(cells[r][i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.header
+	}
+
+	_, _ = fmt.Fprintln(out, "  "+formatRow(headers, columns, widths))
+	for r, wt := range worktrees {
+		_, _ = fmt.Fprintln(out, wt.currentMarker+formatRow(cells[r], columns, widths))
+	}
+}
+
+// formatRow pads and joins one table row
+func formatRow(values []string, columns []listColumn, widths []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		padding := strings.Repeat(" ", max(widths[i]-visibleWidth(v), 0))
+		switch {
+		case columns[i].rightAlign:
+			parts[i] = padding + v
+		case i < len(values)-1:
+			parts[i] = v + padding
+		default:
+			parts[i] = v
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// completeListColumns completes the last entry of a comma-separated --columns value
+func completeListColumns(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	var completions []string
+	for _, name := range append(listColumnNames(), hookColumnPrefix) {
+		if strings.HasPrefix(prefix+name, toComplete) {
+			completions = append(completions, prefix+name)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
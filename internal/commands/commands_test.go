@@ -2,11 +2,14 @@ package commands
 
 import (
 	"bytes"
+	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/agarcher/wt/internal/git"
 )
@@ -14,6 +17,7 @@ import (
 // resetFlags resets command flags to their default values between tests
 func resetFlags() {
 	createBranch = ""
+	createForce = false
 	deleteForce = false
 	deleteKeepBranch = false
 	cleanupDryRun = false
@@ -23,6 +27,26 @@ func resetFlags() {
 	configUnset = false
 	configList = false
 	configShowOrigin = false
+	configMigrateDryRun = false
+	logsHook = ""
+	logsFollow = false
+	logsList = false
+	verboseFlag = false
+	listJSON = false
+	listColumns = defaultListColumns
+	infoJSON = false
+	hookRunDryRun = false
+	indexCompactDryRun = false
+	envShell = ""
+	promptFormat = defaultPromptFormat
+	promptTimeout = defaultPromptTimeout
+	setupWorktreeDir = ""
+	setupDefaultBranch = ""
+	setupHooks = false
+	setupYes = false
+	setupForce = false
+	cloneBare = false
+	cloneHooks = false
 }
 
 // setupTestRepo creates a temporary git repository with .wt.yaml for testing
@@ -322,7 +346,7 @@ func TestCreateAllocatesIndex(t *testing.T) {
 		t.Fatalf("create command failed: %v", err)
 	}
 
-	index1, err := git.GetWorktreeIndex(repoRoot, "wt-one")
+	index1, err := git.OpenMetadata(repoRoot).Index("wt-one")
 	if err != nil {
 		t.Fatalf("failed to get index for wt-one: %v", err)
 	}
@@ -336,7 +360,7 @@ func TestCreateAllocatesIndex(t *testing.T) {
 		t.Fatalf("create command failed: %v", err)
 	}
 
-	index2, err := git.GetWorktreeIndex(repoRoot, "wt-two")
+	index2, err := git.OpenMetadata(repoRoot).Index("wt-two")
 	if err != nil {
 		t.Fatalf("failed to get index for wt-two: %v", err)
 	}
@@ -356,7 +380,7 @@ func TestCreateAllocatesIndex(t *testing.T) {
 		t.Fatalf("create command failed: %v", err)
 	}
 
-	index3, err := git.GetWorktreeIndex(repoRoot, "wt-three")
+	index3, err := git.OpenMetadata(repoRoot).Index("wt-three")
 	if err != nil {
 		t.Fatalf("failed to get index for wt-three: %v", err)
 	}
@@ -418,6 +442,131 @@ func TestListShowsIndex(t *testing.T) {
 	_, _, _ = executeCommand("delete", "indexed-wt", "--force")
 }
 
+func TestCreateProvisionsFiles(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	// Untracked files in the main repo, as a team would keep secrets and local config
+	if err := os.WriteFile(filepath.Join(repoRoot, ".env"), []byte("SECRET=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, ".env.local.tmpl"), []byte("PORT={index*10+5173}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(repoRoot, "cache", "deps"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, "cache", "deps", "lib.a"), []byte("lib"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// post_create hooks run after provisioning, so they can rely on the files
+	writeHookScript(t, repoRoot, "check.sh", `cat .env.local > "$WT_REPO_ROOT/seen.txt"`+"\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+files:
+  copy:
+    - .env
+  template:
+    - .env.local.tmpl
+clone_dirs:
+  - cache
+hooks:
+  post_create:
+    - script: check.sh
+`)
+
+	stdout, _, err := executeCommand("create", "provisioned")
+	if err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "provisioned", "--force") }()
+
+	if !strings.Contains(stdout, "copied .env\n") || !strings.Contains(stdout, "rendered .env.local\n") {
+		t.Errorf("expected provisioning report, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "Cloning directories...\n  cache: 1 files, 3 B (") {
+		t.Errorf("expected clone summary, got:\n%s", stdout)
+	}
+
+	worktreePath := filepath.Join(repoRoot, "worktrees", "provisioned")
+	data, err := os.ReadFile(filepath.Join(worktreePath, ".env"))
+	if err != nil || string(data) != "SECRET=1\n" {
+		t.Errorf("expected .env to be copied, got %q (%v)", data, err)
+	}
+	data, err = os.ReadFile(filepath.Join(worktreePath, "cache", "deps", "lib.a"))
+	if err != nil || string(data) != "lib" {
+		t.Errorf("expected cache to be cloned, got %q (%v)", data, err)
+	}
+	data, err = os.ReadFile(filepath.Join(repoRoot, "seen.txt"))
+	if err != nil || string(data) != "PORT=5183\n" {
+		t.Errorf("expected post_create hook to see rendered template, got %q (%v)", data, err)
+	}
+}
+
+func TestCreateChecksPorts(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	// Something is already listening on the port the first worktree gets for web
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+	busy := l.Addr().(*net.TCPAddr).Port
+
+	writeWtConfig(t, repoRoot, fmt.Sprintf(`version: 1
+worktree_dir: worktrees
+resources:
+  web: %d+index-1
+`, busy))
+
+	// The conflict fails create before anything is created
+	stdout, stderr, err := executeCommand("create", "ported")
+	if err == nil {
+		t.Fatal("expected create to fail on a busy port")
+	}
+	if !strings.Contains(stdout, fmt.Sprintf("Ports: web=%d\n", busy)) {
+		t.Errorf("expected ports to be reported, got:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, fmt.Sprintf("port(s) for \"ported\" are not free:\n  web %d: in use\n", busy)) {
+		t.Errorf("expected conflict report, got:\n%s", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(repoRoot, "worktrees", "ported")); !os.IsNotExist(err) {
+		t.Error("expected no worktree after a port conflict")
+	}
+	if git.BranchExists(repoRoot, "ported") {
+		t.Error("expected no branch after a port conflict")
+	}
+
+	// --force creates it anyway, with a warning
+	stdout, _, err = executeCommand("create", "ported", "--force")
+	if err != nil {
+		t.Fatalf("create --force failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "ported", "--force") }()
+	if !strings.Contains(stdout, "Warning: 1 port(s) for \"ported\" are not free") {
+		t.Errorf("expected a conflict warning, got:\n%s", stdout)
+	}
+
+	// info shows the ports
+	stdout, _, err = executeCommand("info", "ported", "--json")
+	if err != nil {
+		t.Fatalf("info command failed: %v", err)
+	}
+	if !strings.Contains(stdout, fmt.Sprintf(`"web": {`+"\n"+`      "port": %d`, busy)) {
+		t.Errorf("expected ports in info --json, got:\n%s", stdout)
+	}
+}
+
 func TestCreateWithExistingBranch(t *testing.T) {
 	repoRoot, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -472,6 +621,237 @@ func TestCreateDuplicateBranchFails(t *testing.T) {
 	_, _, _ = executeCommand("delete", "feature-x", "--force")
 }
 
+func TestCreateBranchPattern(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	base, err := git.GetCurrentBranch(repoRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeWtConfig(t, repoRoot, "version: 1\nbranch_pattern: \"{user}/{date:2006}-{name|slug}-{index}-from-{base}\"\n")
+	if _, _, err := executeCommand("create", "My Topic"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	want := fmt.Sprintf("test-user/%d-my-topic-1-from-%s", time.Now().Year(), base)
+	branch, err := git.GetCurrentBranch(filepath.Join(repoRoot, "worktrees", "My Topic"))
+	if err != nil || branch != want {
+		t.Errorf("branch = %q, want %q (err %v)", branch, want, err)
+	}
+
+	// An invalid branch name fails before pre_create hooks run
+	writeHookScript(t, repoRoot, "pre.sh", "touch \"$WT_REPO_ROOT/pre-ran\"\n")
+	writeWtConfig(t, repoRoot, "version: 1\nbranch_pattern: \"{name}..x\"\nhooks:\n  pre_create:\n    - script: pre.sh\n")
+	_, _, err = executeCommand("create", "bad")
+	if err == nil || !strings.Contains(err.Error(), `"bad..x" is not a valid branch name`) {
+		t.Errorf("expected an invalid branch name error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoRoot, "pre-ran")); err == nil {
+		t.Error("pre_create hook ran for an invalid branch name")
+	}
+
+	writeWtConfig(t, repoRoot, "version: 1\nbranch_pattern: \"{env:WT_TEST_UNSET}/{name}\"\n")
+	_, _, err = executeCommand("create", "unset")
+	if err == nil || !strings.Contains(err.Error(), "$WT_TEST_UNSET is not set") {
+		t.Errorf("expected an unset variable error, got %v", err)
+	}
+}
+
+func TestWorktreeDirOutsideRepo(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	home, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	writeWtConfig(t, repoRoot, "version: 1\nworktree_dir: \"~/worktrees/{repo}\"\n")
+
+	if _, _, err := executeCommand("create", "outside"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	worktreePath := filepath.Join(home, "worktrees", filepath.Base(repoRoot), "outside")
+	if _, err := os.Stat(worktreePath); err != nil {
+		t.Fatalf("worktree not created at %s: %v", worktreePath, err)
+	}
+
+	stdout, _, err := executeCommand("list")
+	if err != nil || !strings.Contains(stdout, "outside") {
+		t.Errorf("expected list to show the worktree, got %v:\n%s", err, stdout)
+	}
+	names, _ := completeWorktreeNames(cdCmd, nil, "")
+	if len(names) != 1 || !strings.HasPrefix(names[0], "outside\t") {
+		t.Errorf("expected completion of the worktree, got %v", names)
+	}
+
+	// Commands find the worktree from the current directory
+	_ = os.Chdir(worktreePath)
+	stdout, _, err = executeCommand("info")
+	if err != nil || !strings.Contains(stdout, "outside") {
+		t.Errorf("expected info to find the worktree from inside it, got %v:\n%s", err, stdout)
+	}
+	if _, _, err := executeCommand("delete", "--force"); err != nil {
+		t.Fatalf("delete from inside the worktree failed: %v", err)
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Error("worktree directory was not removed")
+	}
+}
+
+func TestBareRepository(t *testing.T) {
+	upstream, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare := filepath.Join(tmp, "repo.git")
+	mainPath := filepath.Join(tmp, "main")
+	branch, _ := git.GetCurrentBranch(upstream)
+	for _, args := range [][]string{
+		{"clone", "-q", "--bare", upstream, bare},
+		{"-C", bare, "worktree", "add", "-q", mainPath, branch},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	// Without a default worktree there's nowhere to exit to
+	_ = os.Chdir(bare)
+	if _, _, err := executeCommand("exit"); err == nil || !strings.Contains(err.Error(), "wt.defaultWorktree") {
+		t.Errorf("expected exit to ask for a default worktree, got %v", err)
+	}
+
+	if out, err := exec.Command("git", "-C", bare, "config", "wt.defaultWorktree", "../main").CombinedOutput(); err != nil {
+		t.Fatalf("git config failed: %v\n%s", err, out)
+	}
+	writeWtConfig(t, mainPath, "version: 1\nworktree_dir: ..\n")
+	_ = os.Chdir(mainPath)
+	if _, _, err := executeCommand("create", "feature"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	featurePath := filepath.Join(tmp, "feature")
+	if _, err := os.Stat(featurePath); err != nil {
+		t.Fatalf("worktree not created at %s: %v", featurePath, err)
+	}
+
+	// Every checkout is a worktree, the default one included
+	stdout, _, err := executeCommand("list")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	for _, name := range []string{"main", "feature"} {
+		if !strings.Contains(stdout, name) {
+			t.Errorf("expected list to show %s, got:\n%s", name, stdout)
+		}
+	}
+	if strings.Contains(stdout, "repo.git") {
+		t.Errorf("expected list not to show the bare repository, got:\n%s", stdout)
+	}
+
+	_ = os.Chdir(featurePath)
+	stdout, _, err = executeCommand("exit")
+	if err != nil {
+		t.Fatalf("exit failed: %v", err)
+	}
+	if strings.TrimSpace(stdout) != mainPath {
+		t.Errorf("expected exit to the default worktree %q, got %q", mainPath, strings.TrimSpace(stdout))
+	}
+
+	if _, _, err := executeCommand("delete", "main", "--force"); err == nil {
+		t.Error("expected deleting the default worktree to fail")
+	}
+}
+
+func TestNestedWorktreeNames(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	// git names both metadata directories after "foo", so they must be told apart
+	for _, name := range []string{"feature/foo", "foo"} {
+		if _, _, err := executeCommand("create", name); err != nil {
+			t.Fatalf("create %s failed: %v", name, err)
+		}
+	}
+	worktreePath := filepath.Join(repoRoot, "worktrees", "feature", "foo")
+	if branch, _ := git.GetCurrentBranch(worktreePath); branch != "feature/foo" {
+		t.Errorf("expected branch feature/foo, got %q", branch)
+	}
+	nestedIndex, err := git.OpenMetadata(repoRoot).Index("feature/foo")
+	if err != nil {
+		t.Fatalf("no index for feature/foo: %v", err)
+	}
+	flatIndex, err := git.OpenMetadata(repoRoot).Index("foo")
+	if err != nil {
+		t.Fatalf("no index for foo: %v", err)
+	}
+	if nestedIndex == flatIndex {
+		t.Errorf("feature/foo and foo share index %d", nestedIndex)
+	}
+
+	for _, name := range []string{"feature/foo/bar", "../escape", "feature//bar", "/abs"} {
+		if _, _, err := executeCommand("create", name); err == nil {
+			t.Errorf("expected create %q to fail", name)
+		}
+	}
+
+	stdout, _, err := executeCommand("list")
+	if err != nil || !strings.Contains(stdout, "feature/foo") {
+		t.Errorf("expected list to show feature/foo, got %v:\n%s", err, stdout)
+	}
+	names, _ := completeWorktreeNames(cdCmd, nil, "")
+	found := false
+	for _, n := range names {
+		found = found || strings.HasPrefix(n, "feature/foo\t")
+	}
+	if !found {
+		t.Errorf("expected completion of feature/foo, got %v", names)
+	}
+
+	// A directory only grouping worktrees isn't one
+	if _, _, err := executeCommand("cd", "feature"); err == nil {
+		t.Error("expected cd to a grouping directory to fail")
+	}
+
+	// Commands find the worktree from a subdirectory of it
+	subdir := filepath.Join(worktreePath, "sub")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Chdir(subdir)
+	stdout, _, err = executeCommand("info")
+	if err != nil || !strings.Contains(stdout, "feature/foo") {
+		t.Errorf("expected info to find feature/foo from inside it, got %v:\n%s", err, stdout)
+	}
+	if _, _, err := executeCommand("delete", "--force"); err != nil {
+		t.Fatalf("delete from inside the worktree failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoRoot, "worktrees", "feature")); !os.IsNotExist(err) {
+		t.Error("empty grouping directory was not removed")
+	}
+	if idx, err := git.OpenMetadata(repoRoot).Index("foo"); err != nil || idx != flatIndex {
+		t.Errorf("expected foo to keep index %d, got %d (%v)", flatIndex, idx, err)
+	}
+}
+
 func TestDeleteNonexistent(t *testing.T) {
 	repoRoot, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -624,6 +1004,36 @@ func TestDeleteSucceedsWithCleanWorktree(t *testing.T) {
 	}
 }
 
+func TestForceDeleteDoesNotFetch(t *testing.T) {
+	repoRoot, _, cleanup := setupTestRepoWithIsolatedHome(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	// A remote that can't be fetched, with fetching on every run
+	if out, err := exec.Command("git", "-C", repoRoot, "remote", "add", "origin", filepath.Join(repoRoot, "missing")).CombinedOutput(); err != nil {
+		t.Fatalf("git remote add failed: %v\n%s", err, out)
+	}
+	for _, args := range [][]string{{"config", "remote", "origin"}, {"config", "fetch_interval", "0"}} {
+		if _, _, err := executeCommand(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	if _, _, err := executeCommand("create", "forced"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	stdout, stderr, err := executeCommand("delete", "forced", "--force")
+	if err != nil {
+		t.Fatalf("delete --force failed: %v", err)
+	}
+	if output := stdout + stderr; strings.Contains(output, "fetch") {
+		t.Errorf("expected delete --force not to fetch, got:\n%s", output)
+	}
+}
+
 func TestCdNonexistent(t *testing.T) {
 	repoRoot, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -1226,6 +1636,24 @@ func TestConfigShowOrigin(t *testing.T) {
 	if !strings.Contains(stdout, "fetch") {
 		t.Errorf("expected 'fetch' in show-origin output, got: %s", stdout)
 	}
+
+	// Repo config values show the file that set them
+	writeWtConfig(t, repoRoot, "version: 1\nworktree_dir: trees\n")
+	if err := os.WriteFile(filepath.Join(repoRoot, ".wt.local.yaml"), []byte("worktree_dir: my-trees\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, _, err = executeCommand("config", "--show-origin")
+	if err != nil {
+		t.Fatalf("config --show-origin failed: %v", err)
+	}
+	for _, want := range []string{
+		"version = 1              .wt.yaml (repo)",
+		"worktree_dir = my-trees       .wt.local.yaml (repo)",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in show-origin output, got: %s", want, stdout)
+		}
+	}
 }
 
 func TestConfigUnset(t *testing.T) {
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -30,14 +31,7 @@ func resolveComparisonRef(cmd *cobra.Command, repoRoot string, cfg *config.Confi
 	// Determine remote for this repo (empty = local comparison)
 	remote := userCfg.GetRemoteForRepo(repoRoot)
 
-	// Determine comparison branch from repo config, or auto-detect
-	branch := cfg.DefaultBranch
-	if branch == "" {
-		branch, _ = git.GetDefaultBranch(repoRoot)
-		if branch == "" {
-			branch = "main" // Ultimate fallback
-		}
-	}
+	branch := comparisonBranch(repoRoot, cfg)
 
 	// Build comparison ref based on whether remote is configured
 	var comparisonRef string
@@ -76,6 +70,18 @@ func resolveComparisonRef(cmd *cobra.Command, repoRoot string, cfg *config.Confi
 	return comparisonRef, nil
 }
 
+// comparisonBranch determines the comparison branch from repo config, or auto-detects it
+func comparisonBranch(repoRoot string, cfg *config.Config) string {
+	branch := cfg.DefaultBranch
+	if branch == "" {
+		branch, _ = git.GetDefaultBranch(repoRoot)
+		if branch == "" {
+			branch = "main" // Ultimate fallback
+		}
+	}
+	return branch
+}
+
 // SetupCompare initializes the comparison context for list/cleanup commands.
 // It prints the repo root, determines the comparison ref, and fetches if configured.
 func SetupCompare(cmd *cobra.Command) (*CompareSetup, error) {
@@ -85,14 +91,20 @@ func SetupCompare(cmd *cobra.Command) (*CompareSetup, error) {
 		return nil, fmt.Errorf("not in a git repository: %w", err)
 	}
 
-	// Print repo root
-	cmd.Printf("Repository: %s\n", repoRoot)
+	// Print repo root (to stderr when stdout is reserved for JSON)
+	printHeader := cmd.Printf
+	if jsonOutput(cmd) {
+		printHeader = cmd.PrintErrf
+	}
+	printHeader("Repository: %s\n", repoRoot)
 
 	// Load repo configuration
 	cfg, err := config.Load(repoRoot)
-	if err != nil {
+	if os.IsNotExist(err) {
 		// Use defaults if no config file
 		cfg = config.DefaultConfig()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
 	comparisonRef, err := resolveComparisonRef(cmd, repoRoot, cfg)
@@ -101,8 +113,7 @@ func SetupCompare(cmd *cobra.Command) (*CompareSetup, error) {
 	}
 
 	// Print comparison ref
-	cmd.Printf("Comparing to: %s\n", comparisonRef)
-	cmd.Println()
+	printHeader("Comparing to: %s\n\n", comparisonRef)
 
 	return &CompareSetup{
 		RepoRoot:      repoRoot,
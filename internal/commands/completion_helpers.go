@@ -9,7 +9,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// completeWorktreeNames returns a completion function that provides worktree names
+// completeWorktreeNames returns a completion function that provides worktree names,
+// described by their branch and status, e.g. "feature-x\tfeature-x ↑2 dirty"
 func completeWorktreeNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// Only complete the first argument
 	if len(args) > 0 {
@@ -26,29 +27,41 @@ func completeWorktreeNames(cmd *cobra.Command, args []string, toComplete string)
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	worktrees, err := git.ListWorktrees(repoRoot)
+	worktrees, err := managedWorktrees(repoRoot, cfg)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
+	// Status is compared against the local comparison ref, so completion never fetches
+	var ref string
+	var mergedCache map[string]bool
 	var names []string
 	for _, wt := range worktrees {
-		if wt.Bare {
-			continue // Skip bare repo
-		}
-		name := git.GetWorktreeName(repoRoot, wt.Path, cfg.WorktreeDir)
-		// Skip empty names (which means it's the main worktree)
-		if name == "" || name == "." || strings.HasPrefix(name, "..") {
+		name := git.GetWorktreeName(cfg.WorktreesDir(repoRoot), wt.Path)
+		if !strings.HasPrefix(name, toComplete) {
 			continue
 		}
-		if strings.HasPrefix(name, toComplete) {
-			names = append(names, name)
+		if ref == "" {
+			ref = promptComparisonRef(repoRoot, cfg)
+			mergedCache, _ = git.GetMergedBranches(repoRoot, ref)
 		}
+		names = append(names, name+"\t"+worktreeDescription(repoRoot, wt.Path, name, ref, mergedCache))
 	}
 
 	return names, cobra.ShellCompDirectiveNoFileComp
 }
 
+// worktreeDescription describes a worktree for completion by its branch and status
+func worktreeDescription(repoRoot, path, name, ref string, mergedCache map[string]bool) string {
+	branch, _ := git.GetCurrentBranch(path)
+	parts := []string{branch}
+	if status, err := git.GetWorktreeStatus(repoRoot, path, name, branch, ref, mergedCache); err == nil {
+		parts = append(parts, statusCounts(status)...)
+		parts = append(parts, statusTags(status, false)...)
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
 // completeBranchNames returns a completion function that provides branch names
 func completeBranchNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	repoRoot, err := config.GetMainRepoRoot()
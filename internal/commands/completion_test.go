@@ -90,16 +90,21 @@ func TestCompleteWorktreeNames(t *testing.T) {
 		t.Errorf("expected 2 completions, got %d: %v", len(completions), completions)
 	}
 
-	found := make(map[string]bool)
+	found := make(map[string]string)
 	for _, c := range completions {
-		found[c] = true
+		value, description, _ := strings.Cut(c, "\t")
+		found[value] = description
 	}
-	if !found["feature-one"] {
+	if _, ok := found["feature-one"]; !ok {
 		t.Error("expected feature-one in completions")
 	}
-	if !found["feature-two"] {
+	if _, ok := found["feature-two"]; !ok {
 		t.Error("expected feature-two in completions")
 	}
+	// Descriptions show the branch and status
+	if desc := found["feature-one"]; !strings.HasPrefix(desc, "feature-one") || !strings.Contains(desc, "new") {
+		t.Errorf("expected description with branch and status, got %q", desc)
+	}
 
 	// Cleanup
 	_, _, _ = executeCommand("delete", "feature-one", "--force")
@@ -132,7 +137,7 @@ func TestCompleteWorktreeNamesWithPrefix(t *testing.T) {
 	if len(completions) != 1 {
 		t.Errorf("expected 1 completion for 'bug' prefix, got %d: %v", len(completions), completions)
 	}
-	if len(completions) == 1 && completions[0] != "bug-fix" {
+	if len(completions) == 1 && !strings.HasPrefix(completions[0], "bug-fix\t") {
 		t.Errorf("expected 'bug-fix', got %s", completions[0])
 	}
 
@@ -251,7 +256,7 @@ func TestInitCommandValidArgs(t *testing.T) {
 		t.Fatal("init command should have ValidArgs set")
 	}
 
-	expected := []string{"zsh", "bash", "fish"}
+	expected := []string{"zsh", "bash", "fish", "powershell", "pwsh", "nu", "nushell", "elvish"}
 	if len(initCmd.ValidArgs) != len(expected) {
 		t.Errorf("expected %d ValidArgs, got %d", len(expected), len(initCmd.ValidArgs))
 	}
@@ -382,7 +387,8 @@ func TestCompletionIntegration(t *testing.T) {
 
 	found := make(map[string]bool)
 	for _, c := range completions {
-		found[c] = true
+		value, _, _ := strings.Cut(c, "\t")
+		found[value] = true
 	}
 
 	if !found["alpha-wt"] {
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -38,7 +39,7 @@ Configuration keys:
 
 Examples:
   wt config --list                       # List all settings
-  wt config --show-origin                # Show where each value comes from
+  wt config --show-origin                # Show where each value comes from, including .wt.yaml
   wt config --global remote origin       # Set global remote
   wt config --global fetch_interval 10m  # Fetch at most every 10 minutes
   wt config --global fetch_interval 0    # Always fetch (no caching)
@@ -156,9 +157,13 @@ func printConfigShowOrigin(cmd *cobra.Command, cfg *userconfig.UserConfig) error
 			_, _ = fmt.Fprintf(out, "fetch_interval = %-14s (default)\n", fetchInterval)
 		}
 
-		// Show repo's default_branch if set
-		if repoCfg, err := config.Load(repoRoot); err == nil && repoCfg.DefaultBranch != "" {
-			_, _ = fmt.Fprintf(out, "default_branch = %-14s .wt.yaml (repo)\n", repoCfg.DefaultBranch)
+		// Show the repo config's values and the file each came from
+		settings, err := config.Settings(repoRoot)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		for _, s := range settings {
+			_, _ = fmt.Fprintf(out, "%s = %-14s %s (repo)\n", s.Key, s.Value, s.File)
 		}
 	} else {
 		// Not in a repo, just show global values
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configMigrateDryRun bool
+
+func init() {
+	configMigrateCmd.Flags().BoolVarP(&configMigrateDryRun, "dry-run", "n", false, "Show the files that would be migrated without changing them")
+	configCmd.AddCommand(configMigrateCmd)
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade .wt.yaml to the current config version",
+	Long: `Rewrite the repository's config files that use an older config version
+in the current one, keeping their comments. This covers .wt.yaml, the files
+it includes and .wt.local.yaml.
+
+Older versions are still read, migrated in memory each time; migrating the
+files makes them match the documentation and schema. A version newer than
+this wt supports is an error: upgrade wt instead.
+
+Use --dry-run to see which files would change.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMigrate,
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	repoRoot, err := config.GetMainRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+	migrated, err := config.Migrate(repoRoot, configMigrateDryRun)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s not found", filepath.Join(repoRoot, config.ConfigFileName))
+		}
+		return fmt.Errorf("failed to migrate config: %w", err)
+	}
+
+	if len(migrated) == 0 {
+		cmd.Printf("Config for %s is already at version %d\n", repoRoot, config.CurrentVersion())
+		return nil
+	}
+	for _, f := range migrated {
+		if configMigrateDryRun {
+			cmd.Printf("Would migrate %s from version %d to %d\n", f.File, f.From, config.CurrentVersion())
+		} else {
+			cmd.Printf("Migrated %s from version %d to %d\n", f.File, f.From, config.CurrentVersion())
+		}
+	}
+	return nil
+}
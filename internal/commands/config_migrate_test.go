@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigMigrate(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	stdout, _, err := executeCommand("config", "migrate", "--dry-run")
+	if err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	if !strings.Contains(stdout, "is already at version 1") {
+		t.Errorf("expected the config to be current, got %q", stdout)
+	}
+
+	// A current config is left exactly as it was
+	configPath := filepath.Join(repoRoot, ".wt.yaml")
+	original := "version: 1   # keep this spacing\nworktree_dir: 'worktrees'\n"
+	writeWtConfig(t, repoRoot, original)
+	if _, _, err := executeCommand("config", "migrate"); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != original {
+		t.Errorf("migrate rewrote a current config:\n%s", data)
+	}
+
+	// A config from a newer wt is refused by every command
+	writeWtConfig(t, repoRoot, "version: 99\n")
+	for _, args := range [][]string{{"config", "migrate"}, {"list"}} {
+		if _, _, err := executeCommand(args...); err == nil || !strings.Contains(err.Error(), "version 99 is newer than this wt supports") {
+			t.Errorf("expected wt %s to refuse a newer config, got %v", strings.Join(args, " "), err)
+		}
+	}
+	stdout, _, err = executeCommand("config", "validate")
+	if err == nil || !strings.Contains(stdout, ".wt.yaml:1: version 99 is newer than this wt supports (1); upgrade wt") {
+		t.Errorf("expected validate to report the version, got %v:\n%s", err, stdout)
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/agarcher/wt/internal/provision"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check .wt.yaml for mistakes",
+	Long: `Check the repository's config for mistakes, reporting each with its file
+and line. This covers .wt.yaml, the files it includes and .wt.local.yaml.
+
+Checks for:
+- Unknown keys, such as a misspelt hook event, and values of the wrong type
+- Hook scripts that don't exist, and info-only options on other hooks
+- A worktree_dir with unset variables, or containing the repository
+- A branch_pattern with unknown variables or filters, or without {name}
+- Index settings that leave no index to allocate
+- Invalid port expressions in resources and variable names in env
+
+Every command reads .wt.yaml strictly, so unknown keys are always errors;
+this command reports everything at once.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for .wt.yaml",
+	Long: `Print a JSON Schema describing .wt.yaml, for editor completion and checking.
+
+For example, with the YAML language server (VS Code, Neovim and others):
+  wt config schema > .wt.schema.json
+and add this first line to .wt.yaml:
+  # yaml-language-server: $schema=.wt.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := cmd.OutOrStdout().Write(config.Schema())
+		return err
+	},
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	repoRoot, err := config.GetMainRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+	_, problems, err := config.Check(repoRoot, validateEnvNames, validateResources)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s not found", filepath.Join(repoRoot, config.ConfigFileName))
+		}
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if len(problems) == 0 {
+		cmd.Printf("Config for %s is valid\n", repoRoot)
+		return nil
+	}
+	// Like compiler errors, e.g. ".wt.yaml:12: unknown key ..."
+	for _, p := range problems {
+		if p.Line > 0 {
+			cmd.Printf("%s:%d: %s\n", p.File, p.Line, p.Message)
+		} else {
+			cmd.Printf("%s: %s\n", p.File, p.Message)
+		}
+	}
+	if len(problems) == 1 {
+		return fmt.Errorf("found 1 problem in the config for %s", repoRoot)
+	}
+	return fmt.Errorf("found %d problems in the config for %s", len(problems), repoRoot)
+}
+
+// validateEnvNames checks the env: section's variable names
+func validateEnvNames(cfg *config.Config) []config.Problem {
+	var problems []config.Problem
+	for _, name := range sortedKeys(cfg.Env) {
+		if !hooks.ValidEnvName(name) {
+			problems = append(problems, config.Problem{Key: "env." + name, Message: fmt.Sprintf("env: %q is not a valid variable name", name)})
+		}
+	}
+	return problems
+}
+
+// validateResources checks the port expressions in resources:, resolving them as
+// the first worktree would
+func validateResources(cfg *config.Config) []config.Problem {
+	var problems []config.Problem
+	for _, name := range slices.Sorted(maps.Keys(cfg.Resources)) {
+		if _, err := provision.ResolvePorts(config.ResourcesConfig{name: cfg.Resources[name]}, 1); err != nil {
+			problems = append(problems, config.Problem{Key: "resources." + name, Message: fmt.Sprintf("resources: %v", err)})
+		}
+	}
+	return problems
+}
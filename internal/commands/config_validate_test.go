@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	stdout, _, err := executeCommand("config", "validate")
+	if err != nil {
+		t.Fatalf("validate failed on the default config: %v", err)
+	}
+	if !strings.Contains(stdout, "is valid") {
+		t.Errorf("expected the config to be reported valid, got %q", stdout)
+	}
+
+	writeHookScript(t, repoRoot, "setup.sh", "true\n")
+	writeWtConfig(t, repoRoot, `version: 1
+hooks:
+  post_create:
+    - script: setup.sh
+    - script: missing.sh
+resources:
+  web: "3000+nope"
+env:
+  BAD-NAME: x
+`)
+	stdout, _, err = executeCommand("config", "validate")
+	if err == nil {
+		t.Fatal("expected validate to fail")
+	}
+	if !strings.Contains(err.Error(), "found 3 problems") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		".wt.yaml:5: hooks.post_create[1]: script missing.sh not found",
+		`.wt.yaml:7: resources: resource "web"`,
+		`.wt.yaml:9: env: "BAD-NAME" is not a valid variable name`,
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout)
+		}
+	}
+
+	// Unknown keys fail validation and every other command
+	writeWtConfig(t, repoRoot, "version: 1\nhooks:\n  post_creat:\n    - script: setup.sh\n")
+	stdout, _, err = executeCommand("config", "validate")
+	if err == nil || !strings.Contains(stdout, `.wt.yaml:3: unknown key "post_creat" (did you mean "post_create"?)`) {
+		t.Errorf("expected an unknown key problem, got %v:\n%s", err, stdout)
+	}
+	if _, _, err := executeCommand("list"); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Errorf("expected list to fail on an unknown key, got %v", err)
+	}
+
+	// Problems in .wt.local.yaml are reported against it
+	writeWtConfig(t, repoRoot, "version: 1\n")
+	if err := os.WriteFile(filepath.Join(repoRoot, ".wt.local.yaml"), []byte("hooks:\n  post_create:\n    - script: mine.sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, _, err = executeCommand("config", "validate")
+	if err == nil || !strings.Contains(stdout, ".wt.local.yaml:3: hooks.post_create[0]: script mine.sh not found") {
+		t.Errorf("expected a problem in .wt.local.yaml, got %v:\n%s", err, stdout)
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	stdout, _, err := executeCommand("config", "schema")
+	if err != nil {
+		t.Fatalf("schema failed: %v", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(stdout), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if _, ok := schema["properties"].(map[string]any)["hooks"]; !ok {
+		t.Error("expected hooks in the schema's properties")
+	}
+}
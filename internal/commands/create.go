@@ -2,7 +2,9 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -10,29 +12,40 @@ import (
 	"github.com/agarcher/wt/internal/config"
 	"github.com/agarcher/wt/internal/git"
 	"github.com/agarcher/wt/internal/hooks"
+	"github.com/agarcher/wt/internal/provision"
 	"github.com/spf13/cobra"
 )
 
 var (
 	createBranch string
+	createForce  bool
 )
 
 func init() {
 	createCmd.Flags().StringVarP(&createBranch, "branch", "b", "", "Use existing branch instead of creating a new one")
 	_ = createCmd.RegisterFlagCompletionFunc("branch", completeBranchNames)
+	createCmd.Flags().BoolVarP(&createForce, "force", "f", false, "Create the worktree even if its ports are not free")
 	rootCmd.AddCommand(createCmd)
 }
 
 var createCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new worktree",
-	Long: `Create a new git worktree with the specified name.
+	Long: `Create a new git worktree with the specified name. Names can contain
+slashes to mirror branches, e.g. "feature/foo".
 
-By default, a new branch with the same name will be created.
-Use --branch to checkout an existing branch instead.
+By default, a new branch is created, named by branch_pattern in your
+.wt.yaml (default: the worktree name). The pattern can use {user}, {date},
+{index}, {base} and {env:VAR} as well as {name}, and filters such as
+{name|slug}. Use --branch to checkout an existing branch instead.
 
 The worktree will be created in the directory specified by worktree_dir
-in your .wt.yaml configuration (default: worktrees/).
+in your .wt.yaml configuration (default: worktrees/), which may be outside
+the repository, e.g. "../{repo}-worktrees".
+
+The worktree's named ports from resources: are checked first: if another
+worktree's resources cover one or something is listening on it, create fails
+with a report of the conflicts. Use --force to create the worktree anyway.
 
 After creation, any post_create hooks defined in .wt.yaml will be executed.`,
 	Args: cobra.ExactArgs(1),
@@ -55,13 +68,32 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Determine the worktree path
-	worktreePath := filepath.Join(repoRoot, cfg.WorktreeDir, name)
+	worktreesDir := cfg.WorktreesDir(repoRoot)
+	if err := validateWorktreeName(worktreesDir, name); err != nil {
+		return err
+	}
+	worktreePath := filepath.Join(worktreesDir, name)
+
+	// Allocate the index up front, as branch_pattern can use it; it's stored once
+	// the worktree exists
+	meta := git.OpenMetadata(repoRoot)
+	index, indexErr := meta.AllocateIndex(cfg.Index.Max, cfg.Index.Reserved...)
 
-	// Determine the branch name
+	// Determine and check the branch name before any hook runs
 	branchName := createBranch
 	if branchName == "" {
-		// Apply branch pattern
-		branchName = strings.ReplaceAll(cfg.BranchPattern, "{name}", name)
+		branchName, err = newBranchName(repoRoot, cfg, name, index)
+		if err != nil {
+			if indexErr != nil {
+				return fmt.Errorf("%w (%v)", err, indexErr)
+			}
+			return err
+		}
+		if git.BranchExists(repoRoot, branchName) {
+			return fmt.Errorf("branch %q already exists (use --branch to checkout existing branch)", branchName)
+		}
+	} else if !git.BranchExists(repoRoot, createBranch) {
+		return fmt.Errorf("branch %q does not exist", createBranch)
 	}
 
 	// Create hook environment
@@ -73,6 +105,15 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		WorktreeDir: cfg.WorktreeDir,
 	}
 
+	// Check the worktree's named ports are free before anything is created
+	if indexErr == nil {
+		ports, err := checkPorts(cmd, cfg, repoRoot, name, index)
+		if err != nil {
+			return err
+		}
+		env.Ports = ports
+	}
+
 	// Run pre-create hooks
 	if err := hooks.RunPreCreate(cfg, env); err != nil {
 		return fmt.Errorf("pre-create hook failed: %w", err)
@@ -81,46 +122,50 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	// Create the worktree
 	if createBranch != "" {
 		// Use existing branch
-		if !git.BranchExists(repoRoot, createBranch) {
-			return fmt.Errorf("branch %q does not exist", createBranch)
-		}
 		cmd.Printf("Creating worktree %q from branch %q...\n", name, createBranch)
 		if err := git.CreateWorktreeFromBranch(repoRoot, worktreePath, createBranch); err != nil {
 			return fmt.Errorf("failed to create worktree: %w", err)
 		}
 	} else {
 		// Create new branch
-		if git.BranchExists(repoRoot, branchName) {
-			return fmt.Errorf("branch %q already exists (use --branch to checkout existing branch)", branchName)
-		}
 		cmd.Printf("Creating worktree %q with new branch %q...\n", name, branchName)
 		if err := git.CreateWorktree(repoRoot, worktreePath, branchName); err != nil {
 			return fmt.Errorf("failed to create worktree: %w", err)
 		}
 	}
 
+	// Record the name first: git names the metadata directory after the last path
+	// element, and the other metadata is found by name
+	if err := meta.Register(worktreePath, name); err != nil {
+		cmd.Printf("Warning: could not store worktree name: %v\n", err)
+	}
+
 	// Store creation metadata for status tracking
-	if err := git.SetWorktreeCreatedAt(repoRoot, name, time.Now()); err != nil {
+	if err := meta.SetCreatedAt(name, time.Now()); err != nil {
 		cmd.Printf("Warning: could not store creation time: %v\n", err)
 	}
 	if initialCommit, err := git.GetCurrentCommit(worktreePath); err == nil {
-		if err := git.SetWorktreeInitialCommit(repoRoot, name, initialCommit); err != nil {
+		if err := meta.SetInitialCommit(name, initialCommit); err != nil {
 			cmd.Printf("Warning: could not store initial commit: %v\n", err)
 		}
+		env.BaseCommit = initialCommit
 	}
 
-	// Allocate and store worktree index
-	index, err := git.AllocateIndex(repoRoot, cfg.Index.Max)
-	if err != nil {
-		cmd.Printf("Warning: could not allocate index: %v\n", err)
+	// Store the worktree index
+	if indexErr != nil {
+		cmd.Printf("Warning: could not allocate index: %v\n", indexErr)
 	} else {
-		if err := git.SetWorktreeIndex(repoRoot, name, index); err != nil {
+		if err := meta.SetIndex(name, index); err != nil {
 			cmd.Printf("Warning: could not store index: %v\n", err)
 		} else {
 			env.Index = index
 		}
 	}
 
+	// Provision files and clone directories declared in .wt.yaml before hooks that may rely on them
+	provisionFiles(cmd, cfg, env)
+	cloneDirs(cmd, cfg, env)
+
 	// Run post-create hooks
 	if err := hooks.RunPostCreate(cfg, env); err != nil {
 		cmd.Printf("Warning: post-create hook failed: %v\n", err)
@@ -140,3 +185,196 @@ func runCreate(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// validateWorktreeName checks a name for a new worktree. Names can contain slashes
+// to mirror branches like "feature/foo", but not inside another worktree.
+func validateWorktreeName(worktreesDir, name string) error {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") {
+		return fmt.Errorf("invalid worktree name %q", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid worktree name %q: empty, . and .. path elements are not allowed", name)
+		}
+	}
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		if git.IsWorktreeCheckout(filepath.Join(worktreesDir, dir)) {
+			return fmt.Errorf("invalid worktree name %q: it would be inside worktree %q", name, dir)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(worktreesDir, name)); err == nil {
+		return fmt.Errorf("worktree %q already exists", name)
+	}
+	return nil
+}
+
+// newBranchName expands branch_pattern for a new worktree and checks the result is
+// a valid branch name
+func newBranchName(repoRoot string, cfg *config.Config, name string, index int) (string, error) {
+	base, _ := git.GetCurrentBranch(repoRoot)
+	branch, err := config.ExpandBranchPattern(cfg.BranchPattern, config.BranchVars{
+		Name:  name,
+		User:  git.GetUserName(repoRoot),
+		Base:  base,
+		Index: index,
+		Time:  time.Now(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid branch_pattern %q: %w", cfg.BranchPattern, err)
+	}
+	if err := git.CheckBranchName(branch); err != nil {
+		return "", fmt.Errorf("branch_pattern %q gives an invalid branch: %w", cfg.BranchPattern, err)
+	}
+	return branch, nil
+}
+
+// checkPorts resolves a new worktree's named ports from resources: and reports them.
+// Ports that overlap another worktree's or are already in use are reported and, unless
+// --force is given, fail the create.
+func checkPorts(cmd *cobra.Command, cfg *config.Config, repoRoot, name string, index int) ([]provision.Port, error) {
+	ports, conflicts, err := resolveWorktreePorts(cmd, cfg, repoRoot, name, index)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) == 0 {
+		return ports, nil
+	}
+	if createForce {
+		reportPortConflicts(cmd, name, conflicts)
+		return ports, nil
+	}
+	cmd.PrintErrf("Error: %d port(s) for %q are not free:\n", len(conflicts), name)
+	for _, c := range conflicts {
+		cmd.PrintErrf("  %s\n", c)
+	}
+	cmd.PrintErrln("\nUse --force to create the worktree anyway.")
+	return nil, fmt.Errorf("ports for %q are not free", name)
+}
+
+// resolveWorktreePorts resolves a worktree's named ports from resources:, reports
+// them, and returns any that overlap another worktree's ports or are already in use
+func resolveWorktreePorts(cmd *cobra.Command, cfg *config.Config, repoRoot, name string, index int) ([]provision.Port, []provision.PortConflict, error) {
+	if len(cfg.Resources) == 0 || index <= 0 {
+		return nil, nil, nil
+	}
+	ports, err := provision.ResolvePorts(cfg.Resources, index)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid resources config: %w", err)
+	}
+	cmd.Printf("Ports: %s\n", formatPorts(ports))
+	return ports, provision.CheckPorts(ports, otherWorktreePorts(cfg, repoRoot, name)), nil
+}
+
+// reportPortConflicts warns about ports that are not free
+func reportPortConflicts(cmd *cobra.Command, name string, conflicts []provision.PortConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+	cmd.Printf("Warning: %d port(s) for %q are not free:\n", len(conflicts), name)
+	for _, c := range conflicts {
+		cmd.Printf("  %s\n", c)
+	}
+}
+
+// otherWorktreePorts returns the named ports of every managed worktree except name
+func otherWorktreePorts(cfg *config.Config, repoRoot, name string) map[string][]provision.Port {
+	worktrees, err := managedWorktrees(repoRoot, cfg)
+	if err != nil {
+		return nil
+	}
+	worktreesDir := cfg.WorktreesDir(repoRoot)
+	meta := git.OpenMetadata(repoRoot)
+	others := make(map[string][]provision.Port)
+	for _, wt := range worktrees {
+		other := git.GetWorktreeName(worktreesDir, wt.Path)
+		if other == name {
+			continue
+		}
+		idx, err := meta.Index(other)
+		if err != nil || idx <= 0 {
+			continue
+		}
+		if ports, err := provision.ResolvePorts(cfg.Resources, idx); err == nil {
+			others[other] = ports
+		}
+	}
+	return others
+}
+
+// cloneProgressInterval limits how often clone progress is redrawn
+const cloneProgressInterval = 100 * time.Millisecond
+
+// cloneDirs clones the directories configured under clone_dirs into the new worktree,
+// showing progress on a terminal and a summary of what was written. Failures are warnings.
+func cloneDirs(cmd *cobra.Command, cfg *config.Config, env *hooks.Env) {
+	if len(cfg.CloneDirs) == 0 {
+		return
+	}
+
+	out := cmd.OutOrStderr()
+	interactive := isTerminal(out)
+	var lastDraw time.Time
+	progress := func(s provision.CloneStats) {
+		if !interactive || time.Since(lastDraw) < cloneProgressInterval {
+			return
+		}
+		lastDraw = time.Now()
+		_, _ = fmt.Fprintf(out, "\r\033[K  %s: %d files...", s.Path, s.Files)
+	}
+
+	cmd.Println("Cloning directories...")
+	stats, err := provision.CloneDirs(cfg, provision.Vars{
+		Name:     env.Name,
+		Branch:   env.Branch,
+		Path:     env.Path,
+		RepoRoot: env.RepoRoot,
+		Index:    env.Index,
+	}, progress)
+	if interactive && !lastDraw.IsZero() {
+		_, _ = fmt.Fprint(out, "\r\033[K")
+	}
+	var hardlinked []string
+	for _, s := range stats {
+		cmd.Printf("  %s\n", s)
+		if s.Hardlinked > 0 {
+			hardlinked = append(hardlinked, s.Path)
+		}
+	}
+	if len(hardlinked) > 0 {
+		cmd.Printf("Warning: the filesystem doesn't support reflinks, so files in %s are hard links to the main checkout's (method: hardlink): editing one in place changes both\n", strings.Join(hardlinked, ", "))
+	}
+	if err != nil {
+		cmd.Printf("Warning: cloning directories failed: %v\n", err)
+	}
+}
+
+// isTerminal reports whether w is a terminal, where progress can be redrawn in place
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// provisionFiles copies, links and renders the files configured under files: into
+// the new worktree and reports what was provisioned. Failures are warnings.
+func provisionFiles(cmd *cobra.Command, cfg *config.Config, env *hooks.Env) {
+	results, err := provision.Run(cfg, provision.Vars{
+		Name:     env.Name,
+		Branch:   env.Branch,
+		Path:     env.Path,
+		RepoRoot: env.RepoRoot,
+		Index:    env.Index,
+	})
+	if len(results) > 0 {
+		cmd.Println("Provisioning files...")
+		for _, r := range results {
+			cmd.Printf("  %s\n", r)
+		}
+	}
+	if err != nil {
+		cmd.Printf("Warning: file provisioning failed: %v\n", err)
+	}
+}
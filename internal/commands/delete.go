@@ -59,37 +59,12 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	}
 
 	// Determine which worktree to delete
-	var name string
-	var worktreePath string
-
-	if len(args) > 0 {
-		name = args[0]
-		worktreePath = filepath.Join(repoRoot, cfg.WorktreeDir, name)
-	} else {
-		// Auto-detect from current directory
-		cwd, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
-		}
-
-		worktreesDir := filepath.Join(repoRoot, cfg.WorktreeDir)
-		if !strings.HasPrefix(cwd, worktreesDir) {
-			return fmt.Errorf("not in a worktree (specify name or cd into a worktree)")
-		}
-
-		// Extract worktree name from path
-		rel, err := filepath.Rel(worktreesDir, cwd)
-		if err != nil {
-			return fmt.Errorf("failed to determine worktree: %w", err)
-		}
-		parts := strings.Split(rel, string(filepath.Separator))
-		name = parts[0]
-		worktreePath = filepath.Join(worktreesDir, name)
+	name, worktreePath, err := resolveWorktreeArg(repoRoot, cfg, args)
+	if err != nil {
+		return err
 	}
-
-	// Check if worktree exists
-	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
-		return fmt.Errorf("worktree %q does not exist", name)
+	if worktreePath == repoRoot {
+		return fmt.Errorf("%s is the default worktree of the bare repository; unset wt.defaultWorktree before deleting it", name)
 	}
 
 	// Get branch name before deletion
@@ -105,10 +80,24 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get index for hooks (before deletion cleans it up)
-	if idx, err := git.GetWorktreeIndex(repoRoot, name); err == nil {
+	if idx, err := git.OpenMetadata(repoRoot).Index(name); err == nil {
 		env.Index = idx
 	}
 
+	// Determine the comparison ref for safety checks and hooks. A forced delete skips
+	// the checks, so hooks get the local ref rather than waiting on a fetch.
+	comparisonRef := promptComparisonRef(repoRoot, cfg)
+	if !deleteForce {
+		if comparisonRef, err = resolveComparisonRef(cmd, repoRoot, cfg); err != nil {
+			return err
+		}
+	}
+
+	// Give hooks the worktree's status (before deletion cleans up its metadata)
+	status, _ := git.GetWorktreeStatus(repoRoot, worktreePath, name, branch, comparisonRef, nil)
+	setHookStatus(env, comparisonRef, status)
+	env.Force = deleteForce
+
 	// Safety checks (unless --force)
 	if !deleteForce {
 		var issues []string
@@ -123,12 +112,7 @@ func runDelete(cmd *cobra.Command, args []string) error {
 		}
 
 		// Check for unmerged commits (commits ahead of comparison ref)
-		comparisonRef, err := resolveComparisonRef(cmd, repoRoot, cfg)
-		if err != nil {
-			return err
-		}
-
-		ahead, _, _ := git.GetCommitsAheadBehind(repoRoot, worktreePath, comparisonRef)
+		ahead := status.CommitsAhead
 		if ahead > 0 {
 			if ahead == 1 {
 				issues = append(issues, fmt.Sprintf("has 1 commit not merged into %s", comparisonRef))
@@ -149,7 +133,10 @@ func runDelete(cmd *cobra.Command, args []string) error {
 
 	// Check if user is in the worktree being deleted
 	cwd, _ := os.Getwd()
-	inDeletedWorktree := strings.HasPrefix(cwd, worktreePath)
+	inDeletedWorktree := cwd == worktreePath || strings.HasPrefix(cwd, worktreePath+string(filepath.Separator))
+
+	// Run post-merge hooks if the branch was merged since we last looked
+	notifyMerged(cmd, cfg, env, status)
 
 	// Run pre-delete hooks
 	if err := hooks.RunPreDelete(cfg, env); err != nil {
@@ -164,6 +151,7 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	if err := git.RemoveWorktree(repoRoot, worktreePath, deleteForce); err != nil {
 		return fmt.Errorf("failed to delete worktree: %w", err)
 	}
+	git.RemoveEmptyParents(cfg.WorktreesDir(repoRoot), worktreePath)
 
 	// Delete the branch unless --keep-branch is specified
 	if !deleteKeepBranch && branch != "" {
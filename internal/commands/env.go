@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/agarcher/wt/internal/provision"
+	"github.com/agarcher/wt/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+// envStateVar is the variable the shell integration uses to remember what it loaded
+const envStateVar = "_WT_ENV_STATE"
+
+// envFileVar holds the loaded worktree's env file, which the shell integration
+// watches to reload the environment when hooks change it
+const envFileVar = "_WT_ENV_FILE"
+
+var envShell string
+
+func init() {
+	envCmd.Flags().StringVar(&envShell, "shell", "", "Print shell code that loads the current worktree's environment (zsh, bash, fish)")
+	_ = envCmd.RegisterFlagCompletionFunc("shell", cobra.FixedCompletions([]string{"zsh", "bash", "fish"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(envCmd)
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env [name]",
+	Short: "Show a worktree's environment",
+	Long: `Show the environment wt maintains for a worktree, as KEY=VALUE lines.
+
+The environment is built from, in increasing precedence:
+- Ports from resources: in .wt.yaml (WT_PORT_<NAME>)
+- Variables from env: in .wt.yaml
+- Variables hooks wrote to the file in $WT_ENV_FILE
+
+If no name is provided, the worktree containing the current directory is used.
+
+With --shell, prints shell code for the shell integration (wt init), which
+loads the environment when you enter a worktree and unloads it when you
+leave or run wt exit.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktreeNames,
+	RunE:              runEnv,
+}
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	if envShell != "" {
+		return runEnvShell(cmd)
+	}
+
+	repoRoot, cfg, err := loadIndexContext()
+	if err != nil {
+		return err
+	}
+	name, worktreePath, err := resolveWorktreeArg(repoRoot, cfg, args)
+	if err != nil {
+		return err
+	}
+	vars, err := worktreeEnv(repoRoot, cfg, name, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, name := range sortedKeys(vars) {
+		_, _ = fmt.Fprintf(out, "%s=%s\n", name, vars[name])
+	}
+	return nil
+}
+
+// worktreeEnv merges a worktree's environment: ports, then env: from the config,
+// then the hook-written env file
+func worktreeEnv(repoRoot string, cfg *config.Config, name, worktreePath string) (map[string]string, error) {
+	idx, _ := git.OpenMetadata(repoRoot).Index(name)
+	branch, _ := git.GetCurrentBranch(worktreePath)
+
+	vars := map[string]string{}
+	if idx > 0 && len(cfg.Resources) > 0 {
+		ports, err := provision.ResolvePorts(cfg.Resources, idx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve resources: %w", err)
+		}
+		for _, p := range ports {
+			vars[p.EnvName()] = fmt.Sprint(p.Port)
+			if p.Count > 1 {
+				vars[p.EnvName()+"_END"] = fmt.Sprint(p.End())
+			}
+		}
+	}
+
+	tmplVars := provision.Vars{Name: name, Branch: branch, Path: worktreePath, RepoRoot: repoRoot, Index: idx}
+	for key, value := range cfg.Env {
+		if !hooks.ValidEnvName(key) {
+			return nil, fmt.Errorf("invalid variable name in env: %q", key)
+		}
+		expanded, err := provision.Expand(value, tmplVars)
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", key, err)
+		}
+		vars[key] = expanded
+	}
+
+	fileVars, err := hooks.ReadEnvFile(hooks.EnvFilePath(repoRoot, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	for key, value := range fileVars {
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// envState records what the shell integration loaded, so it can be unloaded later
+type envState struct {
+	Root string            `json:"root"`           // Worktree path the environment belongs to
+	Hash string            `json:"hash"`           // Hash of the loaded variables, to detect changes
+	Vars []string          `json:"vars"`           // Names of the loaded variables
+	Prev map[string]string `json:"prev,omitempty"` // Values the loaded variables replaced
+}
+
+// encode serializes the state for the environment
+func (s *envState) encode() string {
+	data, _ := json.Marshal(s)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// decodeEnvState parses the state left by a previous wt env --shell; invalid state is ignored
+func decodeEnvState(value string) *envState {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	var s envState
+	if json.Unmarshal(data, &s) != nil || s.Root == "" {
+		return nil
+	}
+	return &s
+}
+
+// hashEnv returns a stable hash of a set of variables
+func hashEnv(vars map[string]string) string {
+	h := sha256.New()
+	for _, name := range sortedKeys(vars) {
+		_, _ = fmt.Fprintf(h, "%s=%s\x00", name, vars[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// runEnvShell prints shell code that moves the shell from the environment it has
+// loaded (if any) to the environment of the worktree containing the current directory
+func runEnvShell(cmd *cobra.Command) error {
+	if _, err := shell.EnvScript(envShell, nil, nil); err != nil {
+		return err
+	}
+	old := decodeEnvState(os.Getenv(envStateVar))
+
+	// Any failure to find a worktree just means there's nothing to load
+	var root, envFile string
+	var vars map[string]string
+	if repoRoot, cfg, err := loadIndexContext(); err == nil {
+		if name, worktreePath, err := resolveWorktreeArg(repoRoot, cfg, nil); err == nil {
+			if vars, err = worktreeEnv(repoRoot, cfg, name, worktreePath); err != nil {
+				return err
+			}
+			root = worktreePath
+			envFile = hooks.EnvFilePath(repoRoot, name)
+		}
+	}
+
+	hash := hashEnv(vars)
+	if old == nil && root == "" {
+		return nil
+	}
+	if old != nil && old.Root == root && old.Hash == hash && os.Getenv(envFileVar) == envFile {
+		return nil
+	}
+
+	set := map[string]string{}
+	var unset []string
+
+	// Unload the previous environment, restoring values it replaced
+	if old != nil {
+		for _, name := range old.Vars {
+			if prev, ok := old.Prev[name]; ok {
+				set[name] = prev
+			} else if _, ok := vars[name]; !ok {
+				unset = append(unset, name)
+			}
+		}
+	}
+
+	if root == "" {
+		unset = append(unset, envFileVar, envStateVar)
+	} else {
+		// Remember the values being replaced, as they were before any wt environment
+		state := &envState{Root: root, Hash: hash, Vars: sortedKeys(vars), Prev: map[string]string{}}
+		for name, value := range vars {
+			if old != nil && slices.Contains(old.Vars, name) {
+				if prev, ok := old.Prev[name]; ok {
+					state.Prev[name] = prev
+				}
+			} else if prev, ok := os.LookupEnv(name); ok {
+				state.Prev[name] = prev
+			}
+			set[name] = value
+		}
+		set[envStateVar] = state.encode()
+		set[envFileVar] = envFile
+	}
+
+	script, err := shell.EnvScript(envShell, set, unset)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), script)
+	return nil
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/agarcher/wt/internal/hooks"
+)
+
+func TestEnvCommand(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	writeHookScript(t, repoRoot, "env.sh", `echo "DATABASE_URL=postgres://localhost/app_$WT_INDEX" >> "$WT_ENV_FILE"`+"\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+resources:
+  web: "5173+index*10"
+env:
+  APP_NAME: "app-{name}"
+  DATABASE_URL: overridden-by-hook
+hooks:
+  post_create:
+    - script: env.sh
+`)
+
+	if _, _, err := executeCommand("create", "alpha"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "alpha", "--force") }()
+
+	stdout, _, err := executeCommand("env", "alpha")
+	if err != nil {
+		t.Fatalf("env failed: %v", err)
+	}
+	want := "APP_NAME=app-alpha\nDATABASE_URL=postgres://localhost/app_1\nWT_PORT_WEB=5183\n"
+	if stdout != want {
+		t.Errorf("unexpected env output:\ngot:\n%s\nwant:\n%s", stdout, want)
+	}
+
+	if _, _, err := executeCommand("env", "--shell", "tcsh"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+
+	// Outside a worktree with nothing loaded, there's nothing to do
+	t.Setenv(envStateVar, "")
+	t.Setenv("APP_NAME", "original")
+	stdout, _, err = executeCommand("env", "--shell", "bash")
+	if err != nil || stdout != "" {
+		t.Errorf("expected no output in the main repo, got %q (%v)", stdout, err)
+	}
+
+	// Entering the worktree loads its environment
+	_ = os.Chdir(filepath.Join(repoRoot, "worktrees", "alpha", "."))
+	stdout, _, err = executeCommand("env", "--shell", "bash")
+	if err != nil {
+		t.Fatalf("env --shell failed: %v", err)
+	}
+	for _, line := range []string{"export APP_NAME='app-alpha'\n", "export DATABASE_URL='postgres://localhost/app_1'\n", "export WT_PORT_WEB='5183'\n"} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf("expected %q in output:\n%s", line, stdout)
+		}
+	}
+	state := regexp.MustCompile(`export _WT_ENV_STATE='([^']*)'`).FindStringSubmatch(stdout)
+	if state == nil {
+		t.Fatalf("expected state in output:\n%s", stdout)
+	}
+	t.Setenv(envStateVar, state[1])
+	file := regexp.MustCompile(`export _WT_ENV_FILE='([^']*)'`).FindStringSubmatch(stdout)
+	if file == nil || !strings.HasSuffix(file[1], hooks.EnvFileName) {
+		t.Fatalf("expected the env file in output:\n%s", stdout)
+	}
+	t.Setenv(envFileVar, file[1])
+	t.Setenv("APP_NAME", "app-alpha")
+	t.Setenv("DATABASE_URL", "postgres://localhost/app_1")
+	t.Setenv("WT_PORT_WEB", "5183")
+
+	// Nothing changed: nothing to do
+	stdout, _, err = executeCommand("env", "--shell", "bash")
+	if err != nil || stdout != "" {
+		t.Errorf("expected no output when unchanged, got %q (%v)", stdout, err)
+	}
+
+	// Leaving restores replaced values and unsets the rest
+	_ = os.Chdir(repoRoot)
+	stdout, _, err = executeCommand("env", "--shell", "fish")
+	if err != nil {
+		t.Fatalf("env --shell failed: %v", err)
+	}
+	want = "set -e DATABASE_URL\nset -e WT_PORT_WEB\nset -e _WT_ENV_FILE\nset -e _WT_ENV_STATE\nset -gx APP_NAME 'original'\n"
+	if stdout != want {
+		t.Errorf("unexpected unload output:\ngot:\n%s\nwant:\n%s", stdout, want)
+	}
+}
@@ -0,0 +1,411 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeHookScript writes an executable hook script into the repo root
+func writeHookScript(t *testing.T, repoRoot, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repoRoot, name), []byte("#!/bin/bash\n"+body), 0755); err != nil {
+		t.Fatalf("failed to write hook %s: %v", name, err)
+	}
+}
+
+// writeWtConfig replaces the repo's .wt.yaml
+func writeWtConfig(t *testing.T, repoRoot, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repoRoot, ".wt.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write .wt.yaml: %v", err)
+	}
+}
+
+// commitAndMerge commits a file in the worktree and merges its branch into the main repo
+func commitAndMerge(t *testing.T, repoRoot, name string) {
+	t.Helper()
+	worktreePath := filepath.Join(repoRoot, "worktrees", name)
+	if err := os.WriteFile(filepath.Join(worktreePath, name+".txt"), []byte(name), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "Add " + name}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = worktreePath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	cmd := exec.Command("git", "merge", name)
+	cmd.Dir = repoRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to merge branch: %v\n%s", err, out)
+	}
+}
+
+func TestCdRunsPostSwitchHook(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	markerPath := filepath.Join(repoRoot, "switch.txt")
+	writeHookScript(t, repoRoot, "switch.sh", `echo "$WT_NAME|$PWD" >> "`+markerPath+`"`+"\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+hooks:
+  post_switch:
+    - script: switch.sh
+`)
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "switch-wt"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "switch-wt", "--force") }()
+
+	// Direct invocation (no shell wrapper) only prints the path
+	stdout, _, err := executeCommand("cd", "switch-wt")
+	if err != nil {
+		t.Fatalf("cd command failed: %v", err)
+	}
+	worktreePath := filepath.Join(repoRoot, "worktrees", "switch-wt")
+	if strings.TrimSpace(stdout) != worktreePath {
+		t.Errorf("expected path %q on stdout, got %q", worktreePath, stdout)
+	}
+	if _, err := os.Stat(markerPath); !os.IsNotExist(err) {
+		t.Fatal("post_switch hook should not run without the shell wrapper")
+	}
+
+	// Shell wrapper mode: path goes to WT_CD_FILE and the hook runs
+	cdFile := filepath.Join(t.TempDir(), "cd")
+	t.Setenv("WT_CD_FILE", cdFile)
+
+	if _, _, err := executeCommand("cd", "switch-wt"); err != nil {
+		t.Fatalf("cd command failed: %v", err)
+	}
+	if data, _ := os.ReadFile(cdFile); strings.TrimSpace(string(data)) != worktreePath {
+		t.Errorf("expected %q in WT_CD_FILE, got %q", worktreePath, data)
+	}
+
+	if _, _, err := executeCommand("exit"); err != nil {
+		t.Fatalf("exit command failed: %v", err)
+	}
+	if data, _ := os.ReadFile(cdFile); strings.TrimSpace(string(data)) != repoRoot {
+		t.Errorf("expected %q in WT_CD_FILE, got %q", repoRoot, data)
+	}
+
+	data, err := os.ReadFile(markerPath)
+	if err != nil {
+		t.Fatalf("post_switch hook did not run: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{"switch-wt|" + worktreePath, "|" + repoRoot}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected post_switch runs %q, got %q", want, lines)
+	}
+}
+
+func TestCleanupRunsCleanupHooks(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	preOut := filepath.Join(repoRoot, "pre-cleanup.json")
+	postOut := filepath.Join(repoRoot, "post-cleanup.txt")
+	writeHookScript(t, repoRoot, "pre-cleanup.sh", `cat "$WT_CONTEXT_FILE" > "`+preOut+`"`+"\n")
+	writeHookScript(t, repoRoot, "post-cleanup.sh", `echo "$WT_WORKTREE_COUNT:$WT_WORKTREES" > "`+postOut+`"`+"\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+hooks:
+  pre_cleanup:
+    - script: pre-cleanup.sh
+  post_cleanup:
+    - script: post-cleanup.sh
+`)
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	for _, name := range []string{"done-a", "done-b"} {
+		if _, _, err := executeCommand("create", name); err != nil {
+			t.Fatalf("create command failed: %v", err)
+		}
+		commitAndMerge(t, repoRoot, name)
+	}
+
+	// Dry run must not fire cleanup hooks
+	if _, _, err := executeCommand("cleanup", "--dry-run"); err != nil {
+		t.Fatalf("cleanup --dry-run failed: %v", err)
+	}
+	if _, err := os.Stat(preOut); !os.IsNotExist(err) {
+		t.Fatal("pre_cleanup hook should not run on dry run")
+	}
+
+	if _, _, err := executeCommand("cleanup", "--force"); err != nil {
+		t.Fatalf("cleanup --force failed: %v", err)
+	}
+
+	data, err := os.ReadFile(preOut)
+	if err != nil {
+		t.Fatalf("pre_cleanup hook did not run: %v", err)
+	}
+	var ctx struct {
+		Event     string `json:"event"`
+		RepoRoot  string `json:"repo_root"`
+		Worktrees []struct {
+			Name   string `json:"name"`
+			Branch string `json:"branch"`
+			Index  int    `json:"index"`
+		} `json:"worktrees"`
+	}
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		t.Fatalf("pre_cleanup context is not valid JSON: %v\n%s", err, data)
+	}
+	if ctx.Event != "pre_cleanup" || ctx.RepoRoot != repoRoot {
+		t.Errorf("unexpected context: %+v", ctx)
+	}
+	if len(ctx.Worktrees) != 2 || ctx.Worktrees[0].Name != "done-a" || ctx.Worktrees[1].Index != 2 {
+		t.Errorf("unexpected worktrees in context: %+v", ctx.Worktrees)
+	}
+
+	data, err = os.ReadFile(postOut)
+	if err != nil {
+		t.Fatalf("post_cleanup hook did not run: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "2:done-a done-b" {
+		t.Errorf("unexpected post_cleanup env: %q", data)
+	}
+}
+
+func TestPreCleanupHookFailureAborts(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeHookScript(t, repoRoot, "pre-cleanup.sh", "exit 1\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+hooks:
+  pre_cleanup:
+    - script: pre-cleanup.sh
+`)
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "blocked"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	commitAndMerge(t, repoRoot, "blocked")
+	defer func() { _, _, _ = executeCommand("delete", "blocked", "--force") }()
+
+	// Answer the confirmation prompt so the pre_cleanup hook is reached
+	r, w, _ := os.Pipe()
+	_, _ = w.WriteString("y\n")
+	_ = w.Close()
+	oldStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = oldStdin }()
+
+	if _, _, err := executeCommand("cleanup"); err == nil {
+		t.Error("expected cleanup to fail when pre_cleanup hook fails")
+	}
+	if _, err := os.Stat(filepath.Join(repoRoot, "worktrees", "blocked")); err != nil {
+		t.Error("worktree should not be deleted when pre_cleanup hook fails")
+	}
+}
+
+func TestPostMergeHookRunsOnce(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	markerPath := filepath.Join(repoRoot, "merged.txt")
+	writeHookScript(t, repoRoot, "merged.sh", `echo "$WT_NAME" >> "`+markerPath+`"`+"\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+hooks:
+  post_merge:
+    - script: merged.sh
+`)
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "landed"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	if _, _, err := executeCommand("create", "fresh"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() {
+		_, _, _ = executeCommand("delete", "landed", "--force")
+		_, _, _ = executeCommand("delete", "fresh", "--force")
+	}()
+
+	// Nothing merged yet
+	if _, _, err := executeCommand("list"); err != nil {
+		t.Fatalf("list command failed: %v", err)
+	}
+	if _, err := os.Stat(markerPath); !os.IsNotExist(err) {
+		t.Fatal("post_merge hook should not run before the branch is merged")
+	}
+
+	commitAndMerge(t, repoRoot, "landed")
+
+	// Read-only commands and a dry run see the merge but don't fire the hook
+	for _, args := range [][]string{{"list"}, {"list", "--json"}, {"info", "landed"}, {"cleanup", "--dry-run"}} {
+		if _, _, err := executeCommand(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	if _, err := os.Stat(markerPath); !os.IsNotExist(err) {
+		t.Fatal("post_merge hook should only run from cleanup and delete")
+	}
+
+	if _, _, err := executeCommand("cleanup", "--force"); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	data, err := os.ReadFile(markerPath)
+	if err != nil {
+		t.Fatalf("post_merge hook did not run: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "landed" {
+		t.Errorf("expected post_merge to run once for landed only, got %q", data)
+	}
+
+	// delete fires it too, before the worktree goes
+	if _, _, err := executeCommand("create", "shipped"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	commitAndMerge(t, repoRoot, "shipped")
+	if _, _, err := executeCommand("delete", "shipped"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	data, _ = os.ReadFile(markerPath)
+	if strings.TrimSpace(string(data)) != "landed\nshipped" {
+		t.Errorf("expected post_merge to run for shipped on delete, got %q", data)
+	}
+}
+
+func TestPostMergeHookOnStatus(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	markerPath := filepath.Join(repoRoot, "merged.txt")
+	writeHookScript(t, repoRoot, "merged.sh", `echo "$WT_NAME" >> "`+markerPath+`"`+"\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+merge_detection: status
+hooks:
+  post_merge:
+    - script: merged.sh
+`)
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "landed"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "landed", "--force") }()
+	commitAndMerge(t, repoRoot, "landed")
+
+	// list reports the merge while the worktree exists; later commands, including
+	// the removal, don't report it again
+	if _, _, err := executeCommand("list"); err != nil {
+		t.Fatalf("list command failed: %v", err)
+	}
+	data, err := os.ReadFile(markerPath)
+	if err != nil || strings.TrimSpace(string(data)) != "landed" {
+		t.Fatalf("expected list to run post_merge, got %q (%v)", data, err)
+	}
+	for _, args := range [][]string{{"info", "landed"}, {"list", "--json"}, {"delete", "landed"}} {
+		if _, _, err := executeCommand(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+	data, _ = os.ReadFile(markerPath)
+	if strings.TrimSpace(string(data)) != "landed" {
+		t.Errorf("expected post_merge to run once, got %q", data)
+	}
+}
+
+func TestDeleteHookReceivesStatus(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	envPath := filepath.Join(repoRoot, "delete-env.txt")
+	contextPath := filepath.Join(repoRoot, "delete-context.json")
+	writeHookScript(t, repoRoot, "pre-delete.sh", `env | grep '^WT_' | sort > "`+envPath+`"
+cat "$WT_CONTEXT_FILE" > "`+contextPath+`"
+`)
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+hooks:
+  pre_delete:
+    - script: pre-delete.sh
+`)
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "status-wt"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+
+	// Leave an untracked file so the worktree is dirty
+	worktreePath := filepath.Join(repoRoot, "worktrees", "status-wt")
+	if err := os.WriteFile(filepath.Join(worktreePath, "scratch.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, _, err := executeCommand("delete", "status-wt", "--force"); err != nil {
+		t.Fatalf("delete command failed: %v", err)
+	}
+
+	data, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf("pre-delete hook did not run: %v", err)
+	}
+	for _, want := range []string{
+		"WT_EVENT=pre_delete",
+		"WT_FORCE=true",
+		"WT_DIRTY=true",
+		"WT_MERGED=false",
+		"WT_NEW=true",
+		"WT_BASE_COMMIT=",
+		"WT_COMPARISON_REF=",
+		"WT_CONTEXT_FILE=",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in hook environment:\n%s", want, data)
+		}
+	}
+
+	data, err = os.ReadFile(contextPath)
+	if err != nil {
+		t.Fatalf("failed to read hook context: %v", err)
+	}
+	var ctx struct {
+		Event  string `json:"event"`
+		Name   string `json:"name"`
+		Force  bool   `json:"force"`
+		Status struct {
+			Dirty bool `json:"dirty"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		t.Fatalf("hook context is not valid JSON: %v\n%s", err, data)
+	}
+	if ctx.Event != "pre_delete" || ctx.Name != "status-wt" || !ctx.Force || !ctx.Status.Dirty {
+		t.Errorf("unexpected hook context: %s", data)
+	}
+}
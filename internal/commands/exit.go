@@ -2,8 +2,11 @@ package commands
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
 	"github.com/spf13/cobra"
 )
 
@@ -16,8 +19,14 @@ var exitCmd = &cobra.Command{
 	Short: "Return to the main repository",
 	Long: `Output the path to the main repository root.
 
+In a bare repository, which has no main checkout, this is the default
+worktree set with:
+
+  git config wt.defaultWorktree <path>   # relative to the bare repository
+
 The shell integration wrapper will use this output to change
-to the main repository directory.
+to the main repository directory, then run any post_switch hooks
+defined in .wt.yaml.
 
 Note: This command requires shell integration. Add this to your
 shell rc file:
@@ -32,13 +41,55 @@ func runExit(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("not in a git repository: %w", err)
 	}
+	if git.IsGitDir(repoRoot) {
+		return fmt.Errorf("the bare repository %s has no home worktree; set one with `git config wt.defaultWorktree <path>`", repoRoot)
+	}
 
 	// Check that config exists (to confirm this is a wt-enabled repo)
 	if !config.Exists(repoRoot) {
 		return fmt.Errorf("not in a wt-enabled repository (no .wt.yaml found)")
 	}
 
-	// Output the path to stdout (shell wrapper will handle the actual cd)
-	_, _ = fmt.Fprintln(cmd.OutOrStdout(), repoRoot)
+	cdFile := os.Getenv("WT_CD_FILE")
+	if cdFile == "" {
+		// Direct invocation: output the path to stdout
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), repoRoot)
+		return nil
+	}
+
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		_ = os.WriteFile(cdFile, []byte(repoRoot+"\n"), 0600)
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	switchToMainRepo(cmd, repoRoot, cfg)
 	return nil
 }
+
+// switchToMainRepo outputs the main repository's path for the shell wrapper to cd to
+// and runs post_switch hooks, or prints the path when invoked directly
+func switchToMainRepo(cmd *cobra.Command, repoRoot string, cfg *config.Config) {
+	cdFile := os.Getenv("WT_CD_FILE")
+	if cdFile == "" {
+		// Direct invocation: output the path to stdout
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), repoRoot)
+		return
+	}
+
+	// Shell wrapper mode: write path to file for cd, then run post-switch hooks
+	_ = os.WriteFile(cdFile, []byte(repoRoot+"\n"), 0600)
+
+	if len(cfg.Hooks.PostSwitch) > 0 {
+		// The main repo has no worktree name or index
+		branch, _ := git.GetCurrentBranch(repoRoot)
+		env := &hooks.Env{
+			Path:        repoRoot,
+			Branch:      branch,
+			RepoRoot:    repoRoot,
+			WorktreeDir: cfg.WorktreeDir,
+		}
+		if err := hooks.RunPostSwitch(cfg, env); err != nil {
+			cmd.Printf("Warning: post-switch hook failed: %v\n", err)
+		}
+	}
+}
@@ -3,10 +3,15 @@ package commands
 import (
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/agarcher/wt/internal/provision"
 )
 
 // KeyValue represents a key-value pair for formatting
@@ -23,7 +28,9 @@ type VerboseInfo struct {
 	CreatedAt     time.Time
 	Status        *git.WorktreeStatus
 	CurrentMarker string
-	HookOutput    string
+	Ports         []provision.Port  // Named ports from resources:
+	HookOutput    string            // Output of text info hooks
+	HookFields    []hooks.InfoField // Fields from JSON info hooks
 }
 
 // PrintVerboseWorktree prints a single worktree in verbose format
@@ -34,6 +41,9 @@ func PrintVerboseWorktree(out io.Writer, info VerboseInfo) {
 	// Parse and append hook output
 	hookPairs, rawLines := ParseHookKeyValues(info.HookOutput)
 	pairs = append(pairs, hookPairs...)
+	for _, f := range info.HookFields {
+		pairs = append(pairs, KeyValue{Key: f.Label, Value: FormatHookField(f)})
+	}
 
 	// Calculate max key width for alignment
 	maxKeyWidth := 0
@@ -78,6 +88,10 @@ func collectBuiltinPairs(info VerboseInfo) []KeyValue {
 		})
 	}
 
+	if len(info.Ports) > 0 {
+		pairs = append(pairs, KeyValue{Key: "Ports", Value: formatPorts(info.Ports)})
+	}
+
 	statusStr := FormatCompactStatus(info.Status)
 	if statusStr != "" {
 		pairs = append(pairs, KeyValue{Key: "Status", Value: statusStr})
@@ -86,6 +100,15 @@ func collectBuiltinPairs(info VerboseInfo) []KeyValue {
 	return pairs
 }
 
+// formatPorts formats named ports for display, e.g. "web=5193, workers=6020-6029"
+func formatPorts(ports []provision.Port) string {
+	parts := make([]string, len(ports))
+	for i, p := range ports {
+		parts[i] = p.Name + "=" + p.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
 // ParseHookKeyValues parses hook output into key-value pairs and raw lines.
 // Lines matching "Key: value" pattern become KeyValue pairs.
 // Other non-empty lines are returned as raw lines.
@@ -114,6 +137,45 @@ func ParseHookKeyValues(output string) (pairs []KeyValue, raw []string) {
 	return pairs, raw
 }
 
+// statusColors maps well-known status values reported by info hooks to colors
+var statusColors = map[string]string{
+	"ok": green, "up": green, "running": green, "healthy": green, "ready": green, "pass": green, "passing": green,
+	"warn": yellow, "warning": yellow, "degraded": yellow, "pending": yellow, "starting": yellow,
+	"error": red, "down": red, "stopped": red, "failed": red, "fail": red, "failing": red, "unhealthy": red,
+}
+
+// FormatHookField renders an info hook field for display according to its type:
+// urls become terminal hyperlinks, durations are humanized and statuses are colored.
+func FormatHookField(f hooks.InfoField) string {
+	value := f.String()
+	switch f.Type {
+	case hooks.FieldTypeURL:
+		// OSC 8 hyperlink; terminals without support just show the text
+		return "\033]8;;" + value + "\033\\" + value + "\033]8;;\033\\"
+	case hooks.FieldTypeDuration:
+		// Numbers are seconds, strings use Go duration syntax ("90s", "1h30m")
+		if secs, err := strconv.ParseFloat(value, 64); err == nil {
+			return formatDuration(time.Duration(secs * float64(time.Second)))
+		}
+		if d, err := time.ParseDuration(value); err == nil {
+			return formatDuration(d)
+		}
+	case hooks.FieldTypeStatus:
+		if color, ok := statusColors[strings.ToLower(value)]; ok {
+			return color + value + reset
+		}
+	}
+	return value
+}
+
+// ansiPattern matches SGR color codes and OSC 8 hyperlink markers
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m|\x1b\]8;;[^\x1b]*\x1b\\`)
+
+// visibleWidth returns the number of terminal columns s occupies, ignoring escape codes
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiPattern.ReplaceAllString(s, ""))
+}
+
 // formatAge formats a duration as a human-readable age string
 func formatAge(d time.Duration) string {
 	days := int(d.Hours() / 24)
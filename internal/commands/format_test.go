@@ -2,11 +2,14 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/agarcher/wt/internal/provision"
 )
 
 func TestParseHookKeyValues(t *testing.T) {
@@ -173,6 +176,33 @@ func TestPrintVerboseWorktree(t *testing.T) {
 			},
 			contains: []string{"Port:", "3001", "Database:", "dev_test"},
 		},
+		{
+			name: "with JSON hook fields",
+			info: VerboseInfo{
+				Name:          "feature-test",
+				Branch:        "feature-test",
+				CurrentMarker: "  ",
+				Status:        &git.WorktreeStatus{},
+				HookFields: []hooks.InfoField{
+					{Key: "port", Label: "Port", Type: hooks.FieldTypePort, Value: json.Number("5183")},
+				},
+			},
+			contains: []string{"Port:", "5183"},
+		},
+		{
+			name: "with ports",
+			info: VerboseInfo{
+				Name:          "feature-test",
+				Branch:        "feature-test",
+				CurrentMarker: "  ",
+				Status:        &git.WorktreeStatus{},
+				Ports: []provision.Port{
+					{Name: "web", Port: 5183, Count: 1},
+					{Name: "workers", Port: 6010, Count: 10},
+				},
+			},
+			contains: []string{"Ports:", "web=5183, workers=6010-6019"},
+		},
 		{
 			name: "with hook raw output",
 			info: VerboseInfo{
@@ -292,3 +322,39 @@ func TestKeyValueAlignment(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatHookField(t *testing.T) {
+	tests := []struct {
+		name  string
+		field hooks.InfoField
+		want  string
+	}{
+		{"plain", hooks.InfoField{Value: "dev_db"}, "dev_db"},
+		{"port", hooks.InfoField{Type: hooks.FieldTypePort, Value: json.Number("5183")}, "5183"},
+		{"url", hooks.InfoField{Type: hooks.FieldTypeURL, Value: "http://x"}, "\033]8;;http://x\033\\http://x\033]8;;\033\\"},
+		{"duration seconds", hooks.InfoField{Type: hooks.FieldTypeDuration, Value: json.Number("5400")}, "1h30m"},
+		{"duration string", hooks.InfoField{Type: hooks.FieldTypeDuration, Value: "90s"}, "1m"},
+		{"duration unparseable", hooks.InfoField{Type: hooks.FieldTypeDuration, Value: "soon"}, "soon"},
+		{"status known", hooks.InfoField{Type: hooks.FieldTypeStatus, Value: "Running"}, green + "Running" + reset},
+		{"status failing", hooks.InfoField{Type: hooks.FieldTypeStatus, Value: "down"}, red + "down" + reset},
+		{"status unknown", hooks.InfoField{Type: hooks.FieldTypeStatus, Value: "paused"}, "paused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatHookField(tt.field); got != tt.want {
+				t.Errorf("FormatHookField() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	link := FormatHookField(hooks.InfoField{Type: hooks.FieldTypeURL, Value: "http://x"})
+	if got := visibleWidth(link); got != len("http://x") {
+		t.Errorf("visibleWidth(link) = %d, want %d", got, len("http://x"))
+	}
+	if got := visibleWidth(bold + "dirty" + reset + " ↑2"); got != 8 {
+		t.Errorf("visibleWidth(styled) = %d, want 8", got)
+	}
+}
@@ -0,0 +1,323 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+var hookRunDryRun bool
+
+func init() {
+	hookRunCmd.Flags().BoolVarP(&hookRunDryRun, "dry-run", "n", false, "Print the environment and commands without running them")
+	hookCmd.AddCommand(hookRunCmd)
+	hookCmd.AddCommand(hookListCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Inspect and run configured hooks",
+	Long: `Inspect and manually run the hooks configured in .wt.yaml.
+
+Useful when developing hook scripts: hooks can be triggered without
+creating or deleting throwaway worktrees.`,
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:   "run <event> [name]",
+	Short: "Run the hooks for an event",
+	Long: `Run the hooks configured for an event with the same environment
+the triggering command would give them.
+
+If no name is provided and you're currently inside a worktree,
+that worktree is used. pre_create accepts a name that doesn't exist
+yet. pre_cleanup and post_cleanup take no name; they receive the
+worktrees wt cleanup would currently remove.
+
+Use --dry-run to print the environment, JSON context and commands
+without running anything.
+
+Examples:
+  wt hook run post_create feature-x     # Re-run setup for a worktree
+  wt hook run info                      # Show info hook output for the current worktree
+  wt hook run pre_delete feature-x -n   # Show what pre_delete hooks would receive`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeHookRunArgs,
+	RunE:              runHookRun,
+}
+
+var hookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured hooks",
+	Long: `List the hooks configured for each event, with the resolved script
+paths and whether each script exists.`,
+	Args: cobra.NoArgs,
+	RunE: runHookList,
+}
+
+func runHookRun(cmd *cobra.Command, args []string) error {
+	event := args[0]
+
+	// Find the main repository root
+	repoRoot, err := config.GetMainRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	entries, err := hooks.Entries(cfg, event)
+	if err != nil {
+		return err
+	}
+
+	env, err := buildHookEnv(cmd, repoRoot, cfg, event, args[1:])
+	if err != nil {
+		return err
+	}
+	env = hooks.WithPorts(env, cfg.Resources)
+
+	if hookRunDryRun {
+		return printHookPlan(cmd.OutOrStdout(), event, entries, env)
+	}
+
+	if len(entries) == 0 {
+		cmd.Printf("No %s hooks configured\n", event)
+		return nil
+	}
+
+	// Info hooks capture their output; show it the way wt info would
+	if event == hooks.EventInfo {
+		output, err := hooks.RunInfo(cfg, env)
+		if output != nil {
+			out := cmd.OutOrStdout()
+			_, _ = fmt.Fprint(out, output.Text)
+			for _, f := range output.Fields {
+				_, _ = fmt.Fprintf(out, "%s: %s\n", f.Label, FormatHookField(f))
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("info hook failed: %w", err)
+		}
+		return nil
+	}
+
+	if err := hooks.RunEvent(cfg, event, env); err != nil {
+		return fmt.Errorf("%s hook failed: %w", event, err)
+	}
+	return nil
+}
+
+// buildHookEnv builds the hook environment the command that triggers event would use
+func buildHookEnv(cmd *cobra.Command, repoRoot string, cfg *config.Config, event string, args []string) (*hooks.Env, error) {
+	env := &hooks.Env{
+		RepoRoot:    repoRoot,
+		WorktreeDir: cfg.WorktreeDir,
+	}
+
+	switch event {
+	case hooks.EventPreCleanup, hooks.EventPostCleanup:
+		// Repo-wide: the worktrees cleanup would remove right now
+		if len(args) > 0 {
+			return nil, fmt.Errorf("%s hooks run for all cleanup candidates and take no worktree name", event)
+		}
+		comparisonRef, err := resolveComparisonRef(cmd, repoRoot, cfg)
+		if err != nil {
+			return nil, err
+		}
+		setup := &CompareSetup{RepoRoot: repoRoot, Config: cfg, ComparisonRef: comparisonRef}
+		candidates, err := findCleanupCandidates(cmd, setup)
+		if err != nil {
+			return nil, err
+		}
+		refs := make([]hooks.WorktreeRef, 0, len(candidates))
+		for _, c := range candidates {
+			refs = append(refs, c.ref())
+		}
+		return cleanupHookEnv(setup, refs), nil
+
+	case hooks.EventPreCreate:
+		// The worktree doesn't exist yet; use the path and branch create would
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s hooks need a worktree name", event)
+		}
+		env.Name = args[0]
+		env.Path = filepath.Join(cfg.WorktreesDir(repoRoot), args[0])
+		index, _ := git.OpenMetadata(repoRoot).AllocateIndex(cfg.Index.Max, cfg.Index.Reserved...)
+		branch, err := newBranchName(repoRoot, cfg, args[0], index)
+		if err != nil {
+			return nil, err
+		}
+		env.Branch = branch
+		return env, nil
+
+	case hooks.EventPostSwitch:
+		// Outside any worktree, switching means `wt exit` back to the repo root
+		cwd, _ := os.Getwd()
+		if len(args) == 0 && !git.IsInsideWorktree(cfg.WorktreesDir(repoRoot), cwd) {
+			env.Path = repoRoot
+			env.Branch, _ = git.GetCurrentBranch(repoRoot)
+			return env, nil
+		}
+	}
+
+	name, worktreePath, err := resolveWorktreeArg(repoRoot, cfg, args)
+	if err != nil {
+		return nil, err
+	}
+	env.Name = name
+	env.Path = worktreePath
+	env.Branch, _ = git.GetCurrentBranch(worktreePath)
+	meta := git.OpenMetadata(repoRoot)
+	env.Index, _ = meta.Index(name)
+
+	switch event {
+	case hooks.EventPostCreate:
+		env.BaseCommit, _ = meta.InitialCommit(name)
+	case hooks.EventPreDelete, hooks.EventPostDelete, hooks.EventPostMerge, hooks.EventInfo:
+		comparisonRef, err := resolveComparisonRef(cmd, repoRoot, cfg)
+		if err != nil {
+			return nil, err
+		}
+		status, _ := git.GetWorktreeStatus(repoRoot, worktreePath, name, env.Branch, comparisonRef, nil)
+		setHookStatus(env, comparisonRef, status)
+	}
+	return env, nil
+}
+
+// printHookPlan prints what running an event's hooks would do, without running them
+func printHookPlan(out io.Writer, event string, entries []config.HookEntry, env *hooks.Env) error {
+	env.Event = event
+	context, err := env.ToJSON()
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "Event:   %s\n", event)
+	_, _ = fmt.Fprintf(out, "Workdir: %s\n", hooks.WorkDir(event, env))
+
+	_, _ = fmt.Fprintln(out, "\nEnvironment:")
+	for _, v := range env.ToEnvVars() {
+		_, _ = fmt.Fprintf(out, "  %s\n", v)
+	}
+
+	_, _ = fmt.Fprintln(out, "\nContext ($WT_CONTEXT_FILE):")
+	_, _ = fmt.Fprintf(out, "  %s\n", context)
+
+	_, _ = fmt.Fprintln(out, "\nCommands:")
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintf(out, "  (no %s hooks configured)\n", event)
+	}
+	for _, entry := range entries {
+		scriptPath := hooks.ResolveScript(entry, env.RepoRoot)
+		missing := ""
+		if _, err := os.Stat(scriptPath); err != nil {
+			missing = "  (not found)"
+		}
+		_, _ = fmt.Fprintf(out, "  /bin/bash %s%s\n", scriptPath, missing)
+		for _, k := range sortedKeys(entry.Env) {
+			_, _ = fmt.Fprintf(out, "    %s=%s\n", k, entry.Env[k])
+		}
+	}
+	return nil
+}
+
+func runHookList(cmd *cobra.Command, args []string) error {
+	// Find the main repository root
+	repoRoot, err := config.GetMainRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	found := false
+	for _, event := range hooks.Events() {
+		entries, _ := hooks.Entries(cfg, event)
+		if len(entries) == 0 {
+			continue
+		}
+		if found {
+			_, _ = fmt.Fprintln(out)
+		}
+		found = true
+
+		// Align resolved paths across this event's scripts
+		scriptWidth := 0
+		for _, entry := range entries {
+			scriptWidth = max(scriptWidth, len(entry.Script))
+		}
+
+		_, _ = fmt.Fprintf(out, "%s:\n", event)
+		for _, entry := range entries {
+			scriptPath := hooks.ResolveScript(entry, repoRoot)
+			state := ""
+			if _, err := os.Stat(scriptPath); err != nil {
+				state = " (not found)"
+			}
+			_, _ = fmt.Fprintf(out, "  %-*s  %s%s%s\n", scriptWidth, entry.Script, scriptPath, state, hookEntryOptions(entry))
+		}
+	}
+
+	if !found {
+		_, _ = fmt.Fprintln(out, "No hooks configured")
+	}
+	return nil
+}
+
+// hookEntryOptions describes an entry's non-default settings, e.g. " [format=json env=PORT]"
+func hookEntryOptions(entry config.HookEntry) string {
+	var opts []string
+	if entry.Format != "" {
+		opts = append(opts, "format="+entry.Format)
+	}
+	if entry.Timeout != "" {
+		opts = append(opts, "timeout="+entry.Timeout)
+	}
+	if entry.CacheTTL != "" {
+		opts = append(opts, "cache_ttl="+entry.CacheTTL)
+	}
+	if len(entry.Env) > 0 {
+		opts = append(opts, "env="+strings.Join(sortedKeys(entry.Env), ","))
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(opts, " ") + "]"
+}
+
+// sortedKeys returns a map's keys in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// completeHookRunArgs completes the event name, then a worktree name
+func completeHookRunArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return hooks.Events(), cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeWorktreeNames(cmd, args[1:], toComplete)
+}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
+)
+
+// isMergedStatus reports whether a worktree counts as merged. New worktrees are
+// trivially merged (no commits of their own), so they don't count.
+func isMergedStatus(status *git.WorktreeStatus) bool {
+	return status.IsMerged && !status.IsNew && status.CommitsAhead == 0
+}
+
+// setHookStatus fills in the comparison details of a hook environment from a worktree status
+func setHookStatus(env *hooks.Env, comparisonRef string, status *git.WorktreeStatus) {
+	env.ComparisonRef = comparisonRef
+	env.BaseCommit, _ = git.OpenMetadata(env.RepoRoot).InitialCommit(env.Name)
+	if status == nil {
+		return
+	}
+	env.Status = &hooks.Status{
+		Dirty:         status.HasUncommittedChanges,
+		Merged:        isMergedStatus(status),
+		New:           status.IsNew,
+		CommitsAhead:  status.CommitsAhead,
+		CommitsBehind: status.CommitsBehind,
+	}
+}
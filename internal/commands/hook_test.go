@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupTestRepoWithHooks creates a test repo whose .wt.yaml configures a post_create
+// hook that records its environment, and a pre_delete hook whose script is missing
+func setupTestRepoWithHooks(t *testing.T) (repoRoot, markerPath string, cleanup func()) {
+	t.Helper()
+
+	repoRoot, cleanup = setupTestRepo(t)
+	markerPath = filepath.Join(repoRoot, "post-create.txt")
+	writeHookScript(t, repoRoot, "setup.sh", `echo "$WT_EVENT $WT_NAME $WT_INDEX" >> "`+markerPath+`"`+"\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+hooks:
+  post_create:
+    - script: setup.sh
+      env:
+        SETUP_MODE: full
+  pre_delete:
+    - script: scripts/missing.sh
+`)
+	return repoRoot, markerPath, cleanup
+}
+
+func TestHookRun(t *testing.T) {
+	repoRoot, markerPath, cleanup := setupTestRepoWithHooks(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "hook-wt"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "hook-wt", "--force") }()
+
+	// Re-run post_create for an existing worktree
+	if _, _, err := executeCommand("hook", "run", "post_create", "hook-wt"); err != nil {
+		t.Fatalf("hook run failed: %v", err)
+	}
+	data, err := os.ReadFile(markerPath)
+	if err != nil {
+		t.Fatalf("post_create hook did not run: %v", err)
+	}
+	if string(data) != "post_create hook-wt 1\npost_create hook-wt 1\n" {
+		t.Errorf("expected hook to run on create and again manually, got %q", data)
+	}
+
+	// Dry run prints the plan without running anything
+	stdout, _, err := executeCommand("hook", "run", "post_create", "hook-wt", "--dry-run")
+	if err != nil {
+		t.Fatalf("hook run --dry-run failed: %v", err)
+	}
+	worktreePath := filepath.Join(repoRoot, "worktrees", "hook-wt")
+	for _, want := range []string{
+		"Event:   post_create",
+		"Workdir: " + worktreePath,
+		"WT_NAME=hook-wt",
+		"WT_INDEX=1",
+		`"event":"post_create"`,
+		"/bin/bash " + filepath.Join(repoRoot, "setup.sh"),
+		"SETUP_MODE=full",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("dry run output missing %q:\n%s", want, stdout)
+		}
+	}
+	data, _ = os.ReadFile(markerPath)
+	if strings.Count(string(data), "\n") != 2 {
+		t.Errorf("dry run should not run hooks, marker has %q", data)
+	}
+
+	// pre_create works for a worktree that doesn't exist yet
+	stdout, _, err = executeCommand("hook", "run", "pre_create", "future-wt", "-n")
+	if err != nil {
+		t.Fatalf("hook run pre_create failed: %v", err)
+	}
+	if !strings.Contains(stdout, "WT_PATH="+filepath.Join(repoRoot, "worktrees", "future-wt")) || !strings.Contains(stdout, "no pre_create hooks configured") {
+		t.Errorf("unexpected pre_create dry run output:\n%s", stdout)
+	}
+
+	// Missing scripts fail like they would during the real command
+	if _, _, err := executeCommand("hook", "run", "pre_delete", "hook-wt"); err == nil {
+		t.Error("expected error for missing hook script")
+	}
+}
+
+func TestHookRunErrors(t *testing.T) {
+	repoRoot, _, cleanup := setupTestRepoWithHooks(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown event", []string{"hook", "run", "post_frobnicate", "x"}, "unknown hook event"},
+		{"missing worktree", []string{"hook", "run", "post_create", "nope"}, "does not exist"},
+		{"pre_create without name", []string{"hook", "run", "pre_create"}, "need a worktree name"},
+		{"cleanup with name", []string{"hook", "run", "pre_cleanup", "x"}, "take no worktree name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := executeCommand(tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestHookList(t *testing.T) {
+	repoRoot, _, cleanup := setupTestRepoWithHooks(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	stdout, _, err := executeCommand("hook", "list")
+	if err != nil {
+		t.Fatalf("hook list failed: %v", err)
+	}
+
+	want := "post_create:\n" +
+		"  setup.sh  " + filepath.Join(repoRoot, "setup.sh") + " [env=SETUP_MODE]\n" +
+		"\n" +
+		"pre_delete:\n" +
+		"  scripts/missing.sh  " + filepath.Join(repoRoot, "scripts", "missing.sh") + " (not found)\n"
+	if stdout != want {
+		t.Errorf("unexpected hook list output:\ngot:\n%s\nwant:\n%s", stdout, want)
+	}
+}
@@ -0,0 +1,344 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var indexCompactDryRun bool
+
+func init() {
+	indexCompactCmd.Flags().BoolVarP(&indexCompactDryRun, "dry-run", "n", false, "Show the new indexes without changing them")
+	indexCmd.AddCommand(indexListCmd)
+	indexCmd.AddCommand(indexSetCmd)
+	indexCmd.AddCommand(indexReleaseCmd)
+	indexCmd.AddCommand(indexCompactCmd)
+	rootCmd.AddCommand(indexCmd)
+}
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Show and manage worktree indexes",
+	Long: `Show and manage the numeric indexes wt allocates to worktrees.
+
+Indexes are allocated when a worktree is created and are available to
+hooks as WT_INDEX. Use these commands to move a worktree off an index
+that collides with another service, or to renumber worktrees.
+
+Indexes listed under index.reserved in .wt.yaml are never allocated.`,
+}
+
+var indexListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List worktree indexes",
+	Args:  cobra.NoArgs,
+	RunE:  runIndexList,
+}
+
+var indexSetCmd = &cobra.Command{
+	Use:   "set <name> <index>",
+	Short: "Assign an index to a worktree",
+	Long: `Assign a specific index to a worktree.
+
+The index must not be used by another worktree, be reserved, or exceed
+index.max. Hooks that wrote index-based configuration (such as ports)
+may need re-running afterwards, e.g. wt hook run post_create <name>.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeWorktreeNames,
+	RunE:              runIndexSet,
+}
+
+var indexReleaseCmd = &cobra.Command{
+	Use:   "release <name>",
+	Short: "Remove a worktree's index",
+	Long: `Remove a worktree's index so it can be allocated to another worktree.
+
+The worktree keeps working without an index; hooks no longer receive
+WT_INDEX for it. Use wt index set to give it an index again.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorktreeNames,
+	RunE:              runIndexRelease,
+}
+
+var indexCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Renumber worktrees to the lowest free indexes",
+	Long: `Renumber indexed worktrees so they use the lowest available indexes,
+skipping reserved ones. Worktrees keep their relative order.
+
+Use --dry-run to see the new indexes without changing anything.`,
+	Args: cobra.NoArgs,
+	RunE: runIndexCompact,
+}
+
+// loadIndexContext finds the main repository and loads its configuration
+func loadIndexContext() (string, *config.Config, error) {
+	repoRoot, err := config.GetMainRepoRoot()
+	if err != nil {
+		return "", nil, fmt.Errorf("not in a git repository: %w", err)
+	}
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return repoRoot, cfg, nil
+}
+
+func runIndexList(cmd *cobra.Command, args []string) error {
+	repoRoot, cfg, err := loadIndexContext()
+	if err != nil {
+		return err
+	}
+
+	indexes, err := git.OpenMetadata(repoRoot).Indexes()
+	if err != nil {
+		return fmt.Errorf("failed to read indexes: %w", err)
+	}
+	names, err := managedWorktreeNames(repoRoot, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Indexed worktrees first, in index order
+	sort.SliceStable(names, func(i, j int) bool {
+		a, aok := indexes[names[i]]
+		b, bok := indexes[names[j]]
+		if aok != bok {
+			return aok
+		}
+		return a < b
+	})
+
+	out := cmd.OutOrStdout()
+	if len(names) == 0 {
+		_, _ = fmt.Fprintln(out, "No worktrees")
+	}
+
+	nameWidth := 0
+	for _, name := range names {
+		nameWidth = max(nameWidth, len(name))
+	}
+	for _, name := range names {
+		idx, ok := indexes[name]
+		if !ok {
+			_, _ = fmt.Fprintf(out, "  %3s  %-*s  (no index)\n", "-", nameWidth, name)
+			continue
+		}
+		notes := indexProblems(cfg, indexes, name, idx)
+		line := fmt.Sprintf("  %3d  %-*s", idx, nameWidth, name)
+		if len(notes) > 0 {
+			line += "  (" + strings.Join(notes, ", ") + ")"
+		}
+		_, _ = fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+
+	if len(cfg.Index.Reserved) > 0 || cfg.Index.Max > 0 {
+		_, _ = fmt.Fprintln(out)
+	}
+	if len(cfg.Index.Reserved) > 0 {
+		_, _ = fmt.Fprintf(out, "Reserved: %s\n", joinInts(cfg.Index.Reserved))
+	}
+	if cfg.Index.Max > 0 {
+		_, _ = fmt.Fprintf(out, "Max:      %d\n", cfg.Index.Max)
+	}
+	return nil
+}
+
+// indexProblems describes why a worktree's index conflicts with the config or another worktree
+func indexProblems(cfg *config.Config, indexes map[string]int, name string, idx int) []string {
+	var problems []string
+	if slices.Contains(cfg.Index.Reserved, idx) {
+		problems = append(problems, "reserved")
+	}
+	if cfg.Index.Max > 0 && idx > cfg.Index.Max {
+		problems = append(problems, fmt.Sprintf("above max %d", cfg.Index.Max))
+	}
+	if other := indexOwner(indexes, idx, name); other != "" {
+		problems = append(problems, fmt.Sprintf("also used by %q", other))
+	}
+	return problems
+}
+
+// indexOwner returns the worktree other than exclude that uses idx, if any
+func indexOwner(indexes map[string]int, idx int, exclude string) string {
+	names := make([]string, 0, len(indexes))
+	for name := range indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name != exclude && indexes[name] == idx {
+			return name
+		}
+	}
+	return ""
+}
+
+func runIndexSet(cmd *cobra.Command, args []string) error {
+	repoRoot, cfg, err := loadIndexContext()
+	if err != nil {
+		return err
+	}
+
+	name, _, err := resolveWorktreeArg(repoRoot, cfg, args[:1])
+	if err != nil {
+		return err
+	}
+	idx, err := strconv.Atoi(args[1])
+	if err != nil || idx < 1 {
+		return fmt.Errorf("invalid index %q: must be a positive integer", args[1])
+	}
+
+	// Collision detection
+	if cfg.Index.Max > 0 && idx > cfg.Index.Max {
+		return fmt.Errorf("index %d exceeds index.max (%d)", idx, cfg.Index.Max)
+	}
+	if slices.Contains(cfg.Index.Reserved, idx) {
+		return fmt.Errorf("index %d is reserved in .wt.yaml", idx)
+	}
+	meta := git.OpenMetadata(repoRoot)
+	indexes, err := meta.Indexes()
+	if err != nil {
+		return fmt.Errorf("failed to read indexes: %w", err)
+	}
+	if other := indexOwner(indexes, idx, name); other != "" {
+		return fmt.Errorf("index %d is already used by worktree %q", idx, other)
+	}
+
+	old, hadIndex := indexes[name]
+	if hadIndex && old == idx {
+		cmd.Printf("Worktree %q already has index %d\n", name, idx)
+		return nil
+	}
+	if err := meta.SetIndex(name, idx); err != nil {
+		return fmt.Errorf("failed to set index: %w", err)
+	}
+	if hadIndex {
+		cmd.Printf("Changed index of %q from %d to %d\n", name, old, idx)
+	} else {
+		cmd.Printf("Set index of %q to %d\n", name, idx)
+	}
+
+	// Report the worktree's new ports and whether they are free
+	_, conflicts, err := resolveWorktreePorts(cmd, cfg, repoRoot, name, idx)
+	if err != nil {
+		cmd.Printf("Warning: %v\n", err)
+		return nil
+	}
+	reportPortConflicts(cmd, name, conflicts)
+	return nil
+}
+
+func runIndexRelease(cmd *cobra.Command, args []string) error {
+	repoRoot, cfg, err := loadIndexContext()
+	if err != nil {
+		return err
+	}
+
+	name, _, err := resolveWorktreeArg(repoRoot, cfg, args)
+	if err != nil {
+		return err
+	}
+	meta := git.OpenMetadata(repoRoot)
+	idx, err := meta.Index(name)
+	if err != nil {
+		return fmt.Errorf("worktree %q has no index", name)
+	}
+	if err := meta.ReleaseIndex(name); err != nil {
+		return fmt.Errorf("failed to release index: %w", err)
+	}
+	cmd.Printf("Released index %d from %q\n", idx, name)
+	return nil
+}
+
+func runIndexCompact(cmd *cobra.Command, args []string) error {
+	repoRoot, cfg, err := loadIndexContext()
+	if err != nil {
+		return err
+	}
+
+	meta := git.OpenMetadata(repoRoot)
+	indexes, err := meta.Indexes()
+	if err != nil {
+		return fmt.Errorf("failed to read indexes: %w", err)
+	}
+
+	// Keep relative order: by current index, then name for duplicates
+	names := make([]string, 0, len(indexes))
+	for name := range indexes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if indexes[names[i]] != indexes[names[j]] {
+			return indexes[names[i]] < indexes[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	var changed []string
+	next := 1
+	for _, name := range names {
+		for slices.Contains(cfg.Index.Reserved, next) {
+			next++
+		}
+		if cfg.Index.Max > 0 && next > cfg.Index.Max {
+			return fmt.Errorf("not enough indexes: %d worktrees don't fit in 1-%d with %d reserved", len(names), cfg.Index.Max, len(cfg.Index.Reserved))
+		}
+
+		old := indexes[name]
+		idx := next
+		next++
+		if old == idx {
+			continue
+		}
+		cmd.Printf("  %s: %d -> %d\n", name, old, idx)
+		changed = append(changed, name)
+		if indexCompactDryRun {
+			continue
+		}
+		if err := meta.SetIndex(name, idx); err != nil {
+			return fmt.Errorf("failed to set index of %q: %w", name, err)
+		}
+	}
+
+	switch {
+	case len(changed) == 0:
+		cmd.Println("Indexes are already compact")
+	case indexCompactDryRun:
+		cmd.Printf("Would renumber %d worktree(s) (dry run)\n", len(changed))
+	default:
+		cmd.Printf("Renumbered %d worktree(s)\n", len(changed))
+		cmd.Println("Hooks that wrote index-based configuration may need re-running, e.g. `wt hook run post_create <name>`")
+	}
+	return nil
+}
+
+// managedWorktreeNames returns the names of the worktrees in the configured worktree directory
+func managedWorktreeNames(repoRoot string, cfg *config.Config) ([]string, error) {
+	worktrees, err := managedWorktrees(repoRoot, cfg)
+	if err != nil {
+		return nil, err
+	}
+	worktreesDir := cfg.WorktreesDir(repoRoot)
+	var names []string
+	for _, wt := range worktrees {
+		names = append(names, git.GetWorktreeName(worktreesDir, wt.Path))
+	}
+	return names, nil
+}
+
+// joinInts formats integers as a comma-separated list, e.g. "3, 7"
+func joinInts(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/agarcher/wt/internal/git"
+)
+
+func TestIndexCommands(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+index:
+  max: 5
+  reserved: [2]
+`)
+
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		if _, _, err := executeCommand("create", name); err != nil {
+			t.Fatalf("create %s failed: %v", name, err)
+		}
+		defer func(name string) { _, _, _ = executeCommand("delete", name, "--force") }(name)
+	}
+
+	// Reserved index 2 is skipped at allocation
+	assertIndex := func(name string, want int) {
+		t.Helper()
+		got, err := git.OpenMetadata(repoRoot).Index(name)
+		if err != nil || got != want {
+			t.Errorf("expected %s to have index %d, got %d (%v)", name, want, got, err)
+		}
+	}
+	assertIndex("alpha", 1)
+	assertIndex("beta", 3)
+	assertIndex("gamma", 4)
+
+	stdout, _, err := executeCommand("index", "list")
+	if err != nil {
+		t.Fatalf("index list failed: %v", err)
+	}
+	want := "    1  alpha\n    3  beta\n    4  gamma\n\nReserved: 2\nMax:      5\n"
+	if stdout != want {
+		t.Errorf("unexpected index list output:\ngot:\n%s\nwant:\n%s", stdout, want)
+	}
+
+	// Collisions, reserved and max are rejected
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"index", "set", "alpha", "3"}, `already used by worktree "beta"`},
+		{[]string{"index", "set", "alpha", "2"}, "reserved"},
+		{[]string{"index", "set", "alpha", "6"}, "exceeds index.max"},
+		{[]string{"index", "set", "alpha", "0"}, "must be a positive integer"},
+		{[]string{"index", "set", "missing", "5"}, "does not exist"},
+	} {
+		if _, _, err := executeCommand(tt.args...); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+
+	stdout, _, err = executeCommand("index", "set", "alpha", "5")
+	if err != nil {
+		t.Fatalf("index set failed: %v", err)
+	}
+	if !strings.Contains(stdout, `Changed index of "alpha" from 1 to 5`) {
+		t.Errorf("unexpected index set output: %s", stdout)
+	}
+	assertIndex("alpha", 5)
+
+	stdout, _, err = executeCommand("index", "release", "beta")
+	if err != nil {
+		t.Fatalf("index release failed: %v", err)
+	}
+	if !strings.Contains(stdout, `Released index 3 from "beta"`) {
+		t.Errorf("unexpected index release output: %s", stdout)
+	}
+	if _, err := git.OpenMetadata(repoRoot).Index("beta"); err == nil {
+		t.Error("expected beta to have no index after release")
+	}
+	if _, _, err := executeCommand("index", "release", "beta"); err == nil || !strings.Contains(err.Error(), "has no index") {
+		t.Errorf("expected error releasing twice, got %v", err)
+	}
+
+	// Compact: gamma 4 -> 1, alpha 5 -> 3 (2 is reserved), beta stays unindexed
+	stdout, _, err = executeCommand("index", "compact", "--dry-run")
+	if err != nil {
+		t.Fatalf("index compact --dry-run failed: %v", err)
+	}
+	if !strings.Contains(stdout, "gamma: 4 -> 1") || !strings.Contains(stdout, "alpha: 5 -> 3") {
+		t.Errorf("unexpected compact plan: %s", stdout)
+	}
+	assertIndex("gamma", 4)
+
+	if _, _, err := executeCommand("index", "compact"); err != nil {
+		t.Fatalf("index compact failed: %v", err)
+	}
+	assertIndex("gamma", 1)
+	assertIndex("alpha", 3)
+
+	stdout, _, _ = executeCommand("index", "list")
+	if !strings.Contains(stdout, "    -  beta   (no index)\n") {
+		t.Errorf("expected unindexed worktree to be listed, got:\n%s", stdout)
+	}
+}
+
+func TestIndexListShowsConflicts(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	for _, name := range []string{"one", "two"} {
+		if _, _, err := executeCommand("create", name); err != nil {
+			t.Fatalf("create %s failed: %v", name, err)
+		}
+		defer func(name string) { _, _, _ = executeCommand("delete", name, "--force") }(name)
+	}
+
+	// Config changed after the worktrees were created, and an index edited by hand
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+index:
+  max: 1
+  reserved: [1]
+`)
+	if err := git.OpenMetadata(repoRoot).SetIndex("two", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := executeCommand("index", "list")
+	if err != nil {
+		t.Fatalf("index list failed: %v", err)
+	}
+	for _, want := range []string{
+		`    1  one  (reserved, also used by "two")`,
+		`    1  two  (reserved, also used by "one")`,
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout)
+		}
+	}
+}
@@ -6,12 +6,16 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/agarcher/wt/internal/config"
 	"github.com/agarcher/wt/internal/git"
 	"github.com/agarcher/wt/internal/hooks"
 	"github.com/spf13/cobra"
 )
 
+var infoJSON bool
+
 func init() {
+	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "Output as JSON, including info hook fields")
 	rootCmd.AddCommand(infoCmd)
 }
 
@@ -28,7 +32,9 @@ Shows:
 - Index number (if assigned)
 - Creation date and age
 - Status (commits ahead/behind, dirty state, merge status)
-- Custom info from info hooks (if configured)`,
+- Custom info from info hooks (if configured)
+
+Use --json for machine-readable output.`,
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: completeWorktreeNames,
 	RunE:              runInfo,
@@ -42,37 +48,9 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	}
 
 	// Determine which worktree to show info for
-	var name string
-	var worktreePath string
-
-	if len(args) > 0 {
-		name = args[0]
-		worktreePath = filepath.Join(setup.RepoRoot, setup.Config.WorktreeDir, name)
-	} else {
-		// Auto-detect from current directory
-		cwd, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
-		}
-
-		worktreesDir := filepath.Join(setup.RepoRoot, setup.Config.WorktreeDir)
-		if !strings.HasPrefix(cwd, worktreesDir) {
-			return fmt.Errorf("not in a worktree (specify name or cd into a worktree)")
-		}
-
-		// Extract worktree name from path
-		rel, err := filepath.Rel(worktreesDir, cwd)
-		if err != nil {
-			return fmt.Errorf("failed to determine worktree: %w", err)
-		}
-		parts := strings.Split(rel, string(filepath.Separator))
-		name = parts[0]
-		worktreePath = filepath.Join(worktreesDir, name)
-	}
-
-	// Check if worktree exists
-	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
-		return fmt.Errorf("worktree %q does not exist", name)
+	name, worktreePath, err := resolveWorktreeArg(setup.RepoRoot, setup.Config, args)
+	if err != nil {
+		return err
 	}
 
 	// Get worktree details
@@ -85,7 +63,7 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	status, _ := git.GetWorktreeStatus(setup.RepoRoot, worktreePath, name, branch, setup.ComparisonRef, mergedCache)
 
 	// Get worktree index
-	idx, _ := git.GetWorktreeIndex(setup.RepoRoot, name)
+	idx, _ := git.OpenMetadata(setup.RepoRoot).Index(name)
 
 	// Determine current marker
 	cwd, _ := os.Getwd()
@@ -94,22 +72,40 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		currentMarker = "* "
 	}
 
+	env := &hooks.Env{
+		Name:        name,
+		Path:        worktreePath,
+		Branch:      branch,
+		RepoRoot:    setup.RepoRoot,
+		WorktreeDir: setup.Config.WorktreeDir,
+		Index:       idx,
+	}
+	setHookStatus(env, setup.ComparisonRef, status)
+	env = hooks.WithPorts(env, setup.Config.Resources)
+
+	// Report merges as they're first seen, if the repository asks for it
+	if setup.Config.MergeDetection == config.MergeDetectionStatus {
+		notifyMerged(cmd, setup.Config, env, status)
+	}
+
 	// Run info hooks to get custom output
-	hookOutput := ""
-	if len(setup.Config.Hooks.Info) > 0 {
-		env := &hooks.Env{
-			Name:        name,
-			Path:        worktreePath,
-			Branch:      branch,
-			RepoRoot:    setup.RepoRoot,
-			WorktreeDir: setup.Config.WorktreeDir,
-			Index:       idx,
-		}
-		hookOutput, _ = hooks.RunInfo(setup.Config, env)
+	hookInfo := runInfoHooks(setup.Config, env)
+
+	out := cmd.OutOrStdout()
+	if infoJSON {
+		return writeJSON(out, newWorktreeJSON(worktreeInfo{
+			name:          name,
+			branch:        branch,
+			path:          worktreePath,
+			currentMarker: currentMarker,
+			status:        status,
+			index:         idx,
+			hookEnv:       env,
+			hookInfo:      hookInfo,
+		}))
 	}
 
 	// Print output
-	out := cmd.OutOrStdout()
 	separator := strings.Repeat("=", 80)
 
 	_, _ = fmt.Fprintln(out, separator)
@@ -120,7 +116,9 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		CreatedAt:     status.CreatedAt,
 		Status:        status,
 		CurrentMarker: currentMarker,
-		HookOutput:    hookOutput,
+		Ports:         env.Ports,
+		HookOutput:    hookInfo.Text,
+		HookFields:    hookInfo.Fields,
 	})
 	_, _ = fmt.Fprintln(out, separator)
 
@@ -16,7 +16,7 @@ var initCmd = &cobra.Command{
 	Short: "Generate shell integration script",
 	Long: `Generate shell integration script for the specified shell.
 
-Supported shells: zsh, bash, fish
+Supported shells: zsh, bash, fish, powershell (or pwsh), nu (or nushell), elvish
 
 Add the following to your shell configuration file:
 
@@ -27,8 +27,22 @@ Add the following to your shell configuration file:
     eval "$(wt init bash)"
 
   For fish (~/.config/fish/config.fish):
-    wt init fish | source`,
-	ValidArgs: []string{"zsh", "bash", "fish"},
+    wt init fish | source
+
+  For PowerShell ($PROFILE):
+    Invoke-Expression (& wt init powershell | Out-String)
+
+  For Nushell, save the script once (and after upgrading wt):
+    wt init nu | save -f ~/.config/nushell/wt.nu
+  then add to config.nu:
+    source ~/.config/nushell/wt.nu
+
+  For Elvish (~/.config/elvish/rc.elv):
+    eval (wt init elvish | slurp)
+
+The environment autoloading (wt env) and wt_prompt_info helpers are
+only defined for zsh, bash and fish.`,
+	ValidArgs: []string{"zsh", "bash", "fish", "powershell", "pwsh", "nu", "nushell", "elvish"},
 	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		shellName := args[0]
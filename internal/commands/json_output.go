@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/agarcher/wt/internal/provision"
+	"github.com/spf13/cobra"
+)
+
+// worktreeJSON is the --json representation of a worktree
+type worktreeJSON struct {
+	Name      string                        `json:"name"`
+	Branch    string                        `json:"branch"`
+	Path      string                        `json:"path"`
+	Index     int                           `json:"index,omitempty"`
+	Current   bool                          `json:"current"`
+	CreatedAt *time.Time                    `json:"created_at,omitempty"`
+	Status    *hooks.Status                 `json:"status,omitempty"`
+	MergedPRs []string                      `json:"merged_prs,omitempty"`
+	Ports     map[string]provision.PortJSON `json:"ports,omitempty"`
+	Hook      map[string]hookFieldJSON      `json:"hook,omitempty"`
+	HookText  string                        `json:"hook_text,omitempty"`
+}
+
+// hookFieldJSON is a field reported by a JSON info hook
+type hookFieldJSON struct {
+	Value any    `json:"value"`
+	Type  string `json:"type,omitempty"`
+	Label string `json:"label"`
+}
+
+// newWorktreeJSON builds the --json representation of a worktree, including info hook output
+func newWorktreeJSON(wt worktreeInfo) worktreeJSON {
+	j := worktreeJSON{
+		Name:    wt.name,
+		Branch:  wt.branch,
+		Path:    wt.path,
+		Index:   wt.index,
+		Current: wt.currentMarker == "* ",
+	}
+	if wt.status != nil {
+		if !wt.status.CreatedAt.IsZero() {
+			createdAt := wt.status.CreatedAt
+			j.CreatedAt = &createdAt
+		}
+		j.MergedPRs = wt.status.MergedPRs
+	}
+	if wt.hookEnv != nil {
+		j.Status = wt.hookEnv.Status
+		j.Ports = provision.PortsJSON(wt.hookEnv.Ports)
+	}
+	if wt.hookInfo != nil {
+		j.HookText = wt.hookInfo.Text
+		for _, f := range wt.hookInfo.Fields {
+			if j.Hook == nil {
+				j.Hook = map[string]hookFieldJSON{}
+			}
+			j.Hook[f.Key] = hookFieldJSON{Value: f.Value, Type: f.Type, Label: f.Label}
+		}
+	}
+	return j
+}
+
+// writeJSON writes v to out as indented JSON
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// jsonOutput reports whether the command was asked for --json output
+func jsonOutput(cmd *cobra.Command) bool {
+	flag := cmd.Flags().Lookup("json")
+	return flag != nil && flag.Value.String() == "true"
+}
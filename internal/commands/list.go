@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/agarcher/wt/internal/config"
 	"github.com/agarcher/wt/internal/git"
@@ -12,10 +13,17 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var verboseFlag bool
+var (
+	verboseFlag bool
+	listJSON    bool
+	listColumns string
+)
 
 func init() {
 	listCmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Show detailed status for each worktree")
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "Output worktrees as JSON, including info hook fields")
+	listCmd.Flags().StringVar(&listColumns, "columns", defaultListColumns, "Comma-separated columns for compact output (name, index, branch, status, path, created, hook.<field>)")
+	_ = listCmd.RegisterFlagCompletionFunc("columns", completeListColumns)
 	rootCmd.AddCommand(listCmd)
 }
 
@@ -35,7 +43,14 @@ Shows each worktree with:
   - merged: branch has been merged to main
   - dirty: has uncommitted changes (bold, additive)
 
-Use -v/--verbose for detailed multi-line output including worktree age.`,
+Use -v/--verbose for detailed multi-line output including worktree age.
+
+Use --columns to choose the compact table's columns. Fields reported by
+JSON info hooks can be shown with hook.<field>, e.g.:
+
+  wt list --columns name,branch,hook.port
+
+Use --json for machine-readable output.`,
 	RunE: runList,
 }
 
@@ -47,19 +62,27 @@ type worktreeInfo struct {
 	currentMarker string
 	status        *git.WorktreeStatus
 	index         int
+	hookEnv       *hooks.Env
+	hookInfo      *hooks.InfoOutput // Info hook output; nil unless hooks were run
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	// Validate columns before doing any work
+	columns, needsHooks, err := parseListColumns(listColumns)
+	if err != nil {
+		return err
+	}
+
 	// Setup comparison context (prints repo root, fetches if configured, prints comparison ref)
 	setup, err := SetupCompare(cmd)
 	if err != nil {
 		return err
 	}
 
-	// Get all worktrees
-	worktrees, err := git.ListWorktrees(setup.RepoRoot)
+	// Get the managed worktrees
+	worktrees, err := managedWorktrees(setup.RepoRoot, setup.Config)
 	if err != nil {
-		return fmt.Errorf("failed to list worktrees: %w", err)
+		return err
 	}
 
 	// Get merged branches cache for efficiency
@@ -67,30 +90,37 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	// Get current directory to highlight current worktree
 	cwd, _ := os.Getwd()
-	worktreesDir := filepath.Join(setup.RepoRoot, setup.Config.WorktreeDir)
+	worktreesDir := setup.Config.WorktreesDir(setup.RepoRoot)
 
 	// Collect managed worktrees (excluding main repo)
 	var managedWorktrees []worktreeInfo
 
 	for _, wt := range worktrees {
-		// Skip the main worktree
-		if wt.Path == setup.RepoRoot {
-			continue
-		}
-
-		// Check if this worktree is in our managed directory
-		if !strings.HasPrefix(wt.Path, worktreesDir) {
-			continue
-		}
-
 		// Get worktree name
-		name := git.GetWorktreeName(setup.RepoRoot, wt.Path, setup.Config.WorktreeDir)
+		name := git.GetWorktreeName(worktreesDir, wt.Path)
 
 		// Get full worktree status
 		status, _ := git.GetWorktreeStatus(setup.RepoRoot, wt.Path, name, wt.Branch, setup.ComparisonRef, mergedCache)
 
 		// Get worktree index
-		idx, _ := git.GetWorktreeIndex(setup.RepoRoot, name)
+		idx, _ := git.OpenMetadata(setup.RepoRoot).Index(name)
+
+		// Hook environment for info hooks
+		env := &hooks.Env{
+			Name:        name,
+			Path:        wt.Path,
+			Branch:      wt.Branch,
+			RepoRoot:    setup.RepoRoot,
+			WorktreeDir: setup.Config.WorktreeDir,
+			Index:       idx,
+		}
+		setHookStatus(env, setup.ComparisonRef, status)
+		env = hooks.WithPorts(env, setup.Config.Resources)
+
+		// Report merges as they're first seen, if the repository asks for it
+		if setup.Config.MergeDetection == config.MergeDetectionStatus {
+			notifyMerged(cmd, setup.Config, env, status)
+		}
 
 		// Check if this is the current worktree (exact match or inside it)
 		currentMarker := "  "
@@ -105,9 +135,23 @@ func runList(cmd *cobra.Command, args []string) error {
 			currentMarker: currentMarker,
 			status:        status,
 			index:         idx,
+			hookEnv:       env,
 		})
 	}
 
+	// Run info hooks only when their output is shown
+	if listJSON || verboseFlag || needsHooks {
+		runAllInfoHooks(setup.Config, managedWorktrees)
+	}
+
+	if listJSON {
+		result := make([]worktreeJSON, 0, len(managedWorktrees))
+		for _, wt := range managedWorktrees {
+			result = append(result, newWorktreeJSON(wt))
+		}
+		return writeJSON(cmd.OutOrStdout(), result)
+	}
+
 	// If no worktrees, display message and return
 	if len(managedWorktrees) == 0 {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No worktrees")
@@ -116,63 +160,48 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	// Print based on verbose flag
 	if verboseFlag {
-		printVerboseWorktrees(cmd, managedWorktrees, setup.Config, setup.RepoRoot)
+		printVerboseWorktrees(cmd, managedWorktrees)
 	} else {
-		printCompactWorktrees(cmd, managedWorktrees)
+		printWorktreeTable(cmd.OutOrStdout(), managedWorktrees, columns)
 	}
 
 	return nil
 }
 
-
-// printCompactWorktrees prints worktrees in compact table format
-func printCompactWorktrees(cmd *cobra.Command, worktrees []worktreeInfo) {
-	out := cmd.OutOrStdout()
-
-	// Calculate column widths based on content
-	nameWidth := len("NAME")
-	branchWidth := len("BRANCH")
-	for _, wt := range worktrees {
-		if len(wt.name) > nameWidth {
-			nameWidth = len(wt.name)
-		}
-		if len(wt.branch) > branchWidth {
-			branchWidth = len(wt.branch)
-		}
+// maxParallelInfoHooks limits how many worktrees run their info hooks at once
+const maxParallelInfoHooks = 8
+
+// runAllInfoHooks runs info hooks for all worktrees in parallel, storing the output on each
+func runAllInfoHooks(cfg *config.Config, worktrees []worktreeInfo) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxParallelInfoHooks)
+	for i := range worktrees {
+		wg.Add(1)
+		go func(wt *worktreeInfo) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			wt.hookInfo = runInfoHooks(cfg, wt.hookEnv)
+		}(&worktrees[i])
 	}
+	wg.Wait()
+}
 
-	// Print header and rows with dynamic widths
-	_, _ = fmt.Fprintf(out, "  %-*s  %5s  %-*s  %s\n", nameWidth, "NAME", "INDEX", branchWidth, "BRANCH", "STATUS")
-	for _, wt := range worktrees {
-		statusStr := FormatCompactStatus(wt.status)
-		indexStr := "-"
-		if wt.index > 0 {
-			indexStr = fmt.Sprintf("%d", wt.index)
-		}
-		_, _ = fmt.Fprintf(out, "%s%-*s  %5s  %-*s  %s\n", wt.currentMarker, nameWidth, wt.name, indexStr, branchWidth, wt.branch, statusStr)
+// runInfoHooks runs a worktree's info hooks. Failing hooks are silently left out.
+func runInfoHooks(cfg *config.Config, env *hooks.Env) *hooks.InfoOutput {
+	output, _ := hooks.RunInfo(cfg, env)
+	if output == nil {
+		return &hooks.InfoOutput{}
 	}
+	return output
 }
 
 // printVerboseWorktrees prints worktrees in detailed multi-line format
-func printVerboseWorktrees(cmd *cobra.Command, worktrees []worktreeInfo, cfg *config.Config, repoRoot string) {
+func printVerboseWorktrees(cmd *cobra.Command, worktrees []worktreeInfo) {
 	out := cmd.OutOrStdout()
 	separator := strings.Repeat("=", 80)
 
 	for _, wt := range worktrees {
-		// Run info hooks to get custom output
-		hookOutput := ""
-		if len(cfg.Hooks.Info) > 0 {
-			env := &hooks.Env{
-				Name:        wt.name,
-				Path:        wt.path,
-				Branch:      wt.branch,
-				RepoRoot:    repoRoot,
-				WorktreeDir: cfg.WorktreeDir,
-				Index:       wt.index,
-			}
-			hookOutput, _ = hooks.RunInfo(cfg, env)
-		}
-
 		_, _ = fmt.Fprintln(out, separator)
 
 		// Build VerboseInfo, guarding against nil status
@@ -181,7 +210,9 @@ func printVerboseWorktrees(cmd *cobra.Command, worktrees []worktreeInfo, cfg *co
 			Branch:        wt.branch,
 			Index:         wt.index,
 			CurrentMarker: wt.currentMarker,
-			HookOutput:    hookOutput,
+			Ports:         wt.hookEnv.Ports,
+			HookOutput:    wt.hookInfo.Text,
+			HookFields:    wt.hookInfo.Fields,
 		}
 		if wt.status != nil {
 			info.CreatedAt = wt.status.CreatedAt
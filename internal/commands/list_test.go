@@ -1,10 +1,14 @@
 package commands
 
 import (
+	"encoding/json"
+	"os"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/agarcher/wt/internal/git"
+	"github.com/spf13/cobra"
 )
 
 func TestFormatCompactStatus(t *testing.T) {
@@ -249,3 +253,127 @@ func containsBold(s, substr string) bool {
 	boldSubstr := bold + substr + reset
 	return strings.Contains(s, boldSubstr)
 }
+
+func TestParseListColumns(t *testing.T) {
+	tests := []struct {
+		spec       string
+		headers    []string
+		needsHooks bool
+		wantErr    bool
+	}{
+		{spec: defaultListColumns, headers: []string{"NAME", "INDEX", "BRANCH", "STATUS"}},
+		{spec: "name, Path,created", headers: []string{"NAME", "PATH", "CREATED"}},
+		{spec: "name,hook.port", headers: []string{"NAME", "PORT"}, needsHooks: true},
+		{spec: "name,bogus", wantErr: true},
+		{spec: "hook.", wantErr: true},
+		{spec: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			columns, needsHooks, err := parseListColumns(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var headers []string
+			for _, c := range columns {
+				headers = append(headers, c.header)
+			}
+			if strings.Join(headers, ",") != strings.Join(tt.headers, ",") {
+				t.Errorf("expected headers %v, got %v", tt.headers, headers)
+			}
+			if needsHooks != tt.needsHooks {
+				t.Errorf("expected needsHooks=%v, got %v", tt.needsHooks, needsHooks)
+			}
+		})
+	}
+}
+
+func TestListJSONHookColumns(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeHookScript(t, repoRoot, "ports.sh", `echo '{"port": {"value": '$((5173 + WT_INDEX * 10))', "type": "port", "label": "Port"}}'`+"\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+hooks:
+  info:
+    - script: ports.sh
+      format: json
+`)
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "json-wt"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "json-wt", "--force") }()
+
+	stdout, _, err := executeCommand("list", "--columns", "name,hook.port")
+	if err != nil {
+		t.Fatalf("list command failed: %v", err)
+	}
+	if !strings.Contains(stdout, "NAME     PORT") || !strings.Contains(stdout, "json-wt  5183") {
+		t.Errorf("expected hook column in list output, got:\n%s", stdout)
+	}
+
+	// JSON output has no header lines on stdout and carries typed hook fields
+	stdout, stderr, err := executeCommand("list", "--json")
+	if err != nil {
+		t.Fatalf("list --json failed: %v", err)
+	}
+	if !strings.Contains(stderr, "Repository:") {
+		t.Errorf("expected repository header on stderr, got %q", stderr)
+	}
+	var result []struct {
+		Name   string `json:"name"`
+		Index  int    `json:"index"`
+		Status struct {
+			New bool `json:"new"`
+		} `json:"status"`
+		Hook map[string]struct {
+			Value int    `json:"value"`
+			Type  string `json:"type"`
+		} `json:"hook"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("list --json output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if len(result) != 1 || result[0].Name != "json-wt" || result[0].Index != 1 || !result[0].Status.New {
+		t.Fatalf("unexpected list --json output: %s", stdout)
+	}
+	if port := result[0].Hook["port"]; port.Value != 5183 || port.Type != "port" {
+		t.Errorf("expected typed port field, got %+v", port)
+	}
+
+	stdout, _, err = executeCommand("info", "json-wt", "--json")
+	if err != nil {
+		t.Fatalf("info --json failed: %v", err)
+	}
+	if !strings.Contains(stdout, `"name": "json-wt"`) || !strings.Contains(stdout, `"value": 5183`) {
+		t.Errorf("unexpected info --json output: %s", stdout)
+	}
+}
+
+func TestCompleteListColumns(t *testing.T) {
+	completions, directive := completeListColumns(&cobra.Command{}, nil, "name,b")
+	if len(completions) != 1 || completions[0] != "name,branch" {
+		t.Errorf("completions = %v, want [name,branch]", completions)
+	}
+	if directive&cobra.ShellCompDirectiveNoSpace == 0 {
+		t.Error("expected NoSpace so more columns can follow")
+	}
+
+	completions, _ = completeListColumns(&cobra.Command{}, nil, "")
+	if !slices.Contains(completions, "hook.") || !slices.Contains(completions, "status") {
+		t.Errorf("expected all columns and hook. for an empty value, got %v", completions)
+	}
+}
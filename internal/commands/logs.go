@@ -0,0 +1,200 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsHook   string
+	logsFollow bool
+	logsList   bool
+)
+
+// logsFollowInterval is how often --follow polls the log file for new output
+const logsFollowInterval = 250 * time.Millisecond
+
+// logsFollowIdleTimeout is how long --follow waits for output from a hook whose
+// process can't be checked before giving up
+const logsFollowIdleTimeout = time.Minute
+
+func init() {
+	logsCmd.Flags().StringVar(&logsHook, "hook", "", "Only show logs for this hook event (e.g. post_create)")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep printing output until the hook finishes or its wt process is gone")
+	logsCmd.Flags().BoolVarP(&logsList, "list", "l", false, "List all retained logs instead of showing the latest")
+	_ = logsCmd.RegisterFlagCompletionFunc("hook", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return hooks.Events(), cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.AddCommand(logsCmd)
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [name]",
+	Short: "Show hook execution logs for a worktree",
+	Long: `Show the output of hooks that ran for a worktree.
+
+Every lifecycle hook run for a worktree has its stdout and stderr saved,
+along with its exit code and duration: under .git/worktrees/<name>/wt-logs/
+while the worktree exists, and under .git/wt-logs/<name>/ for hooks that
+run before it is created or after it is removed (pre_create, post_delete).
+
+If no name is provided and you're currently inside a worktree,
+logs for that worktree will be shown. A deleted worktree's name shows
+its remaining logs, such as post_delete.
+
+By default the most recent log is printed. Use --hook to restrict to a
+single event, --list to see all retained logs, and --follow to keep
+printing output from a hook that is still running.
+
+Retention is bounded by logs.max_files and logs.max_bytes in .wt.yaml.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktreeNames,
+	RunE:              runLogs,
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	// Find the main repository root
+	repoRoot, err := config.GetMainRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if logsHook != "" && !isHookEvent(logsHook) {
+		return fmt.Errorf("unknown hook event: %s\nValid events: %s", logsHook, strings.Join(hooks.Events(), ", "))
+	}
+
+	// A worktree that no longer exists may still have logs from its removal
+	name, _, resolveErr := resolveWorktreeArg(repoRoot, cfg, args)
+	if resolveErr != nil {
+		if len(args) == 0 {
+			return resolveErr
+		}
+		name = args[0]
+	}
+
+	entries, err := hooks.ListWorktreeLogs(repoRoot, name, logsHook)
+	if err != nil {
+		return fmt.Errorf("failed to read hook logs: %w", err)
+	}
+	if len(entries) == 0 && resolveErr != nil {
+		return resolveErr
+	}
+	if len(entries) == 0 {
+		if logsHook != "" {
+			return fmt.Errorf("no %s hook logs for worktree %q", logsHook, name)
+		}
+		return fmt.Errorf("no hook logs for worktree %q", name)
+	}
+
+	if logsList {
+		printLogList(cmd, entries)
+		return nil
+	}
+
+	latest := entries[len(entries)-1]
+	if logsFollow {
+		return followLog(cmd, latest.Path)
+	}
+
+	data, err := os.ReadFile(latest.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+	_, _ = cmd.OutOrStdout().Write(data)
+	return nil
+}
+
+// printLogList prints retained hook logs as a table, oldest first
+func printLogList(cmd *cobra.Command, entries []hooks.LogEntry) {
+	out := cmd.OutOrStdout()
+
+	// Calculate column widths based on content
+	eventWidth := len("EVENT")
+	scriptWidth := len("SCRIPT")
+	for _, e := range entries {
+		if len(e.Event) > eventWidth {
+			eventWidth = len(e.Event)
+		}
+		if len(e.Script) > scriptWidth {
+			scriptWidth = len(e.Script)
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "%-19s  %-*s  %-*s  %7s  %s\n", "STARTED", eventWidth, "EVENT", scriptWidth, "SCRIPT", "EXIT", "DURATION")
+	for _, e := range entries {
+		exitStr := "running"
+		durationStr := "-"
+		if stopped, _ := e.Stopped(); stopped {
+			exitStr = "stopped"
+		}
+		if e.Complete {
+			exitStr = fmt.Sprintf("%d", e.ExitCode)
+			durationStr = e.Duration.String()
+		}
+		_, _ = fmt.Fprintf(out, "%-19s  %-*s  %-*s  %7s  %s\n",
+			e.Started.Local().Format("2006-01-02 15:04:05"), eventWidth, e.Event, scriptWidth, e.Script, exitStr, durationStr)
+	}
+}
+
+// followLog prints a log file and keeps printing appended output until the hook
+// finishes. It also stops if the wt process running the hook is gone, as the log
+// will never be finished, or, where that can't be checked, once the log has stopped
+// growing for logsFollowIdleTimeout.
+func followLog(cmd *cobra.Command, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	out := cmd.OutOrStdout()
+	lastOutput := time.Now()
+	for {
+		n, err := io.Copy(out, f)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			lastOutput = time.Now()
+		}
+		entry, err := hooks.ParseLog(path)
+		if err != nil {
+			return err
+		}
+		stopped, known := entry.Stopped()
+		if entry.Complete || stopped || (!known && time.Since(lastOutput) > logsFollowIdleTimeout) {
+			// Drain anything written between the last copy and the footer check
+			if _, err := io.Copy(out, f); err != nil {
+				return err
+			}
+			if !entry.Complete {
+				cmd.PrintErrf("Warning: the hook stopped without finishing its log\n")
+			}
+			return nil
+		}
+		time.Sleep(logsFollowInterval)
+	}
+}
+
+// isHookEvent checks whether name is a known hook event
+func isHookEvent(name string) bool {
+	for _, e := range hooks.Events() {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agarcher/wt/internal/hooks"
+)
+
+// setupTestRepoWithPostCreateHook creates a test repo whose post_create hook prints a marker
+func setupTestRepoWithPostCreateHook(t *testing.T, hookBody string) (string, func()) {
+	t.Helper()
+
+	repoRoot, cleanup := setupTestRepo(t)
+
+	if err := os.WriteFile(filepath.Join(repoRoot, "post-create.sh"), []byte("#!/bin/bash\n"+hookBody), 0755); err != nil {
+		cleanup()
+		t.Fatalf("failed to write hook: %v", err)
+	}
+
+	wtConfig := `version: 1
+worktree_dir: worktrees
+hooks:
+  post_create:
+    - script: post-create.sh
+`
+	if err := os.WriteFile(filepath.Join(repoRoot, ".wt.yaml"), []byte(wtConfig), 0644); err != nil {
+		cleanup()
+		t.Fatalf("failed to write .wt.yaml: %v", err)
+	}
+
+	return repoRoot, cleanup
+}
+
+func TestLogsShowsLatestHookOutput(t *testing.T) {
+	repoRoot, cleanup := setupTestRepoWithPostCreateHook(t, "echo \"setting up $WT_NAME\"\n")
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "logged-wt"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "logged-wt", "--force") }()
+
+	stdout, _, err := executeCommand("logs", "logged-wt")
+	if err != nil {
+		t.Fatalf("logs command failed: %v", err)
+	}
+	if !strings.Contains(stdout, "setting up logged-wt") {
+		t.Errorf("expected hook output in logs, got: %s", stdout)
+	}
+	if !strings.Contains(stdout, "# wt exit: 0") {
+		t.Errorf("expected exit code in logs, got: %s", stdout)
+	}
+
+	// --list shows a table entry for the run
+	stdout, _, err = executeCommand("logs", "logged-wt", "--list")
+	if err != nil {
+		t.Fatalf("logs --list failed: %v", err)
+	}
+	if !strings.Contains(stdout, "post_create") || !strings.Contains(stdout, "EXIT") {
+		t.Errorf("expected post_create row in list, got: %s", stdout)
+	}
+
+	// --follow returns once the hook has finished
+	stdout, _, err = executeCommand("logs", "logged-wt", "--hook", "post_create", "--follow")
+	if err != nil {
+		t.Fatalf("logs --follow failed: %v", err)
+	}
+	if !strings.Contains(stdout, "setting up logged-wt") {
+		t.Errorf("expected hook output when following, got: %s", stdout)
+	}
+}
+
+func TestLogsFollowStopsWhenHookIsGone(t *testing.T) {
+	repoRoot, cleanup := setupTestRepoWithPostCreateHook(t, "echo hello\n")
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "stopped-wt"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "stopped-wt", "--force") }()
+
+	// A log left unfinished by a wt process that has since exited
+	gone := exec.Command("true")
+	if err := gone.Run(); err != nil {
+		t.Fatal(err)
+	}
+	content := fmt.Sprintf("# wt event: pre_delete\n# wt script: /tmp/check.sh\n# wt started: 2099-01-02T03:04:05Z\n# wt pid: %d\nchecking...\n", gone.Process.Pid)
+	logPath := filepath.Join(hooks.LogDir(repoRoot, "stopped-wt"), "20990102T030405.000-pre_delete-check.sh.log")
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	var stdout, stderr string
+	var err error
+	go func() {
+		stdout, stderr, err = executeCommand("logs", "stopped-wt", "--follow")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("logs --follow kept waiting for a hook whose process is gone")
+	}
+	if err != nil {
+		t.Fatalf("logs --follow failed: %v", err)
+	}
+	if !strings.Contains(stdout, "checking...") || !strings.Contains(stdout+stderr, "stopped without finishing") {
+		t.Errorf("expected the partial log and a warning, got stdout %q, stderr %q", stdout, stderr)
+	}
+
+	stdout, _, _ = executeCommand("logs", "stopped-wt", "--list")
+	if !strings.Contains(stdout, "stopped") {
+		t.Errorf("expected the unfinished log to be listed as stopped, got: %s", stdout)
+	}
+}
+
+func TestLogsFromInsideWorktree(t *testing.T) {
+	repoRoot, cleanup := setupTestRepoWithPostCreateHook(t, "echo hello\n")
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "inside-wt"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(repoRoot)
+		_, _, _ = executeCommand("delete", "inside-wt", "--force")
+	}()
+
+	_ = os.Chdir(filepath.Join(repoRoot, "worktrees", "inside-wt"))
+	stdout, _, err := executeCommand("logs")
+	if err != nil {
+		t.Fatalf("logs command failed: %v", err)
+	}
+	if !strings.Contains(stdout, "hello") {
+		t.Errorf("expected hook output, got: %s", stdout)
+	}
+}
+
+func TestLogsForCreateAndDeleteHooks(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	writeHookScript(t, repoRoot, "pre-create.sh", "echo \"checking $WT_NAME\"\n")
+	writeHookScript(t, repoRoot, "post-delete.sh", "echo \"tearing down $WT_NAME\"\n")
+	writeWtConfig(t, repoRoot, `version: 1
+worktree_dir: worktrees
+hooks:
+  pre_create:
+    - script: pre-create.sh
+  post_delete:
+    - script: post-delete.sh
+`)
+
+	if _, _, err := executeCommand("create", "short-lived"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	stdout, _, err := executeCommand("logs", "short-lived", "--hook", "pre_create")
+	if err != nil {
+		t.Fatalf("logs for pre_create failed: %v", err)
+	}
+	if !strings.Contains(stdout, "checking short-lived") {
+		t.Errorf("expected pre_create output in logs, got: %s", stdout)
+	}
+
+	// The worktree is gone, but its logs from outside it remain
+	if _, _, err := executeCommand("delete", "short-lived", "--force"); err != nil {
+		t.Fatalf("delete command failed: %v", err)
+	}
+	stdout, _, err = executeCommand("logs", "short-lived")
+	if err != nil {
+		t.Fatalf("logs for a deleted worktree failed: %v", err)
+	}
+	if !strings.Contains(stdout, "tearing down short-lived") || !strings.Contains(stdout, "# wt exit: 0") {
+		t.Errorf("expected the post_delete log as the latest, got: %s", stdout)
+	}
+	stdout, _, err = executeCommand("logs", "short-lived", "--list")
+	if err != nil {
+		t.Fatalf("logs --list failed: %v", err)
+	}
+	if !strings.Contains(stdout, "pre_create") || !strings.Contains(stdout, "post_delete") {
+		t.Errorf("expected pre_create and post_delete rows, got: %s", stdout)
+	}
+}
+
+func TestLogsErrors(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "quiet-wt"); err != nil {
+		t.Fatalf("create command failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "quiet-wt", "--force") }()
+
+	// No hooks configured, so there are no logs
+	if _, _, err := executeCommand("logs", "quiet-wt"); err == nil {
+		t.Error("expected error when no logs exist")
+	}
+
+	if _, _, err := executeCommand("logs", "quiet-wt", "--hook", "post_creat"); err == nil {
+		t.Error("expected error for unknown hook event")
+	}
+
+	if _, _, err := executeCommand("logs", "missing-wt"); err == nil {
+		t.Error("expected error for nonexistent worktree")
+	}
+}
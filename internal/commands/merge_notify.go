@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+// notifyMerged runs post_merge hooks the first time a worktree is seen merged at its
+// current commit. Cleanup and delete call it before removing the worktree; list and
+// info only with merge_detection: status, which reports the merge while the worktree
+// is still around. The commit is recorded in the worktree's metadata, so a later
+// command, or a removal that fails, doesn't re-fire the hooks.
+func notifyMerged(cmd *cobra.Command, cfg *config.Config, env *hooks.Env, status *git.WorktreeStatus) {
+	if len(cfg.Hooks.PostMerge) == 0 || status == nil {
+		return
+	}
+
+	if !isMergedStatus(status) {
+		return
+	}
+
+	head, err := git.GetCurrentCommit(env.Path)
+	if err != nil {
+		return
+	}
+	meta := git.OpenMetadata(env.RepoRoot)
+	if notified, _ := meta.MergeNotified(env.Name); notified == head {
+		return
+	}
+
+	if err := hooks.RunPostMerge(cfg, env); err != nil {
+		cmd.PrintErrf("Warning: post-merge hook failed for %s: %v\n", env.Name, err)
+	}
+	if err := meta.SetMergeNotified(env.Name, head); err != nil {
+		cmd.PrintErrf("Warning: could not record merge notification for %s: %v\n", env.Name, err)
+	}
+}
@@ -0,0 +1,433 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// previewPlaceholder is shown while a preview renders in the background
+const previewPlaceholder = "Loading…"
+
+// pickerItem is an entry in the interactive picker
+type pickerItem struct {
+	Label  string // Shown in the list
+	Filter string // Text the query is matched against
+}
+
+// picker is a fuzzy finder: a query line, the matching items and a preview of the
+// highlighted one. It holds no terminal state, so it can be driven by tests.
+type picker struct {
+	items   []pickerItem
+	preview func(i int) string // Preview text for items[i]; may be nil
+
+	query   []rune
+	matches []int // Indexes into items, best match first
+	cursor  int   // Position in matches
+}
+
+// newPicker creates a picker with an initial query
+func newPicker(items []pickerItem, query string, preview func(i int) string) *picker {
+	p := &picker{items: items, preview: preview, query: []rune(query)}
+	p.filter()
+	return p
+}
+
+// filter recomputes the matches for the current query, keeping the item order among equal scores
+func (p *picker) filter() {
+	type match struct{ item, score int }
+	var matches []match
+	for i, item := range p.items {
+		if score, ok := fuzzyScore(string(p.query), item.Filter); ok {
+			matches = append(matches, match{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool { return matches[a].score > matches[b].score })
+
+	p.matches = p.matches[:0]
+	for _, m := range matches {
+		p.matches = append(p.matches, m.item)
+	}
+	p.cursor = 0
+}
+
+// selected returns the highlighted item, or -1 if nothing matches
+func (p *picker) selected() int {
+	if len(p.matches) == 0 {
+		return -1
+	}
+	return p.matches[p.cursor]
+}
+
+// pickerKey is a key press understood by the picker
+type pickerKey struct {
+	action keyAction
+	r      rune // For keyRune
+}
+
+type keyAction int
+
+const (
+	keyRune keyAction = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyCancel
+	keyBackspace
+	keyClearQuery
+)
+
+// handle applies a key press. It returns done when the picker should close, with
+// the chosen item or -1 if it was cancelled.
+func (p *picker) handle(k pickerKey) (done bool, chosen int) {
+	switch k.action {
+	case keyRune:
+		p.query = append(p.query, k.r)
+		p.filter()
+	case keyBackspace:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.filter()
+		}
+	case keyClearQuery:
+		p.query = p.query[:0]
+		p.filter()
+	case keyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case keyDown:
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+	case keyEnter:
+		if sel := p.selected(); sel >= 0 {
+			return true, sel
+		}
+	case keyCancel:
+		return true, -1
+	}
+	return false, -1
+}
+
+// parseKeys decodes the bytes of one read from a terminal in non-canonical mode
+func parseKeys(b []byte) []pickerKey {
+	var keys []pickerKey
+	for len(b) > 0 {
+		// Escape sequences: arrow keys are used, others (Home, Delete, F-keys...) dropped
+		if len(b) >= 2 && b[0] == 0x1b && (b[1] == '[' || b[1] == 'O') {
+			final, n := escapeSequence(b)
+			switch final {
+			case 'A':
+				keys = append(keys, pickerKey{action: keyUp})
+			case 'B':
+				keys = append(keys, pickerKey{action: keyDown})
+			}
+			b = b[n:]
+			continue
+		}
+
+		switch b[0] {
+		case 0x1b, 0x03, 0x07: // Esc, Ctrl-C, Ctrl-G
+			return append(keys, pickerKey{action: keyCancel})
+		case '\r', '\n':
+			keys = append(keys, pickerKey{action: keyEnter})
+		case 0x7f, 0x08: // Backspace, Ctrl-H
+			keys = append(keys, pickerKey{action: keyBackspace})
+		case 0x15: // Ctrl-U
+			keys = append(keys, pickerKey{action: keyClearQuery})
+		case 0x10, 0x0b: // Ctrl-P, Ctrl-K
+			keys = append(keys, pickerKey{action: keyUp})
+		case 0x0e: // Ctrl-N
+			keys = append(keys, pickerKey{action: keyDown})
+		default:
+			r, size := utf8.DecodeRune(b)
+			if unicode.IsPrint(r) {
+				keys = append(keys, pickerKey{action: keyRune, r: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// escapeSequence reads the escape sequence at the start of b, which begins ESC [ or
+// ESC O, and returns its final byte (0 if it's cut off) and length. A CSI sequence
+// (ESC [) has parameter and intermediate bytes before a final byte in 0x40-0x7E, e.g.
+// ESC [ 1 ; 5 A for Ctrl-Up or ESC [ 3 ~ for Delete; an SS3 one (ESC O) is one byte more.
+func escapeSequence(b []byte) (final byte, n int) {
+	if b[1] == 'O' {
+		if len(b) < 3 {
+			return 0, len(b)
+		}
+		return b[2], 3
+	}
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return b[i], i + 1
+		}
+		if b[i] < 0x20 || b[i] > 0x3f {
+			// Not a parameter or intermediate byte: the sequence is malformed
+			return 0, i
+		}
+	}
+	return 0, len(b)
+}
+
+// asyncPreviews renders previews in the background, each once, so a slow one (info
+// hooks run for each) doesn't hold up typing. Until it's ready, a placeholder is shown.
+type asyncPreviews struct {
+	render func(i int) string
+
+	mu    sync.Mutex
+	texts map[int]string
+	busy  map[int]bool
+	ready chan struct{} // Signalled when a preview finishes, to redraw
+}
+
+// newAsyncPreviews creates previews rendered by render
+func newAsyncPreviews(render func(i int) string) *asyncPreviews {
+	return &asyncPreviews{render: render, texts: map[int]string{}, busy: map[int]bool{}, ready: make(chan struct{}, 1)}
+}
+
+// get returns the preview of item i, or the placeholder while it renders
+func (a *asyncPreviews) get(i int) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if text, ok := a.texts[i]; ok {
+		return text
+	}
+	if !a.busy[i] {
+		a.busy[i] = true
+		go func() {
+			text := a.render(i)
+			a.mu.Lock()
+			a.texts[i] = text
+			a.mu.Unlock()
+			select {
+			case a.ready <- struct{}{}:
+			default:
+			}
+		}()
+	}
+	return previewPlaceholder
+}
+
+// render draws the picker to fit a terminal of the given size
+func (p *picker) render(out io.Writer, width, height int) {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J") // Home, clear screen
+
+	// Query line with match count
+	count := fmt.Sprintf("  %d/%d", len(p.matches), len(p.items))
+	b.WriteString(truncate("> "+string(p.query), width-len(count)) + count + "\r\n")
+
+	// The list takes up to half the screen; the preview gets the rest
+	listHeight := min(len(p.items), max(height/2-1, 1))
+	start := max(p.cursor-listHeight+1, 0)
+	for row := 0; row < listHeight; row++ {
+		i := start + row
+		if i >= len(p.matches) {
+			b.WriteString("\r\n")
+			continue
+		}
+		line := truncate(p.items[p.matches[i]].Label, width-2)
+		if i == p.cursor {
+			b.WriteString("\x1b[7m> " + line + "\x1b[0m\r\n")
+		} else {
+			b.WriteString("  " + line + "\r\n")
+		}
+	}
+
+	b.WriteString(strings.Repeat("─", max(width, 1)) + "\r\n")
+	if sel := p.selected(); sel >= 0 && p.preview != nil {
+		lines := strings.Split(strings.TrimRight(p.preview(sel), "\n"), "\n")
+		for i, line := range lines {
+			if i >= height-listHeight-2 {
+				break
+			}
+			b.WriteString(truncate(ansiPattern.ReplaceAllString(line, ""), width) + "\r\n")
+		}
+	}
+
+	// Leave the cursor at the end of the query
+	fmt.Fprintf(&b, "\x1b[1;%dH", min(3+len(p.query), width))
+	_, _ = io.WriteString(out, b.String())
+}
+
+// truncate shortens s to at most width runes
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	if width == 1 {
+		return string(r[:1])
+	}
+	return string(r[:width-1]) + "…"
+}
+
+// fuzzyScore reports whether the characters of pattern appear in order in s (case
+// insensitive), scoring substrings, contiguous runs and matches at word starts higher
+func fuzzyScore(pattern, s string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	lp, ls := strings.ToLower(pattern), strings.ToLower(s)
+	pr, sr := []rune(lp), []rune(ls)
+
+	score, pi, prev := 0, 0, -2
+	for si := 0; si < len(sr) && pi < len(pr); si++ {
+		if sr[si] != pr[pi] {
+			continue
+		}
+		score++
+		if si == prev+1 {
+			score += 5 // Contiguous
+		}
+		if si == 0 || isWordBoundary(sr[si-1]) {
+			score += 3 // Start of a word, e.g. after "-" or "/"
+		}
+		prev = si
+		pi++
+	}
+	if pi < len(pr) {
+		return 0, false
+	}
+
+	// The greedy scan above can miss a later exact substring, which ranks above all else
+	if idx := strings.Index(ls, lp); idx >= 0 {
+		score += 50
+		if r, _ := utf8.DecodeLastRuneInString(ls[:idx]); idx == 0 || isWordBoundary(r) {
+			score += 25
+		}
+	}
+	return score, true
+}
+
+// isWordBoundary reports whether r separates words in a name, like "-", "_" or "/"
+func isWordBoundary(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// runPicker shows the picker on the terminal tty until an item is chosen, returning
+// -1 if it was cancelled. The picker is also redrawn on each signal from updates
+// (e.g. a preview becoming ready) and when the terminal is resized. The terminal is
+// restored before returning.
+func runPicker(tty *os.File, out io.Writer, p *picker, updates <-chan struct{}) (int, error) {
+	restore, err := makeRaw(tty)
+	if err != nil {
+		return -1, err
+	}
+	defer restore()
+
+	// Draw on the alternate screen so the picker leaves no trace
+	_, _ = io.WriteString(out, "\x1b[?1049h")
+	defer func() { _, _ = io.WriteString(out, "\x1b[?1049l") }()
+
+	// Keys are read here while other redraws happen in the background, so the
+	// picker's state is shared under mu. The size is only queried again on resize.
+	var mu sync.Mutex
+	closed := false
+	width, height := terminalSize(tty)
+	draw := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !closed {
+			p.render(out, width, height)
+		}
+	}
+	defer func() {
+		mu.Lock()
+		closed = true
+		mu.Unlock()
+	}()
+
+	resize := make(chan os.Signal, 1)
+	notifyResize(resize)
+	defer signal.Stop(resize)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-updates:
+			case <-resize:
+				w, h := terminalSize(tty)
+				mu.Lock()
+				width, height = w, h
+				mu.Unlock()
+			}
+			draw()
+		}
+	}()
+
+	buf := make([]byte, 256)
+	for {
+		draw()
+
+		n, err := tty.Read(buf)
+		if err != nil {
+			return -1, err
+		}
+		mu.Lock()
+		for _, k := range parseKeys(buf[:n]) {
+			if done, chosen := p.handle(k); done {
+				mu.Unlock()
+				return chosen, nil
+			}
+		}
+		mu.Unlock()
+	}
+}
+
+// makeRaw puts the terminal into non-canonical mode without echo or signals, using
+// stty to avoid platform-specific ioctls, and returns a function that restores it
+func makeRaw(tty *os.File) (func(), error) {
+	state, err := stty(tty, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terminal state: %w", err)
+	}
+	if _, err := stty(tty, "-icanon", "-echo", "-isig", "min", "1", "time", "0"); err != nil {
+		return nil, fmt.Errorf("failed to configure terminal: %w", err)
+	}
+	return func() { _, _ = stty(tty, strings.TrimSpace(state)) }, nil
+}
+
+// terminalSize returns the terminal's width and height, defaulting to 80x24
+func terminalSize(tty *os.File) (width, height int) {
+	out, err := stty(tty, "size")
+	if err == nil {
+		if fields := strings.Fields(out); len(fields) == 2 {
+			height, _ = strconv.Atoi(fields[0])
+			width, _ = strconv.Atoi(fields[1])
+		}
+	}
+	if width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// stty runs stty against the terminal
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package commands
+
+import "os"
+
+// notifyResize does nothing where terminals don't signal resizes
+func notifyResize(c chan<- os.Signal) {}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		match   bool
+	}{
+		{"", "anything", true},
+		{"auth", "feature-auth", true},
+		{"fa", "feature-auth", true},
+		{"FA", "feature-auth", true},
+		{"af", "feature-auth", false},
+		{"xyz", "feature-auth", false},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.pattern, tt.s); ok != tt.match {
+			t.Errorf("fuzzyScore(%q, %q) match = %v, want %v", tt.pattern, tt.s, ok, tt.match)
+		}
+	}
+
+	// Contiguous and word-start matches rank higher than scattered ones
+	contiguous, _ := fuzzyScore("auth", "feature-auth")
+	scattered, _ := fuzzyScore("auth", "a-unit-test-h")
+	if contiguous <= scattered {
+		t.Errorf("expected contiguous score %d > scattered score %d", contiguous, scattered)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("ab\x1b[A\x1b[B\x7f\x15\r"))
+	want := []pickerKey{
+		{action: keyRune, r: 'a'},
+		{action: keyRune, r: 'b'},
+		{action: keyUp},
+		{action: keyDown},
+		{action: keyBackspace},
+		{action: keyClearQuery},
+		{action: keyEnter},
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("expected %d keys, got %d: %v", len(want), len(keys), keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("key %d = %v, want %v", i, keys[i], want[i])
+		}
+	}
+
+	if keys := parseKeys([]byte("\x1b")); len(keys) != 1 || keys[0].action != keyCancel {
+		t.Errorf("expected a lone Esc to cancel, got %v", keys)
+	}
+	if keys := parseKeys([]byte("é")); len(keys) != 1 || keys[0].r != 'é' {
+		t.Errorf("expected a multi-byte rune, got %v", keys)
+	}
+
+	// Longer sequences are read up to their final byte: modified arrows are arrows,
+	// and keys the picker doesn't use are dropped rather than typed or cancelling
+	tests := []struct {
+		input string
+		want  []pickerKey
+	}{
+		{"\x1b[1;5A", []pickerKey{{action: keyUp}}},
+		{"\x1bOB", []pickerKey{{action: keyDown}}},
+		{"\x1b[3~x", []pickerKey{{action: keyRune, r: 'x'}}},
+		{"\x1b[Hx\x1b[F", []pickerKey{{action: keyRune, r: 'x'}}},
+		{"\x1b[15~\x1bOP", nil},
+		{"\x1b[1;2", nil},
+	}
+	for _, tt := range tests {
+		if keys := parseKeys([]byte(tt.input)); !reflect.DeepEqual(keys, tt.want) {
+			t.Errorf("parseKeys(%q) = %v, want %v", tt.input, keys, tt.want)
+		}
+	}
+}
+
+func TestPicker(t *testing.T) {
+	items := []pickerItem{
+		{Label: "repo", Filter: "repo main"},
+		{Label: "feature-auth", Filter: "feature-auth feature-auth"},
+		{Label: "bugfix", Filter: "bugfix fix/login"},
+	}
+	previewed := map[int]int{}
+	p := newPicker(items, "", func(i int) string {
+		previewed[i]++
+		return "preview of " + items[i].Label
+	})
+
+	if len(p.matches) != 3 || p.selected() != 0 {
+		t.Fatalf("expected all items with the first selected, got %v (%d)", p.matches, p.selected())
+	}
+
+	// Typing filters; matching the branch works too
+	for _, r := range "login" {
+		p.handle(pickerKey{action: keyRune, r: r})
+	}
+	if len(p.matches) != 1 || p.selected() != 2 {
+		t.Errorf("expected only bugfix to match, got %v", p.matches)
+	}
+
+	var out bytes.Buffer
+	p.render(&out, 40, 12)
+	for _, s := range []string{"> login", "1/3", "bugfix", "preview of bugfix"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q in render output: %q", s, out.String())
+		}
+	}
+
+	// Clearing the query and moving down selects the second item
+	p.handle(pickerKey{action: keyClearQuery})
+	p.handle(pickerKey{action: keyDown})
+	p.handle(pickerKey{action: keyDown})
+	p.handle(pickerKey{action: keyDown})
+	p.handle(pickerKey{action: keyUp})
+	if done, chosen := p.handle(pickerKey{action: keyEnter}); !done || chosen != 1 {
+		t.Errorf("expected to choose item 1, got done=%v chosen=%d", done, chosen)
+	}
+
+	// Enter does nothing without matches; Esc cancels
+	for _, r := range "zzz" {
+		p.handle(pickerKey{action: keyRune, r: r})
+	}
+	if done, _ := p.handle(pickerKey{action: keyEnter}); done {
+		t.Error("expected Enter with no matches to keep the picker open")
+	}
+	if done, chosen := p.handle(pickerKey{action: keyCancel}); !done || chosen != -1 {
+		t.Errorf("expected cancel, got done=%v chosen=%d", done, chosen)
+	}
+}
+
+func TestAsyncPreviews(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	previews := newAsyncPreviews(func(i int) string {
+		calls.Add(1)
+		<-release
+		return fmt.Sprintf("preview %d", i)
+	})
+
+	// A slow preview doesn't block: the placeholder is shown until it's ready
+	if got := previews.get(1); got != previewPlaceholder {
+		t.Errorf("expected the placeholder, got %q", got)
+	}
+	if got := previews.get(1); got != previewPlaceholder {
+		t.Errorf("expected the placeholder, got %q", got)
+	}
+	close(release)
+	<-previews.ready
+	if got := previews.get(1); got != "preview 1" {
+		t.Errorf("expected the rendered preview, got %q", got)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected the preview to render once, got %d", n)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("feature-auth", 8); got != "feature…" {
+		t.Errorf("truncate() = %q", got)
+	}
+	if got := truncate("short", 8); got != "short" {
+		t.Errorf("truncate() = %q", got)
+	}
+	if got := truncate("short", 0); got != "" {
+		t.Errorf("truncate() = %q", got)
+	}
+}
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+// defaultPromptFormat renders e.g. "⎇ agent-3 [#3 ↑2 in_progress, dirty]"
+const defaultPromptFormat = "⎇ {name} {info}"
+
+// defaultPromptTimeout keeps the prompt responsive in large repositories
+const defaultPromptTimeout = 200 * time.Millisecond
+
+var (
+	promptFormat  string
+	promptTimeout time.Duration
+)
+
+func init() {
+	promptCmd.Flags().StringVar(&promptFormat, "format", defaultPromptFormat, "Prompt format with {name}, {branch}, {index}, {status} and {info} placeholders")
+	promptCmd.Flags().DurationVar(&promptTimeout, "timeout", defaultPromptTimeout, "Time budget for the whole prompt, including finding the worktree; the status is left out if exceeded")
+	rootCmd.AddCommand(promptCmd)
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a prompt segment for the current worktree",
+	Long: `Print a short description of the current worktree for shell prompts,
+e.g. "⎇ agent-3 [#3 ↑2 in_progress, dirty]". Prints nothing outside a worktree.
+
+It is built for speed: no fetch or network access, a few local git commands,
+and a time budget (--timeout) for all of it. The status is left out if the
+budget runs out, and nothing is printed if the worktree isn't known by then.
+The status uses the same indicators as wt list, compared against the local
+comparison ref (the configured remote's copy is used if already fetched).
+
+Placeholders for --format:
+  {name}    Worktree name
+  {branch}  Branch name
+  {index}   Worktree index
+  {status}  Status, e.g. "↑2 in_progress, dirty"
+  {info}    Index and status in brackets, e.g. "[#3 ↑2 in_progress, dirty]"
+
+wt init defines a wt_prompt_info shell function that runs this command.`,
+	Args: cobra.NoArgs,
+	RunE: runPrompt,
+}
+
+// promptResult is the status gathered for a prompt within its time budget
+type promptResult struct {
+	status *git.WorktreeStatus
+	branch string
+}
+
+// promptWorktree identifies the worktree a prompt is for
+type promptWorktree struct {
+	name  string
+	index int
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	// The budget covers finding the repository too, which runs git
+	ctx, cancel := context.WithTimeout(context.Background(), promptTimeout)
+	defer cancel()
+
+	found := make(chan *promptWorktree, 1)
+	done := make(chan promptResult, 1)
+	go func() {
+		// Prompts must never fail: anything that isn't a worktree prints nothing
+		repoRoot, err := config.GetMainRepoRoot()
+		if err != nil {
+			found <- nil
+			return
+		}
+		cfg, err := config.Load(repoRoot)
+		if err != nil {
+			found <- nil
+			return
+		}
+		name, worktreePath, err := resolveWorktreeArg(repoRoot, cfg, nil)
+		if err != nil {
+			found <- nil
+			return
+		}
+		index, _ := git.OpenMetadata(repoRoot).Index(name)
+		found <- &promptWorktree{name: name, index: index}
+
+		ref := promptComparisonRef(repoRoot, cfg)
+		status, branch, _ := git.GetPromptStatus(ctx, repoRoot, worktreePath, name, ref)
+		done <- promptResult{status: status, branch: branch}
+	}()
+
+	var worktree *promptWorktree
+	select {
+	case worktree = <-found:
+	case <-ctx.Done():
+	}
+	if worktree == nil {
+		return nil
+	}
+
+	// Over budget: show what's known without the status
+	var result promptResult
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+	}
+
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), formatPrompt(promptFormat, worktree.name, worktree.index, result))
+	return nil
+}
+
+// promptComparisonRef resolves the comparison ref like the other commands, but never fetches
+func promptComparisonRef(repoRoot string, cfg *config.Config) string {
+	branch := comparisonBranch(repoRoot, cfg)
+	userCfg, _ := userconfig.Load()
+	if remote := userCfg.GetRemoteForRepo(repoRoot); remote != "" && git.RefExists(repoRoot, remote+"/"+branch) {
+		return remote + "/" + branch
+	}
+	return branch
+}
+
+// formatPrompt expands the prompt format's placeholders
+func formatPrompt(format, name string, index int, result promptResult) string {
+	var status string
+	if result.status != nil {
+		parts := statusCounts(result.status)
+		if tags := statusTags(result.status, false); len(tags) > 0 {
+			parts = append(parts, strings.Join(tags, ", "))
+		}
+		status = strings.Join(parts, " ")
+	}
+
+	var indexStr string
+	var infoParts []string
+	if index > 0 {
+		indexStr = strconv.Itoa(index)
+		infoParts = append(infoParts, "#"+indexStr)
+	}
+	if status != "" {
+		infoParts = append(infoParts, status)
+	}
+	var info string
+	if len(infoParts) > 0 {
+		info = "[" + strings.Join(infoParts, " ") + "]"
+	}
+
+	return strings.TrimSpace(strings.NewReplacer(
+		"{name}", name,
+		"{branch}", result.branch,
+		"{index}", indexStr,
+		"{status}", status,
+		"{info}", info,
+	).Replace(format))
+}
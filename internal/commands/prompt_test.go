@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agarcher/wt/internal/git"
+)
+
+func TestFormatPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		index  int
+		result promptResult
+		want   string
+	}{
+		{
+			name:   "index and status",
+			format: defaultPromptFormat,
+			index:  3,
+			result: promptResult{status: &git.WorktreeStatus{CommitsAhead: 2, HasUncommittedChanges: true}, branch: "agent-3"},
+			want:   "⎇ agent-3 [#3 ↑2 in_progress, dirty]",
+		},
+		{
+			name:   "new worktree",
+			format: defaultPromptFormat,
+			index:  1,
+			result: promptResult{status: &git.WorktreeStatus{IsNew: true}},
+			want:   "⎇ agent-3 [#1 new]",
+		},
+		{
+			name:   "status over budget",
+			format: defaultPromptFormat,
+			index:  3,
+			want:   "⎇ agent-3 [#3]",
+		},
+		{
+			name:   "no index or status",
+			format: defaultPromptFormat,
+			want:   "⎇ agent-3",
+		},
+		{
+			name:   "custom format",
+			format: "{name}@{branch}:{index} {status}",
+			index:  2,
+			result: promptResult{status: &git.WorktreeStatus{CommitsBehind: 4, IsMerged: true}, branch: "feature/x"},
+			want:   "agent-3@feature/x:2 ↓4 merged",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatPrompt(tt.format, "agent-3", tt.index, tt.result); got != tt.want {
+				t.Errorf("formatPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptCommand(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	if _, _, err := executeCommand("create", "agent-3"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	defer func() { _, _, _ = executeCommand("delete", "agent-3", "--force") }()
+
+	// Nothing outside a worktree
+	stdout, _, err := executeCommand("prompt")
+	if err != nil || stdout != "" {
+		t.Errorf("expected no output in the main repo, got %q (%v)", stdout, err)
+	}
+
+	worktreePath := filepath.Join(repoRoot, "worktrees", "agent-3")
+	if err := os.WriteFile(filepath.Join(worktreePath, "dirty.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Chdir(worktreePath)
+
+	// A generous budget so a loaded machine doesn't drop the status
+	stdout, _, err = executeCommand("prompt", "--timeout", "5s")
+	if err != nil {
+		t.Fatalf("prompt failed: %v", err)
+	}
+	if want := "⎇ agent-3 [#1 new, dirty]\n"; stdout != want {
+		t.Errorf("expected %q, got %q", want, stdout)
+	}
+
+	stdout, _, err = executeCommand("prompt", "--format", "{branch}", "--timeout", "5s")
+	if err != nil || stdout != "agent-3\n" {
+		t.Errorf("expected branch from --format, got %q (%v)", stdout, err)
+	}
+
+	// The budget covers finding the worktree: with none left, nothing is printed
+	stdout, _, err = executeCommand("prompt", "--timeout", "1ns")
+	if err != nil || stdout != "" {
+		t.Errorf("expected no output when the budget runs out during discovery, got %q (%v)", stdout, err)
+	}
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+)
+
+// resolveWorktreeArg determines the worktree name and path from an optional name
+// argument, falling back to the worktree containing the current directory.
+func resolveWorktreeArg(repoRoot string, cfg *config.Config, args []string) (name, worktreePath string, err error) {
+	if len(args) > 0 {
+		name = args[0]
+		worktreePath = filepath.Join(cfg.WorktreesDir(repoRoot), name)
+	} else {
+		// Auto-detect from current directory
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+
+		worktreesDir := cfg.WorktreesDir(repoRoot)
+		if !git.IsInsideWorktree(worktreesDir, cwd) {
+			return "", "", fmt.Errorf("not in a worktree (specify name or cd into a worktree)")
+		}
+
+		// Extract worktree name from path
+		name = git.GetWorktreeName(worktreesDir, cwd)
+		worktreePath = filepath.Join(worktreesDir, name)
+	}
+
+	// Check if worktree exists; with nested names, worktreePath may be a directory
+	// that only groups worktrees, e.g. "feature" for "feature/foo"
+	if !git.IsWorktreeCheckout(worktreePath) {
+		return "", "", fmt.Errorf("worktree %q does not exist", name)
+	}
+
+	return name, worktreePath, nil
+}
+
+// managedWorktrees returns the worktrees in the configured worktree directory. The
+// main checkout isn't one, except in a bare repository, whose root is its default
+// worktree: there every checkout is listed.
+func managedWorktrees(repoRoot string, cfg *config.Config) ([]git.Worktree, error) {
+	worktrees, err := git.ListWorktrees(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	bare := git.IsBareRepository(repoRoot)
+	worktreesDir := cfg.WorktreesDir(repoRoot)
+	var managed []git.Worktree
+	for _, wt := range worktrees {
+		if wt.Bare || (wt.Path == repoRoot && !bare) || !git.IsInsideWorktree(worktreesDir, wt.Path) {
+			continue
+		}
+		managed = append(managed, wt)
+	}
+	return managed, nil
+}
@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agarcher/wt/examples"
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	setupWorktreeDir   string
+	setupDefaultBranch string
+	setupHooks         bool
+	setupYes           bool
+	setupForce         bool
+)
+
+// exampleHooks are the example scripts `wt setup --hooks` copies into scripts/,
+// with the event each is configured for
+var exampleHooks = []struct {
+	script string
+	event  string
+}{
+	{"setup-ports.sh", hooks.EventPostCreate},
+	{"pre-delete-check.sh", hooks.EventPreDelete},
+	{"show-info.sh", hooks.EventInfo},
+}
+
+func init() {
+	setupCmd.Flags().StringVar(&setupWorktreeDir, "worktree-dir", "", "Directory for worktrees (default: worktrees, or .. in a bare repository)")
+	setupCmd.Flags().StringVar(&setupDefaultBranch, "default-branch", "", "Branch to compare against (default: detected from the repository)")
+	setupCmd.Flags().BoolVar(&setupHooks, "hooks", false, "Copy example hook scripts into scripts/ and configure them")
+	setupCmd.Flags().BoolVarP(&setupYes, "yes", "y", false, "Accept the defaults without prompting")
+	setupCmd.Flags().BoolVarP(&setupForce, "force", "f", false, "Overwrite an existing .wt.yaml")
+	rootCmd.AddCommand(setupCmd)
+}
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Set up the repository for wt",
+	Long: `Generate .wt.yaml in the repository root with the default settings and
+the repository's default branch, and add the worktree directory and
+.wt.local.yaml to .gitignore.
+
+In a terminal, setup asks for each setting not given by a flag. Use --yes
+to accept the defaults without prompting.
+
+Use --hooks to copy the example hook scripts into scripts/ and configure
+them: a post_create hook that assigns ports, a pre_delete hook that checks
+for unsaved work and an info hook.`,
+	Args: cobra.NoArgs,
+	RunE: runSetup,
+}
+
+// setupOptions are the choices `wt setup` makes for a new .wt.yaml
+type setupOptions struct {
+	worktreeDir   string
+	defaultBranch string
+	exampleHooks  bool
+}
+
+func runSetup(cmd *cobra.Command, args []string) error {
+	repoRoot, err := config.GetMainRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+	// Linked worktrees of a separate git dir can only find the main checkout this way
+	if recorded, err := config.RecordMainWorktree(); err != nil {
+		cmd.Printf("Warning: could not record the main checkout: %v\n", err)
+	} else if recorded {
+		cmd.Printf("Recorded %s as the main checkout\n", repoRoot)
+	}
+	if config.Exists(repoRoot) && !setupForce {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", filepath.Join(repoRoot, config.ConfigFileName))
+	}
+
+	opts := defaultSetupOptions(repoRoot)
+	if setupWorktreeDir != "" {
+		opts.worktreeDir = setupWorktreeDir
+	}
+	if setupDefaultBranch != "" {
+		opts.defaultBranch = setupDefaultBranch
+	}
+	opts.exampleHooks = setupHooks
+
+	if !setupYes && isTerminal(os.Stdin) && isTerminal(cmd.ErrOrStderr()) {
+		reader := bufio.NewReader(cmd.InOrStdin())
+		if setupWorktreeDir == "" {
+			opts.worktreeDir = promptSetting(cmd, reader, "Worktree directory", opts.worktreeDir)
+		}
+		if setupDefaultBranch == "" {
+			opts.defaultBranch = promptSetting(cmd, reader, "Default branch", opts.defaultBranch)
+		}
+		if !setupHooks {
+			answer := promptSetting(cmd, reader, "Add example hooks? [y/N]", "")
+			opts.exampleHooks = strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes")
+		}
+	}
+
+	return setupRepo(cmd, repoRoot, opts)
+}
+
+// defaultSetupOptions returns the settings `wt setup` suggests for a repository.
+// A bare repository's worktrees sit next to each other, so they default to "..".
+func defaultSetupOptions(repoRoot string) setupOptions {
+	opts := setupOptions{worktreeDir: config.DefaultConfig().WorktreeDir}
+	if git.IsBareRepository(repoRoot) {
+		opts.worktreeDir = ".."
+	}
+	opts.defaultBranch, _ = git.GetDefaultBranch(repoRoot)
+	return opts
+}
+
+// promptSetting asks for a setting, returning def if the answer is empty
+func promptSetting(cmd *cobra.Command, reader *bufio.Reader, label, def string) string {
+	if def != "" {
+		cmd.Printf("%s [%s]: ", label, def)
+	} else {
+		cmd.Printf("%s: ", label)
+	}
+	line, _ := reader.ReadString('\n')
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// setupRepo writes .wt.yaml for opts, copying the example hooks if asked to, and
+// adds wt's files to .gitignore
+func setupRepo(cmd *cobra.Command, repoRoot string, opts setupOptions) error {
+	cfg := config.DefaultConfig()
+	cfg.WorktreeDir = opts.worktreeDir
+	cfg.DefaultBranch = opts.defaultBranch
+
+	if opts.exampleHooks {
+		if err := copyExampleHooks(cmd, repoRoot, cfg); err != nil {
+			return fmt.Errorf("failed to copy example hooks: %w", err)
+		}
+	}
+
+	data, err := config.Generate(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to generate config: %w", err)
+	}
+	configPath := filepath.Join(repoRoot, config.ConfigFileName)
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	cmd.Printf("Wrote %s\n", configPath)
+
+	// Loading the config checks it and resolves the worktree directory
+	loaded, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	// A bare repository's git dir has no working tree to ignore files in
+	if git.IsGitDir(repoRoot) {
+		return nil
+	}
+	patterns := []string{"/.wt.local.yaml"}
+	if rel, err := filepath.Rel(repoRoot, loaded.WorktreesDir(repoRoot)); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		patterns = append([]string{"/" + filepath.ToSlash(rel) + "/"}, patterns...)
+	}
+	added, err := addIgnorePatterns(filepath.Join(repoRoot, ".gitignore"), patterns)
+	if err != nil {
+		return fmt.Errorf("failed to update .gitignore: %w", err)
+	}
+	if len(added) > 0 {
+		cmd.Printf("Added %s to .gitignore\n", strings.Join(added, ", "))
+	}
+	return nil
+}
+
+// copyExampleHooks copies the example hook scripts into scripts/, keeping any file
+// already there, and configures them in cfg
+func copyExampleHooks(cmd *cobra.Command, repoRoot string, cfg *config.Config) error {
+	scriptsDir := filepath.Join(repoRoot, "scripts")
+	if err := os.MkdirAll(scriptsDir, 0755); err != nil {
+		return err
+	}
+	for _, hook := range exampleHooks {
+		path := filepath.Join(scriptsDir, hook.script)
+		if _, err := os.Stat(path); err == nil {
+			cmd.Printf("Keeping existing %s\n", path)
+		} else {
+			data, err := fs.ReadFile(examples.Hooks, "hooks/"+hook.script)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, data, 0755); err != nil {
+				return err
+			}
+			cmd.Printf("Created %s\n", path)
+		}
+
+		entry := config.HookEntry{Script: "./scripts/" + hook.script}
+		switch hook.event {
+		case hooks.EventPostCreate:
+			cfg.Hooks.PostCreate = append(cfg.Hooks.PostCreate, entry)
+		case hooks.EventPreDelete:
+			cfg.Hooks.PreDelete = append(cfg.Hooks.PreDelete, entry)
+		case hooks.EventInfo:
+			cfg.Hooks.Info = append(cfg.Hooks.Info, entry)
+		}
+	}
+	return nil
+}
+
+// addIgnorePatterns appends the patterns a .gitignore file lacks, returning them
+func addIgnorePatterns(path string, patterns []string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+	var added []string
+	for _, pattern := range patterns {
+		// A pattern without the leading slash or trailing slash ignores the same files here
+		bare := strings.Trim(pattern, "/")
+		if !existing[pattern] && !existing[bare] && !existing[bare+"/"] && !existing["/"+bare] {
+			added = append(added, pattern)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	content += "# wt\n" + strings.Join(added, "\n") + "\n"
+	return added, os.WriteFile(path, []byte(content), 0644)
+}
@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+)
+
+func TestSetupWritesConfig(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	// setupTestRepo writes a .wt.yaml, which setup keeps unless forced
+	if _, _, err := executeCommand("setup"); err == nil {
+		t.Fatal("expected setup to refuse to overwrite .wt.yaml")
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, ".gitignore"), []byte("node_modules"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := executeCommand("setup", "--force", "--hooks", "--worktree-dir", ".worktrees"); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		t.Fatalf("generated config doesn't load: %v", err)
+	}
+	branch, _ := git.GetCurrentBranch(repoRoot)
+	if cfg.DefaultBranch != branch {
+		t.Errorf("expected default_branch %q, got %q", branch, cfg.DefaultBranch)
+	}
+	if cfg.WorktreeDir != ".worktrees" {
+		t.Errorf("expected worktree_dir .worktrees, got %q", cfg.WorktreeDir)
+	}
+	if len(cfg.Hooks.PostCreate) != 1 || len(cfg.Hooks.PreDelete) != 1 || len(cfg.Hooks.Info) != 1 {
+		t.Errorf("expected the example hooks to be configured, got %+v", cfg.Hooks)
+	}
+	info, err := os.Stat(filepath.Join(repoRoot, "scripts", "setup-ports.sh"))
+	if err != nil || info.Mode()&0111 == 0 {
+		t.Errorf("expected an executable example hook, got %v", err)
+	}
+
+	// Ignore rules are appended once
+	if _, _, err := executeCommand("setup", "--force", "--worktree-dir", ".worktrees"); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(repoRoot, ".gitignore"))
+	want := "node_modules\n\n# wt\n/.worktrees/\n/.wt.local.yaml\n"
+	if string(data) != want {
+		t.Errorf("expected .gitignore:\n%s\ngot:\n%s", want, data)
+	}
+
+	if _, _, err := executeCommand("create", "feature"); err != nil {
+		t.Fatalf("create failed after setup: %v", err)
+	}
+}
+
+func TestSetupRecordsSeparateGitDirCheckout(t *testing.T) {
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainPath := filepath.Join(tmp, "main")
+	linked := filepath.Join(tmp, "linked")
+	for _, args := range [][]string{
+		{"init", "-q", "--separate-git-dir", filepath.Join(tmp, "main.git"), mainPath},
+		{"-C", mainPath, "-c", "user.name=Test", "-c", "user.email=test@test.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"-C", mainPath, "worktree", "add", "-q", "-b", "linked", linked},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	// Other commands find the repository without recording anything
+	_ = os.Chdir(mainPath)
+	if _, _, err := executeCommand("root"); err != nil {
+		t.Fatalf("root failed: %v", err)
+	}
+	_ = os.Chdir(linked)
+	if _, _, err := executeCommand("root"); err == nil {
+		t.Fatal("expected the main checkout to be unknown before setup")
+	}
+
+	_ = os.Chdir(mainPath)
+	if _, _, err := executeCommand("setup", "--yes"); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	_ = os.Chdir(linked)
+	stdout, _, err := executeCommand("root")
+	if err != nil || lastLine(stdout) != mainPath {
+		t.Errorf("expected root %q after setup, got %v %q", mainPath, err, stdout)
+	}
+}
+
+func TestCloneStandard(t *testing.T) {
+	upstream, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Chdir(tmp)
+
+	stdout, _, err := executeCommand("clone", "file://"+upstream+"/")
+	if err != nil {
+		t.Fatalf("clone failed: %v", err)
+	}
+	clonePath := filepath.Join(tmp, filepath.Base(upstream))
+	if lastLine(stdout) != clonePath {
+		t.Errorf("expected the clone's path %q, got %q", clonePath, lastLine(stdout))
+	}
+	if !config.Exists(clonePath) {
+		t.Fatal("expected the clone to be set up with .wt.yaml")
+	}
+	data, _ := os.ReadFile(filepath.Join(clonePath, ".gitignore"))
+	if !strings.Contains(string(data), "/worktrees/") {
+		t.Errorf("expected the worktree directory to be ignored, got:\n%s", data)
+	}
+
+	if _, _, err := executeCommand("clone", "file://"+upstream); err == nil {
+		t.Error("expected cloning into an existing directory to fail")
+	}
+}
+
+func TestCloneBare(t *testing.T) {
+	upstream, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Chdir(tmp)
+
+	stdout, _, err := executeCommand("clone", "--bare", "file://"+upstream, "project")
+	if err != nil {
+		t.Fatalf("clone failed: %v", err)
+	}
+	branch, _ := git.GetCurrentBranch(upstream)
+	defaultPath := filepath.Join(tmp, "project", branch)
+	if lastLine(stdout) != defaultPath {
+		t.Errorf("expected the default worktree %q, got %q", defaultPath, lastLine(stdout))
+	}
+	if !git.IsBareRepository(defaultPath) {
+		t.Error("expected a bare repository")
+	}
+	if !git.RefExists(defaultPath, "refs/remotes/origin/"+branch) {
+		t.Error("expected remote-tracking branches in the bare clone")
+	}
+
+	// Worktrees are created next to the default worktree, which wt exit returns to
+	_ = os.Chdir(defaultPath)
+	if _, _, err := executeCommand("create", "feature"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	featurePath := filepath.Join(tmp, "project", "feature")
+	if !git.IsWorktreeCheckout(featurePath) {
+		t.Fatalf("expected a worktree at %s", featurePath)
+	}
+	_ = os.Chdir(featurePath)
+	stdout, _, err = executeCommand("exit")
+	if err != nil || strings.TrimSpace(stdout) != defaultPath {
+		t.Errorf("expected exit to %q, got %v %q", defaultPath, err, strings.TrimSpace(stdout))
+	}
+
+	out, err := exec.Command("git", "-C", filepath.Join(tmp, "project", "repo.git"), "config", "wt.defaultWorktree").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "../"+branch {
+		t.Errorf("expected wt.defaultWorktree ../%s, got %v %q", branch, err, out)
+	}
+}
+
+// lastLine returns the last line of a command's output, where paths are printed
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}
+
+func TestRepoNameFromURL(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/agarcher/wt.git": "wt",
+		"git@github.com:agarcher/wt.git":     "wt",
+		"git@host:wt":                        "wt",
+		"file:///srv/repos/project/":         "project",
+	}
+	for url, want := range tests {
+		if got := repoNameFromURL(url); got != want {
+			t.Errorf("repoNameFromURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
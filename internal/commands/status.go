@@ -7,10 +7,13 @@ import (
 	"github.com/agarcher/wt/internal/git"
 )
 
-// ANSI codes for bold text
+// ANSI codes for text styling
 const (
-	bold  = "\033[1m"
-	reset = "\033[0m"
+	bold   = "\033[1m"
+	reset  = "\033[0m"
+	red    = "\033[31m"
+	green  = "\033[32m"
+	yellow = "\033[33m"
 )
 
 // FormatCompactStatus builds the compact status string with arrows.
@@ -21,38 +24,54 @@ func FormatCompactStatus(status *git.WorktreeStatus) string {
 		return ""
 	}
 
-	var parts []string
+	parts := statusCounts(status)
+	if tags := statusTags(status, true); len(tags) > 0 {
+		parts = append(parts, "["+strings.Join(tags, ", ")+"]")
+	}
+
+	return strings.Join(parts, " ")
+}
 
+// statusCounts returns the commits ahead and behind as arrows, e.g. ["↑2", "↓1"]
+func statusCounts(status *git.WorktreeStatus) []string {
+	var parts []string
 	if status.CommitsAhead > 0 {
 		parts = append(parts, fmt.Sprintf("↑%d", status.CommitsAhead))
 	}
 	if status.CommitsBehind > 0 {
 		parts = append(parts, fmt.Sprintf("↓%d", status.CommitsBehind))
 	}
+	return parts
+}
+
+// statusTags returns the state and dirty tags, optionally with bold emphasis
+func statusTags(status *git.WorktreeStatus, styled bool) []string {
+	emphasize := func(s string) string {
+		if !styled {
+			return s
+		}
+		return bold + s + reset
+	}
 
 	// Build status tags (state is mutually exclusive, dirty is additive)
-	var statusTags []string
+	var tags []string
 
 	// State indicator: new > in_progress > merged (mutually exclusive)
 	if status.IsNew {
-		statusTags = append(statusTags, "new")
+		tags = append(tags, "new")
 	} else if status.CommitsAhead > 0 && !status.IsMerged {
 		// in_progress: has commits ahead that aren't merged
-		statusTags = append(statusTags, bold+"in_progress"+reset)
+		tags = append(tags, emphasize("in_progress"))
 	} else if status.IsMerged && status.CommitsAhead == 0 {
-		statusTags = append(statusTags, FormatMergedStatus(status.MergedPRs))
+		tags = append(tags, FormatMergedStatus(status.MergedPRs))
 	}
 
 	// dirty is additive - can appear with any state
 	if status.HasUncommittedChanges {
-		statusTags = append(statusTags, bold+"dirty"+reset)
+		tags = append(tags, emphasize("dirty"))
 	}
 
-	if len(statusTags) > 0 {
-		parts = append(parts, "["+strings.Join(statusTags, ", ")+"]")
-	}
-
-	return strings.Join(parts, " ")
+	return tags
 }
 
 // FormatMergedStatus returns the merged status string.
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+}
+
+var switchCmd = &cobra.Command{
+	Use:   "switch [query]",
+	Short: "Pick a worktree to change to",
+	Long: `Pick a worktree interactively and change to it.
+
+Lists the worktrees (and the main repository) with their branch and
+status. Type to filter by name or branch, use the arrow keys or
+Ctrl-P/Ctrl-N to move, Enter to switch and Esc to cancel. The
+highlighted worktree is previewed as in wt info, in the background.
+
+An optional query pre-fills the filter. When not run on a terminal,
+a numbered list is shown instead and the choice is read from stdin.
+
+Like wt cd, this requires shell integration to change directory and
+runs any post_switch hooks.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktreeNames,
+	RunE:              runSwitch,
+}
+
+// switchItem is a worktree or the main repository offered by wt switch
+type switchItem struct {
+	name    string
+	branch  string
+	path    string
+	main    bool // The main repository
+	current bool
+	index   int
+	status  *git.WorktreeStatus
+}
+
+func runSwitch(cmd *cobra.Command, args []string) error {
+	repoRoot, cfg, err := loadIndexContext()
+	if err != nil {
+		return err
+	}
+
+	items, err := switchItems(repoRoot, cfg)
+	if err != nil {
+		return err
+	}
+	var query string
+	if len(args) > 0 {
+		query = args[0]
+	}
+
+	var chosen int
+	if isTerminal(os.Stdin) && isTerminal(cmd.ErrOrStderr()) {
+		chosen, err = pickSwitchItem(cmd, repoRoot, cfg, items, query)
+	} else {
+		chosen, err = promptSwitchItem(cmd, items, query)
+	}
+	if err != nil {
+		return err
+	}
+	if chosen < 0 {
+		return nil
+	}
+
+	item := items[chosen]
+	if item.main {
+		switchToMainRepo(cmd, repoRoot, cfg)
+	} else {
+		switchToWorktree(cmd, repoRoot, cfg, item.name, item.path)
+	}
+	return nil
+}
+
+// switchItems lists the main repository and the managed worktrees with their status,
+// compared against the local comparison ref so no fetch is needed
+func switchItems(repoRoot string, cfg *config.Config) ([]switchItem, error) {
+	names, err := managedWorktreeNames(repoRoot, cfg)
+	if err != nil {
+		return nil, err
+	}
+	ref := promptComparisonRef(repoRoot, cfg)
+	mergedCache, _ := git.GetMergedBranches(repoRoot, ref)
+	cwd, _ := os.Getwd()
+
+	var items []switchItem
+	// A bare repository has no main checkout; its default worktree is a managed one
+	if !git.IsBareRepository(repoRoot) {
+		mainBranch, _ := git.GetCurrentBranch(repoRoot)
+		items = append(items, switchItem{name: filepath.Base(repoRoot), branch: mainBranch, path: repoRoot, main: true})
+	}
+	for _, name := range names {
+		path := filepath.Join(cfg.WorktreesDir(repoRoot), name)
+		branch, _ := git.GetCurrentBranch(path)
+		status, _ := git.GetWorktreeStatus(repoRoot, path, name, branch, ref, mergedCache)
+		items = append(items, switchItem{name: name, branch: branch, path: path, index: status.Index, status: status})
+	}
+
+	// The deepest path containing the current directory is the current one
+	current := -1
+	for i, item := range items {
+		if cwd == item.path || strings.HasPrefix(cwd, item.path+string(filepath.Separator)) {
+			current = i
+		}
+	}
+	if current >= 0 {
+		items[current].current = true
+	}
+	return items, nil
+}
+
+// label formats an item for the picker and the numbered list, e.g. "agent-3  agent-3  ↑2 [dirty]"
+func (item switchItem) label(nameWidth, branchWidth int) string {
+	marker := "  "
+	if item.current {
+		marker = "* "
+	}
+	status := "(main repository)"
+	if !item.main {
+		status = ansiPattern.ReplaceAllString(FormatCompactStatus(item.status), "")
+	}
+	line := fmt.Sprintf("%s%-*s  %-*s  %s", marker, nameWidth, item.name, branchWidth, item.branch, status)
+	return strings.TrimRight(line, " ")
+}
+
+// switchLabels formats all items with aligned columns
+func switchLabels(items []switchItem) []string {
+	nameWidth, branchWidth := 0, 0
+	for _, item := range items {
+		nameWidth = max(nameWidth, len(item.name))
+		branchWidth = max(branchWidth, len(item.branch))
+	}
+	labels := make([]string, len(items))
+	for i, item := range items {
+		labels[i] = item.label(nameWidth, branchWidth)
+	}
+	return labels
+}
+
+// pickSwitchItem runs the interactive picker, returning -1 if it was cancelled
+func pickSwitchItem(cmd *cobra.Command, repoRoot string, cfg *config.Config, items []switchItem, query string) (int, error) {
+	labels := switchLabels(items)
+	pickerItems := make([]pickerItem, len(items))
+	for i, item := range items {
+		pickerItems[i] = pickerItem{Label: labels[i], Filter: item.name + " " + item.branch}
+	}
+
+	// Previews run info hooks, so render each one once, when first highlighted, and
+	// in the background
+	previews := newAsyncPreviews(func(i int) string {
+		return switchPreview(repoRoot, cfg, items[i])
+	})
+
+	return runPicker(os.Stdin, cmd.ErrOrStderr(), newPicker(pickerItems, query, previews.get), previews.ready)
+}
+
+// switchPreview renders an item as wt info does
+func switchPreview(repoRoot string, cfg *config.Config, item switchItem) string {
+	if item.main {
+		return fmt.Sprintf("%s\n  Branch: %s\n  Path:   %s\n", item.name, item.branch, item.path)
+	}
+
+	env := &hooks.Env{
+		Name:        item.name,
+		Path:        item.path,
+		Branch:      item.branch,
+		RepoRoot:    repoRoot,
+		WorktreeDir: cfg.WorktreeDir,
+		Index:       item.index,
+	}
+	env = hooks.WithPorts(env, cfg.Resources)
+	hookInfo := runInfoHooks(cfg, env)
+
+	var buf bytes.Buffer
+	PrintVerboseWorktree(&buf, VerboseInfo{
+		Name:       item.name,
+		Branch:     item.branch,
+		Index:      item.index,
+		CreatedAt:  item.status.CreatedAt,
+		Status:     item.status,
+		Ports:      env.Ports,
+		HookOutput: hookInfo.Text,
+		HookFields: hookInfo.Fields,
+	})
+	return buf.String()
+}
+
+// promptSwitchItem shows a numbered list and reads the choice from stdin, for when
+// there's no terminal for the picker. A query narrows the list first.
+func promptSwitchItem(cmd *cobra.Command, items []switchItem, query string) (int, error) {
+	labels := switchLabels(items)
+	var shown []int
+	for i, item := range items {
+		if _, ok := fuzzyScore(query, item.name+" "+item.branch); ok {
+			shown = append(shown, i)
+		}
+	}
+	if len(shown) == 0 {
+		return -1, fmt.Errorf("no worktree matches %q", query)
+	}
+
+	for n, i := range shown {
+		cmd.Printf("%3d) %s\n", n+1, labels[i])
+	}
+	cmd.Printf("Switch to [1-%d]: ", len(shown))
+
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		if err != nil {
+			cmd.Println()
+		}
+		return -1, nil
+	}
+
+	// Accept a number from the list, or a name
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n < 1 || n > len(shown) {
+			return -1, fmt.Errorf("invalid choice %d: must be between 1 and %d", n, len(shown))
+		}
+		return shown[n-1], nil
+	}
+	for _, i := range shown {
+		if items[i].name == answer {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no worktree named %q", answer)
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSwitchNumberedFallback(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(repoRoot)
+
+	for _, name := range []string{"feature-auth", "bugfix"} {
+		if _, _, err := executeCommand("create", name); err != nil {
+			t.Fatalf("create %s failed: %v", name, err)
+		}
+		defer func(name string) { _, _, _ = executeCommand("delete", name, "--force") }(name)
+	}
+	defer rootCmd.SetIn(nil)
+
+	cdFile := filepath.Join(t.TempDir(), "cd")
+	t.Setenv("WT_CD_FILE", cdFile)
+
+	// Choose by number; the list has the main repo first
+	rootCmd.SetIn(strings.NewReader("3\n"))
+	stdout, _, err := executeCommand("switch")
+	if err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+	for _, s := range []string{"  1) * " + filepath.Base(repoRoot), "(main repository)", "  2)   bugfix", "  3)   feature-auth", "[new]", "Switch to [1-3]:"} {
+		if !strings.Contains(stdout, s) {
+			t.Errorf("expected %q in output:\n%s", s, stdout)
+		}
+	}
+	assertCdFile := func(want string) {
+		t.Helper()
+		data, err := os.ReadFile(cdFile)
+		if err != nil || strings.TrimSpace(string(data)) != want {
+			t.Errorf("expected cd file %q, got %q (%v)", want, data, err)
+		}
+		_ = os.Remove(cdFile)
+	}
+	assertCdFile(filepath.Join(repoRoot, "worktrees", "feature-auth"))
+
+	// A query narrows the list; names are accepted too
+	rootCmd.SetIn(strings.NewReader("bugfix\n"))
+	stdout, _, err = executeCommand("switch", "bug")
+	if err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+	if strings.Contains(stdout, "feature-auth") {
+		t.Errorf("expected the query to filter the list:\n%s", stdout)
+	}
+	assertCdFile(filepath.Join(repoRoot, "worktrees", "bugfix"))
+
+	// The main repo can be chosen from a worktree
+	_ = os.Chdir(filepath.Join(repoRoot, "worktrees", "bugfix"))
+	rootCmd.SetIn(strings.NewReader("1\n"))
+	if _, _, err := executeCommand("switch"); err != nil {
+		t.Fatalf("switch failed: %v", err)
+	}
+	assertCdFile(repoRoot)
+
+	// Empty input cancels; invalid choices are errors
+	rootCmd.SetIn(strings.NewReader("\n"))
+	if _, _, err := executeCommand("switch"); err != nil {
+		t.Errorf("expected empty input to cancel quietly, got %v", err)
+	}
+	if _, err := os.Stat(cdFile); !os.IsNotExist(err) {
+		t.Error("expected no cd file after cancelling")
+	}
+	rootCmd.SetIn(strings.NewReader("9\n"))
+	if _, _, err := executeCommand("switch"); err == nil || !strings.Contains(err.Error(), "between 1 and 3") {
+		t.Errorf("expected out of range error, got %v", err)
+	}
+	if _, _, err := executeCommand("switch", "zzz"); err == nil || !strings.Contains(err.Error(), "no worktree matches") {
+		t.Errorf("expected no match error, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BranchVars are the values available to branch_pattern
+type BranchVars struct {
+	Name  string
+	User  string // git user.name; {user} is its slug, or $USER when empty
+	Base  string // Branch the worktree is created from
+	Index int    // Worktree index, or 0 if none was allocated
+	Time  time.Time
+	// Getenv looks up {env:VAR} and $USER; os.Getenv when nil
+	Getenv func(string) string
+}
+
+// defaultDateLayout is the layout of a bare {date}
+const defaultDateLayout = "2006-01-02"
+
+// branchFilters transform a branch_pattern value, e.g. {name|slug}
+var branchFilters = map[string]func(string) string{
+	"slug":  Slug,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// argKind is whether a branch_pattern variable takes an argument after a colon
+type argKind int
+
+const (
+	argNone argKind = iota
+	argOptional
+	argRequired
+)
+
+// branchVariables are the variables branch_pattern can use
+var branchVariables = map[string]argKind{
+	"name":  argNone,
+	"user":  argNone,
+	"base":  argNone,
+	"index": argNone,
+	"date":  argOptional, // Go time layout
+	"env":   argRequired, // Variable name
+}
+
+// branchPlaceholder is one {variable:arg|filter|...} in a branch_pattern
+type branchPlaceholder struct {
+	start, end int // Position in the pattern
+	variable   string
+	arg        string
+	hasArg     bool
+	filters    []string
+}
+
+// parseBranchPattern finds the placeholders in a branch_pattern, rejecting unknown
+// variables and filters and unbalanced braces
+func parseBranchPattern(pattern string) ([]branchPlaceholder, error) {
+	var placeholders []branchPlaceholder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '}':
+			return nil, fmt.Errorf("unexpected } at position %d", i+1)
+		case '{':
+		default:
+			continue
+		}
+		end := strings.IndexAny(pattern[i+1:], "{}")
+		if end < 0 || pattern[i+1+end] != '}' {
+			return nil, fmt.Errorf("unclosed { at position %d", i+1)
+		}
+		end += i + 1
+
+		p := branchPlaceholder{start: i, end: end + 1}
+		expr, filters, _ := strings.Cut(pattern[i+1:end], "|")
+		p.variable, p.arg, p.hasArg = strings.Cut(strings.TrimSpace(expr), ":")
+		p.variable = strings.TrimSpace(p.variable)
+		switch kind, ok := branchVariables[p.variable]; {
+		case !ok:
+			return nil, fmt.Errorf("unknown variable {%s}", p.variable)
+		case kind == argNone && p.hasArg:
+			return nil, fmt.Errorf("{%s} doesn't take an argument", p.variable)
+		case kind == argRequired && (!p.hasArg || p.arg == ""):
+			return nil, fmt.Errorf("{%s} needs an argument, e.g. {%s:NAME}", p.variable, p.variable)
+		}
+		if filters != "" {
+			for _, f := range strings.Split(filters, "|") {
+				f = strings.TrimSpace(f)
+				if _, ok := branchFilters[f]; !ok {
+					return nil, fmt.Errorf("unknown filter %q in {%s}", f, pattern[i+1:end])
+				}
+				p.filters = append(p.filters, f)
+			}
+		}
+		placeholders = append(placeholders, p)
+		i = end
+	}
+	return placeholders, nil
+}
+
+// usesName reports whether a branch_pattern uses {name}; without it every worktree
+// would get the same branch
+func usesName(placeholders []branchPlaceholder) bool {
+	for _, p := range placeholders {
+		if p.variable == "name" {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandBranchPattern returns the branch name for a new worktree:
+//
+//	{name}                  the worktree name
+//	{user}                  slug of git user.name, or $USER
+//	{date}, {date:Jan-02}   today's date, optionally in a Go time layout
+//	{index}                 the worktree index
+//	{base}                  the branch the worktree is created from
+//	{env:VAR}               an environment variable
+//
+// Each can be followed by filters: {name|slug}, {user|upper} and {base|lower}.
+// The result is not checked against git's rules for branch names.
+func ExpandBranchPattern(pattern string, vars BranchVars) (string, error) {
+	placeholders, err := parseBranchPattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	getenv := vars.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, p := range placeholders {
+		b.WriteString(pattern[last:p.start])
+		last = p.end
+
+		var value string
+		switch p.variable {
+		case "name":
+			value = vars.Name
+		case "user":
+			value = Slug(vars.User)
+			if value == "" {
+				value = getenv("USER")
+			}
+			if value == "" {
+				return "", fmt.Errorf("{user}: git user.name and $USER are not set")
+			}
+		case "base":
+			value = vars.Base
+			if value == "" {
+				return "", fmt.Errorf("{base}: not on a branch")
+			}
+		case "index":
+			if vars.Index <= 0 {
+				return "", fmt.Errorf("{index}: no index was allocated")
+			}
+			value = strconv.Itoa(vars.Index)
+		case "date":
+			layout := defaultDateLayout
+			if p.hasArg && p.arg != "" {
+				layout = p.arg
+			}
+			value = vars.Time.Format(layout)
+		case "env":
+			value = getenv(p.arg)
+			if value == "" {
+				return "", fmt.Errorf("{env:%s}: $%s is not set", p.arg, p.arg)
+			}
+		}
+		for _, f := range p.filters {
+			value = branchFilters[f](value)
+		}
+		b.WriteString(value)
+	}
+	b.WriteString(pattern[last:])
+	return b.String(), nil
+}
+
+// nonSlugPattern matches runs of characters that don't belong in a slug
+var nonSlugPattern = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// Slug lowercases s and replaces each run of characters other than letters and
+// digits with a hyphen, e.g. "Jane Doe" becomes "jane-doe"
+func Slug(s string) string {
+	return strings.Trim(nonSlugPattern.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
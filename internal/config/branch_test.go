@@ -0,0 +1,116 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandBranchPattern(t *testing.T) {
+	env := map[string]string{"USER": "jdoe", "TICKET": "ABC-12"}
+	vars := BranchVars{
+		Name:   "Fix Login",
+		User:   "Jane Doe",
+		Base:   "main",
+		Index:  3,
+		Time:   time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC),
+		Getenv: func(k string) string { return env[k] },
+	}
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"{name}", "Fix Login"},
+		{"feature/{name|slug}", "feature/fix-login"},
+		{"{user}/{date}-{name|slug}", "jane-doe/2026-10-16-fix-login"},
+		{"{date:2006/01}/{name|lower}", "2026/10/fix login"},
+		{"{date:15:04}", "09:30"},
+		{"{env:TICKET|lower}-{name|slug|upper}", "abc-12-FIX-LOGIN"},
+		{"{base}-{index}-{ name | slug }", "main-3-fix-login"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := ExpandBranchPattern(tt.pattern, vars)
+			if err != nil {
+				t.Fatalf("ExpandBranchPattern failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandBranchPattern(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+
+	// {user} falls back to $USER
+	noName := vars
+	noName.User = ""
+	if got, _ := ExpandBranchPattern("{user}/{name|slug}", noName); got != "jdoe/fix-login" {
+		t.Errorf("expected $USER for {user}, got %q", got)
+	}
+}
+
+func TestExpandBranchPatternErrors(t *testing.T) {
+	vars := BranchVars{Name: "x", Getenv: func(string) string { return "" }}
+	tests := []struct {
+		pattern string
+		wantErr string
+	}{
+		{"{nam}", "unknown variable {nam}"},
+		{"{name|slugify}", `unknown filter "slugify" in {name|slugify}`},
+		{"{name:x}", "{name} doesn't take an argument"},
+		{"{env}", "{env} needs an argument"},
+		{"{name", "unclosed { at position 1"},
+		{"a}{name}", "unexpected } at position 2"},
+		{"{user}", "git user.name and $USER are not set"},
+		{"{base}", "not on a branch"},
+		{"{index}", "no index was allocated"},
+		{"{env:MISSING}", "$MISSING is not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := ExpandBranchPattern(tt.pattern, vars)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateBranchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"{user}/{date}-{name|slug}", ""},
+		{"feature/{base}", `branch_pattern "feature/{base}" must contain {name}`},
+		{"{name|slugify}", `branch_pattern "{name|slugify}": unknown filter "slugify" in {name|slugify}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.BranchPattern = tt.pattern
+			problems := Validate(t.TempDir(), cfg)
+			switch {
+			case tt.want == "" && len(problems) > 0:
+				t.Errorf("unexpected problems: %+v", problems)
+			case tt.want != "" && (len(problems) != 1 || problems[0].Message != tt.want):
+				t.Errorf("problems = %+v, want %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	for in, want := range map[string]string{
+		"Jane Doe":       "jane-doe",
+		"  Fix: login! ": "fix-login",
+		"already-slug":   "already-slug",
+		"Ünïcode Ök":     "ünïcode-ök",
+		"":               "",
+	} {
+		if got := Slug(in); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
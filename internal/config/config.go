@@ -1,30 +1,127 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"slices"
 
+	"github.com/agarcher/wt/internal/git"
 	"gopkg.in/yaml.v3"
 )
 
 const (
 	ConfigFileName = ".wt.yaml"
+
+	// MergeDetectionRemoval runs post_merge hooks from wt cleanup and wt delete only
+	MergeDetectionRemoval = "removal"
+	// MergeDetectionStatus also runs them from wt list and wt info, when they first
+	// show a worktree merged
+	MergeDetectionStatus = "status"
 )
 
 // IndexConfig contains worktree index configuration
 type IndexConfig struct {
-	Max int `yaml:"max"` // Maximum allowed index (0 = no limit)
+	Max      int   `yaml:"max"`      // Maximum allowed index (0 = no limit)
+	Reserved []int `yaml:"reserved"` // Indexes never allocated to worktrees
+}
+
+// LogsConfig contains hook log retention configuration
+type LogsConfig struct {
+	MaxFiles int   `yaml:"max_files"` // Maximum hook log files kept per worktree (0 = default)
+	MaxBytes int64 `yaml:"max_bytes"` // Maximum total hook log size per worktree in bytes (0 = default)
+}
+
+// FilesConfig lists files provisioned into new worktrees before post_create hooks run.
+// Entries are glob patterns relative to the main repository root.
+type FilesConfig struct {
+	Copy     []string `yaml:"copy"`     // Copied into the worktree
+	Symlink  []string `yaml:"symlink"`  // Linked to the main repository's copy
+	Template []string `yaml:"template"` // Copied with {name}, {branch}, {index} etc. expanded; a .tmpl suffix is dropped
+}
+
+// ResourcesConfig declares named ports allocated to each worktree, keyed by name
+type ResourcesConfig map[string]PortConfig
+
+// PortConfig is a port expression over the worktree index, e.g. "5173+index*10".
+// Written as a mapping, count reserves a range of ports starting at port.
+type PortConfig struct {
+	Port  string `yaml:"port"`
+	Count int    `yaml:"count"` // Number of ports in the range (0 or 1 = a single port)
+}
+
+// UnmarshalYAML accepts either a bare expression or a {port, count} mapping
+func (p *PortConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		p.Port = value.Value
+		return nil
+	}
+	if err := checkMappingKeys(value, "PortConfig", "port", "count"); err != nil {
+		return err
+	}
+	type plain PortConfig
+	return value.Decode((*plain)(p))
+}
+
+// Clone methods for clone_dirs, used where the filesystem doesn't support reflinks
+const (
+	CloneMethodCopy     = "copy"     // Plain byte copy (default)
+	CloneMethodHardlink = "hardlink" // Hard link to the main checkout's file, falling back to a copy
+)
+
+// CloneDirConfig is a directory glob cloned into new worktrees with reflinks where
+// supported. Written as a mapping, method picks what to do where they aren't.
+type CloneDirConfig struct {
+	Path   string `yaml:"path"`
+	Method string `yaml:"method"` // "copy" (default) or "hardlink"
+}
+
+// UnmarshalYAML accepts either a bare glob or a {path, method} mapping
+func (c *CloneDirConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		c.Path = value.Value
+		return nil
+	}
+	if err := checkMappingKeys(value, "CloneDirConfig", "path", "method"); err != nil {
+		return err
+	}
+	type plain CloneDirConfig
+	return value.Decode((*plain)(c))
+}
+
+// checkMappingKeys reports keys of a mapping node that aren't in keys, as the strict
+// decoder would: Node.Decode doesn't check for unknown keys itself
+func checkMappingKeys(value *yaml.Node, typeName string, keys ...string) error {
+	if value.Kind != yaml.MappingNode {
+		return nil
+	}
+	var unknown []string
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if key := value.Content[i]; !slices.Contains(keys, key.Value) {
+			unknown = append(unknown, fmt.Sprintf("line %d: field %s not found in type config.%s", key.Line, key.Value, typeName))
+		}
+	}
+	if len(unknown) > 0 {
+		return &yaml.TypeError{Errors: unknown}
+	}
+	return nil
 }
 
 // Config represents the repository-level configuration
 type Config struct {
-	Version       int          `yaml:"version"`
-	WorktreeDir   string       `yaml:"worktree_dir"`
-	BranchPattern string       `yaml:"branch_pattern"`
-	DefaultBranch string       `yaml:"default_branch"` // Branch to compare against (e.g., "main", "develop")
-	Hooks         HooksConfig  `yaml:"hooks"`
-	Index         IndexConfig  `yaml:"index"`
+	Version        int               `yaml:"version"`
+	Include        []string          `yaml:"include"` // Config files merged under this one, relative to it
+	WorktreeDir    string            `yaml:"worktree_dir"`
+	BranchPattern  string            `yaml:"branch_pattern"`
+	DefaultBranch  string            `yaml:"default_branch"` // Branch to compare against (e.g., "main", "develop")
+	Hooks          HooksConfig       `yaml:"hooks"`
+	Index          IndexConfig       `yaml:"index"`
+	Logs           LogsConfig        `yaml:"logs"`
+	Files          FilesConfig       `yaml:"files"`
+	CloneDirs      []CloneDirConfig  `yaml:"clone_dirs"` // Directories cloned into new worktrees with reflinks where supported
+	Resources      ResourcesConfig   `yaml:"resources"`
+	Env            map[string]string `yaml:"env"`             // Per-worktree environment loaded by the shell integration; values may use {name}, {index} etc.
+	MergeDetection string            `yaml:"merge_detection"` // Where post_merge hooks run: "removal" (default) or "status"
 }
 
 // HooksConfig contains all lifecycle hook configurations
@@ -34,49 +131,71 @@ type HooksConfig struct {
 	PreDelete  []HookEntry `yaml:"pre_delete"`
 	PostDelete []HookEntry `yaml:"post_delete"`
 	Info       []HookEntry `yaml:"info"`
+
+	PostSwitch  []HookEntry `yaml:"post_switch"`  // After `wt cd`/`wt exit` switches directory via the shell wrapper
+	PreCleanup  []HookEntry `yaml:"pre_cleanup"`  // Once per `wt cleanup` run, before any worktree is deleted
+	PostCleanup []HookEntry `yaml:"post_cleanup"` // Once per `wt cleanup` run, after deletions
+	PostMerge   []HookEntry `yaml:"post_merge"`   // When a worktree's branch is first seen merged
 }
 
 // HookEntry represents a single hook script configuration
 type HookEntry struct {
-	Script string            `yaml:"script"`
-	Env    map[string]string `yaml:"env"`
+	Script   string            `yaml:"script"`
+	Env      map[string]string `yaml:"env"`
+	Format   string            `yaml:"format"`    // Info hooks only: "text" (default) or "json"
+	Timeout  string            `yaml:"timeout"`   // Info hooks only: kill the hook after this long (e.g. "5s")
+	CacheTTL string            `yaml:"cache_ttl"` // Info hooks only: reuse output for this long (e.g. "1m")
 }
 
 // DefaultConfig returns a config with default values
 func DefaultConfig() *Config {
 	return &Config{
-		Version:       1,
+		Version:       CurrentVersion(),
 		WorktreeDir:   "worktrees",
 		BranchPattern: "{name}",
 	}
 }
 
-// Load reads the configuration from the given repository root
+// Load reads the configuration from the given repository root: .wt.yaml, the files
+// it includes and .wt.local.yaml, merged
 func Load(repoRoot string) (*Config, error) {
-	configPath := filepath.Join(repoRoot, ConfigFileName)
-
-	data, err := os.ReadFile(configPath)
+	m, err := loadMerged(repoRoot)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, err
-		}
 		return nil, err
 	}
 
-	cfg := DefaultConfig()
-	if err := yaml.Unmarshal(data, cfg); err != nil {
+	cfg := defaultConfigFor(repoRoot)
+	if err := decode(ConfigFileName, m.data, cfg); err != nil {
 		return nil, err
 	}
+	applyDefaults(cfg)
+	cfg.Include = nil // Already merged
+	if err := resolveWorktreeDir(cfg, repoRoot); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// defaultConfigFor returns the default config for a repository. When the root is a
+// bare repository's git dir, worktrees default to its siblings, as git keeps its own
+// worktrees directory there.
+func defaultConfigFor(repoRoot string) *Config {
+	cfg := DefaultConfig()
+	if git.IsGitDir(repoRoot) {
+		cfg.WorktreeDir = ".."
+	}
+	return cfg
+}
 
-	// Apply defaults for empty values
+// applyDefaults fills in defaults for values left empty
+func applyDefaults(cfg *Config) {
 	if cfg.WorktreeDir == "" {
 		cfg.WorktreeDir = "worktrees"
 	}
 	if cfg.BranchPattern == "" {
 		cfg.BranchPattern = "{name}"
 	}
-
-	return cfg, nil
 }
 
 // Exists checks if a config file exists in the given repository root
@@ -85,76 +204,3 @@ func Exists(repoRoot string) bool {
 	_, err := os.Stat(configPath)
 	return err == nil
 }
-
-// GetRepoRoot finds the root of the git repository from the current directory
-func GetRepoRoot() (string, error) {
-	dir, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-
-	for {
-		gitDir := filepath.Join(dir, ".git")
-		if info, err := os.Stat(gitDir); err == nil {
-			// .git can be a directory (normal repo) or a file (worktree)
-			if info.IsDir() || info.Mode().IsRegular() {
-				return dir, nil
-			}
-		}
-
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			return "", os.ErrNotExist
-		}
-		dir = parent
-	}
-}
-
-// GetMainRepoRoot finds the main repository root, even if we're in a worktree
-func GetMainRepoRoot() (string, error) {
-	dir, err := GetRepoRoot()
-	if err != nil {
-		return "", err
-	}
-
-	gitPath := filepath.Join(dir, ".git")
-	info, err := os.Stat(gitPath)
-	if err != nil {
-		return "", err
-	}
-
-	var repoRoot string
-
-	// If .git is a directory, this is the main repo
-	if info.IsDir() {
-		repoRoot = dir
-	} else {
-		// If .git is a file, read it to find the main repo
-		// Format: "gitdir: /path/to/main/.git/worktrees/name"
-		data, err := os.ReadFile(gitPath)
-		if err != nil {
-			return "", err
-		}
-
-		// Parse the gitdir path
-		content := string(data)
-		if len(content) <= 8 || content[:8] != "gitdir: " {
-			repoRoot = dir // fallback to current dir
-		} else {
-			gitdir := strings.TrimSpace(content[8:])
-			if gitdir == "" {
-				repoRoot = dir // fallback to current dir
-			} else {
-				gitdir = filepath.Clean(gitdir)
-
-				// Navigate up from .git/worktrees/name to the main repo
-				// gitdir is like: /path/to/main/.git/worktrees/name
-				mainGitDir := filepath.Dir(filepath.Dir(gitdir))
-				repoRoot = filepath.Dir(mainGitDir)
-			}
-		}
-	}
-
-	// Resolve symlinks to ensure path consistency with git worktree output
-	return filepath.EvalSymlinks(repoRoot)
-}
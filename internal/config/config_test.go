@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -122,6 +124,75 @@ worktree_dir: worktrees
 				}
 			},
 		},
+		{
+			name: "config with files and clone_dirs",
+			configYAML: `version: 1
+files:
+  copy:
+    - .env*
+  symlink:
+    - node_modules
+  template:
+    - config/dev.json.tmpl
+clone_dirs:
+  - node_modules
+  - path: target
+    method: hardlink
+`,
+			wantErr: false,
+			checkConfig: func(t *testing.T, cfg *Config) {
+				if len(cfg.Files.Copy) != 1 || cfg.Files.Copy[0] != ".env*" {
+					t.Errorf("expected files.copy [.env*], got %v", cfg.Files.Copy)
+				}
+				if len(cfg.Files.Symlink) != 1 || cfg.Files.Symlink[0] != "node_modules" {
+					t.Errorf("expected files.symlink [node_modules], got %v", cfg.Files.Symlink)
+				}
+				if len(cfg.Files.Template) != 1 || cfg.Files.Template[0] != "config/dev.json.tmpl" {
+					t.Errorf("expected files.template [config/dev.json.tmpl], got %v", cfg.Files.Template)
+				}
+				want := []CloneDirConfig{{Path: "node_modules"}, {Path: "target", Method: CloneMethodHardlink}}
+				if !reflect.DeepEqual(cfg.CloneDirs, want) {
+					t.Errorf("expected clone_dirs %v, got %v", want, cfg.CloneDirs)
+				}
+			},
+		},
+		{
+			name: "config with resources",
+			configYAML: `version: 1
+resources:
+  web: 5173+index*10
+  db: "5432 + index"
+  workers:
+    port: 6000+index*10
+    count: 10
+`,
+			wantErr: false,
+			checkConfig: func(t *testing.T, cfg *Config) {
+				if cfg.Resources["web"] != (PortConfig{Port: "5173+index*10"}) {
+					t.Errorf("unexpected web resource: %+v", cfg.Resources["web"])
+				}
+				if cfg.Resources["db"] != (PortConfig{Port: "5432 + index"}) {
+					t.Errorf("unexpected db resource: %+v", cfg.Resources["db"])
+				}
+				if cfg.Resources["workers"] != (PortConfig{Port: "6000+index*10", Count: 10}) {
+					t.Errorf("unexpected workers resource: %+v", cfg.Resources["workers"])
+				}
+			},
+		},
+		{
+			name: "config with env",
+			configYAML: `version: 1
+env:
+  API_URL: "http://localhost:{index*10+5173}"
+  APP_NAME: app-{name}
+`,
+			wantErr: false,
+			checkConfig: func(t *testing.T, cfg *Config) {
+				if len(cfg.Env) != 2 || cfg.Env["API_URL"] != "http://localhost:{index*10+5173}" || cfg.Env["APP_NAME"] != "app-{name}" {
+					t.Errorf("unexpected env: %v", cfg.Env)
+				}
+			},
+		},
 		{
 			name:       "invalid yaml",
 			configYAML: `version: [invalid`,
@@ -213,10 +284,9 @@ func TestGetRepoRoot(t *testing.T) {
 		t.Fatalf("failed to eval symlinks: %v", err)
 	}
 
-	// Create .git directory
-	gitDir := filepath.Join(tmpDir, ".git")
-	if err := os.MkdirAll(gitDir, 0755); err != nil {
-		t.Fatalf("failed to create .git dir: %v", err)
+	// Initialize a repository
+	if out, err := exec.Command("git", "init", tmpDir).CombinedOutput(); err != nil {
+		t.Fatalf("failed to init repo: %v\n%s", err, out)
 	}
 
 	// Create a subdirectory
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// defaultWorktreeKey designates the checkout of a bare repository that stands in for
+// the main checkout: wt reads .wt.yaml from it and `wt exit` returns to it
+const defaultWorktreeKey = "wt.defaultWorktree"
+
+// mainWorktreeKey records the main checkout in the repository's git config when
+// git can't derive it from the common dir, which is the case for a separate git dir.
+// Only `wt setup` writes it; discovery never changes the repository.
+const mainWorktreeKey = "wt.mainWorktree"
+
+// repoLayout is where git places the current directory
+type repoLayout struct {
+	topLevel  string // Top of the current checkout; empty in a bare repository's git dir
+	gitDir    string // The checkout's git dir; .git/worktrees/<id> in a linked worktree
+	commonDir string // The git dir shared by all worktrees
+}
+
+// discover asks git where the current directory is, honoring GIT_DIR, .git files
+// with relative paths and commondir files as git does
+func discover() (*repoLayout, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	lines, err := revParse("--absolute-git-dir", "--git-common-dir", "--show-toplevel")
+	if err != nil {
+		// There's no top level in a bare repository's git dir
+		bare, bareErr := revParse("--absolute-git-dir", "--git-common-dir", "--is-bare-repository")
+		if bareErr != nil || bare[2] != "true" {
+			return nil, err
+		}
+		lines = append(bare[:2], "")
+	}
+
+	layout := &repoLayout{gitDir: lines[0], commonDir: lines[1], topLevel: lines[2]}
+	// --git-common-dir may be relative to the current directory
+	if !filepath.IsAbs(layout.commonDir) {
+		layout.commonDir = filepath.Join(cwd, layout.commonDir)
+	}
+	for _, path := range []*string{&layout.topLevel, &layout.gitDir, &layout.commonDir} {
+		if *path == "" {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(*path); err == nil {
+			*path = resolved
+		}
+	}
+	return layout, nil
+}
+
+// revParse runs git rev-parse with the given options, returning a line of output
+// for each
+func revParse(options ...string) ([]string, error) {
+	cmd := exec.Command("git", append([]string{"rev-parse"}, options...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s", strings.TrimPrefix(msg, "fatal: "))
+		}
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != len(options) {
+		return nil, fmt.Errorf("unexpected output from git rev-parse: %q", output)
+	}
+	return lines, nil
+}
+
+// GetRepoRoot finds the top of the checkout containing the current directory,
+// which is a worktree's own directory inside a worktree
+func GetRepoRoot() (string, error) {
+	layout, err := discover()
+	if err != nil {
+		return "", err
+	}
+	if layout.topLevel == "" {
+		return "", fmt.Errorf("%s is a bare repository, not a checkout", layout.commonDir)
+	}
+	return layout.topLevel, nil
+}
+
+// GetMainRepoRoot finds the main repository root, even if we're in a worktree. A bare
+// repository has no main checkout: its root is the default worktree designated with
+// `git config wt.defaultWorktree <path>`, or failing that, the bare git dir.
+func GetMainRepoRoot() (string, error) {
+	layout, err := discover()
+	if err != nil {
+		return "", err
+	}
+
+	// In the main checkout, its git dir is the common dir
+	if layout.gitDir == layout.commonDir && layout.topLevel != "" {
+		return layout.topLevel, nil
+	}
+
+	if gitConfigValue(layout.commonDir, "core.bare") == "true" {
+		return bareRepoRoot(layout.commonDir), nil
+	}
+
+	// A submodule's git dir records its checkout in core.worktree
+	if dir := gitConfigValue(layout.commonDir, "core.worktree"); dir != "" {
+		return resolvePath(layout.commonDir, dir), nil
+	}
+	if dir := gitConfigValue(layout.commonDir, mainWorktreeKey); dir != "" {
+		return resolvePath(layout.commonDir, dir), nil
+	}
+	if filepath.Base(layout.commonDir) == ".git" {
+		return filepath.Dir(layout.commonDir), nil
+	}
+	return "", fmt.Errorf("can't find the main checkout of %s; run `wt setup` there once", layout.commonDir)
+}
+
+// bareRepoRoot returns the root of a bare repository: its default worktree, if
+// one is designated and checked out, or its git dir
+func bareRepoRoot(commonDir string) string {
+	if dir := gitConfigValue(commonDir, defaultWorktreeKey); dir != "" {
+		dir = resolvePath(commonDir, dir)
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+	}
+	return commonDir
+}
+
+// SetDefaultWorktree designates the default worktree of the bare repository at
+// gitDir; path may be relative to gitDir
+func SetDefaultWorktree(gitDir, path string) error {
+	return exec.Command("git", "config", "--file", filepath.Join(gitDir, "config"), defaultWorktreeKey, path).Run()
+}
+
+// gitConfigValue returns a value from a git dir's config file, or "" if it isn't set
+func gitConfigValue(gitDir, key string) string {
+	output, err := exec.Command("git", "config", "--file", filepath.Join(gitDir, "config"), "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// RecordMainWorktree records the main checkout in the repository's config when the
+// current directory is in it and git can't derive it from a linked worktree, as with
+// a separate git dir. It reports whether anything was recorded. Under GIT_DIR the
+// current directory may only pass for the checkout, so nothing is.
+func RecordMainWorktree() (bool, error) {
+	layout, err := discover()
+	if err != nil {
+		return false, err
+	}
+	if layout.gitDir != layout.commonDir || layout.topLevel == "" || os.Getenv("GIT_DIR") != "" ||
+		layout.commonDir == filepath.Join(layout.topLevel, ".git") ||
+		gitConfigValue(layout.commonDir, mainWorktreeKey) == layout.topLevel {
+		return false, nil
+	}
+	err = exec.Command("git", "config", "--file", filepath.Join(layout.commonDir, "config"), mainWorktreeKey, layout.topLevel).Run()
+	return err == nil, err
+}
+
+// resolvePath returns path, made absolute relative to dir and with symlinks resolved
+func resolvePath(dir, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
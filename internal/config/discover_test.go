@@ -0,0 +1,250 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runGit runs a git command in dir, failing the test if it fails
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test User", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test User", "GIT_COMMITTER_EMAIL=test@test.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// newRepo creates a repository with one commit at dir
+func newRepo(t *testing.T, dir string, initArgs ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, append([]string{"init", "-q"}, initArgs...)...)
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "Initial commit")
+}
+
+// newBareRepo creates a bare clone, repo.git in dir, of a repository with a main branch
+func newBareRepo(t *testing.T, dir string) string {
+	t.Helper()
+	upstream := filepath.Join(dir, "upstream")
+	newRepo(t, upstream, "-b", "main")
+	bare := filepath.Join(dir, "repo.git")
+	runGit(t, dir, "clone", "-q", "--bare", upstream, bare)
+	return bare
+}
+
+// mainRepoRootFrom runs GetMainRepoRoot with dir as the current directory
+func mainRepoRootFrom(t *testing.T, dir string) (string, error) {
+	t.Helper()
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	return GetMainRepoRoot()
+}
+
+func TestGetMainRepoRootLayouts(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup builds a layout in tmp and returns the directory to run from and the
+		// expected main repository root
+		setup func(t *testing.T, tmp string) (cwd, want string)
+	}{
+		{
+			name: "main checkout subdirectory",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				main := filepath.Join(tmp, "main")
+				newRepo(t, main)
+				sub := filepath.Join(main, "sub", "dir")
+				_ = os.MkdirAll(sub, 0755)
+				return sub, main
+			},
+		},
+		{
+			name: "linked worktree",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				main := filepath.Join(tmp, "main")
+				newRepo(t, main)
+				runGit(t, main, "worktree", "add", "-q", filepath.Join(main, "worktrees", "wt"))
+				return filepath.Join(main, "worktrees", "wt"), main
+			},
+		},
+		{
+			name: "linked worktree with a relative gitdir",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				main := filepath.Join(tmp, "main")
+				newRepo(t, main)
+				wt := filepath.Join(tmp, "wt")
+				runGit(t, main, "worktree", "add", "-q", wt)
+				if err := os.WriteFile(filepath.Join(wt, ".git"), []byte("gitdir: ../main/.git/worktrees/wt\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return wt, main
+			},
+		},
+		{
+			name: "submodule",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				upstream := filepath.Join(tmp, "upstream")
+				newRepo(t, upstream)
+				super := filepath.Join(tmp, "super")
+				newRepo(t, super)
+				runGit(t, super, "-c", "protocol.file.allow=always", "submodule", "add", "-q", upstream, "sub")
+				return filepath.Join(super, "sub"), filepath.Join(super, "sub")
+			},
+		},
+		{
+			name: "submodule linked worktree",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				upstream := filepath.Join(tmp, "upstream")
+				newRepo(t, upstream)
+				super := filepath.Join(tmp, "super")
+				newRepo(t, super)
+				runGit(t, super, "-c", "protocol.file.allow=always", "submodule", "add", "-q", upstream, "sub")
+				wt := filepath.Join(tmp, "sub-wt")
+				runGit(t, filepath.Join(super, "sub"), "worktree", "add", "-q", wt)
+				return wt, filepath.Join(super, "sub")
+			},
+		},
+		{
+			name: "separate git dir",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				main := filepath.Join(tmp, "main")
+				newRepo(t, main, "--separate-git-dir", filepath.Join(tmp, "main.git"))
+				return main, main
+			},
+		},
+		{
+			name: "separate git dir linked worktree",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				main := filepath.Join(tmp, "main")
+				newRepo(t, main, "--separate-git-dir", filepath.Join(tmp, "main.git"))
+				wt := filepath.Join(tmp, "wt")
+				runGit(t, main, "worktree", "add", "-q", wt)
+				// git can't tell where the main checkout is; wt setup records it there
+				if _, err := mainRepoRootFrom(t, wt); err == nil {
+					t.Error("expected an error before the main checkout was recorded")
+				}
+				if _, err := mainRepoRootFrom(t, main); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := mainRepoRootFrom(t, wt); err == nil {
+					t.Error("expected discovery not to record the main checkout")
+				}
+				oldDir, _ := os.Getwd()
+				defer func() { _ = os.Chdir(oldDir) }()
+				_ = os.Chdir(main)
+				if recorded, err := RecordMainWorktree(); err != nil || !recorded {
+					t.Fatalf("RecordMainWorktree = %v, %v", recorded, err)
+				}
+				return wt, main
+			},
+		},
+		{
+			name: "bare repository",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				bare := newBareRepo(t, tmp)
+				return bare, bare
+			},
+		},
+		{
+			name: "bare repository worktree",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				bare := newBareRepo(t, tmp)
+				wt := filepath.Join(tmp, "feature")
+				runGit(t, bare, "worktree", "add", "-q", "-b", "feature", wt)
+				return wt, bare
+			},
+		},
+		{
+			name: "bare repository default worktree",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				bare := newBareRepo(t, tmp)
+				main := filepath.Join(tmp, "main")
+				runGit(t, bare, "worktree", "add", "-q", main, "main")
+				runGit(t, bare, "config", "wt.defaultWorktree", "../main")
+				wt := filepath.Join(tmp, "feature")
+				runGit(t, bare, "worktree", "add", "-q", "-b", "feature", wt)
+				return wt, main
+			},
+		},
+		{
+			name: "GIT_DIR and GIT_WORK_TREE",
+			setup: func(t *testing.T, tmp string) (string, string) {
+				main := filepath.Join(tmp, "main")
+				newRepo(t, main)
+				elsewhere := filepath.Join(tmp, "elsewhere")
+				_ = os.MkdirAll(elsewhere, 0755)
+				t.Setenv("GIT_DIR", filepath.Join(main, ".git"))
+				t.Setenv("GIT_WORK_TREE", main)
+				return elsewhere, main
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			cwd, want := tt.setup(t, tmp)
+			got, err := mainRepoRootFrom(t, cwd)
+			if err != nil {
+				t.Fatalf("GetMainRepoRoot failed: %v", err)
+			}
+			if got != want {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestGetRepoRootInWorktree(t *testing.T) {
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(tmp, "main")
+	newRepo(t, main)
+	wt := filepath.Join(tmp, "wt")
+	runGit(t, main, "worktree", "add", "-q", wt)
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(wt)
+
+	root, err := GetRepoRoot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if root != wt {
+		t.Errorf("expected the worktree %s, got %s", wt, root)
+	}
+}
+
+func TestGetRepoRootInBareRepository(t *testing.T) {
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare := newBareRepo(t, tmp)
+
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(bare)
+
+	if _, err := GetRepoRoot(); err == nil {
+		t.Error("expected an error in a bare repository's git dir")
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Generate returns a new .wt.yaml for cfg: its version, worktree_dir,
+// branch_pattern, default_branch when set, and the scripts of its hooks
+func Generate(cfg *Config) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value *yaml.Node) {
+		root.Content = append(root.Content, scalarNode(key), value)
+	}
+	add("version", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(cfg.Version)})
+	add("worktree_dir", scalarNode(cfg.WorktreeDir))
+	add("branch_pattern", scalarNode(cfg.BranchPattern))
+	if cfg.DefaultBranch != "" {
+		add("default_branch", scalarNode(cfg.DefaultBranch))
+	}
+
+	hooks := &yaml.Node{Kind: yaml.MappingNode}
+	for _, event := range []struct {
+		name    string
+		entries []HookEntry
+	}{
+		{"pre_create", cfg.Hooks.PreCreate},
+		{"post_create", cfg.Hooks.PostCreate},
+		{"pre_delete", cfg.Hooks.PreDelete},
+		{"post_delete", cfg.Hooks.PostDelete},
+		{"post_switch", cfg.Hooks.PostSwitch},
+		{"pre_cleanup", cfg.Hooks.PreCleanup},
+		{"post_cleanup", cfg.Hooks.PostCleanup},
+		{"post_merge", cfg.Hooks.PostMerge},
+		{"info", cfg.Hooks.Info},
+	} {
+		if len(event.entries) == 0 {
+			continue
+		}
+		list := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, entry := range event.entries {
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalarNode("script"), scalarNode(entry.Script)}})
+		}
+		hooks.Content = append(hooks.Content, scalarNode(event.name), list)
+	}
+	if len(hooks.Content) > 0 {
+		add("hooks", hooks)
+	}
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	doc.HeadComment = "wt configuration: https://github.com/agarcher/wt/blob/main/docs/USAGE.md#repository-configuration"
+	return encode(doc)
+}
+
+// scalarNode returns a string node, quoted by the encoder where YAML needs it
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
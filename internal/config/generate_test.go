@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRoundTrips(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.WorktreeDir = "../{repo}-worktrees"
+	cfg.BranchPattern = "{user}/{name}"
+	cfg.DefaultBranch = "develop"
+	cfg.Hooks.PostCreate = []HookEntry{{Script: "./scripts/setup.sh"}}
+	cfg.Hooks.Info = []HookEntry{{Script: "./scripts/show-info.sh"}}
+
+	data, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# wt configuration") {
+		t.Errorf("expected a leading comment, got:\n%s", data)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("generated config doesn't load: %v\n%s", err, data)
+	}
+	if loaded.Version != CurrentVersion() || loaded.BranchPattern != cfg.BranchPattern || loaded.DefaultBranch != "develop" {
+		t.Errorf("unexpected config loaded from:\n%s", data)
+	}
+	if len(loaded.Hooks.PostCreate) != 1 || loaded.Hooks.PostCreate[0].Script != "./scripts/setup.sh" {
+		t.Errorf("expected the post_create hook, got %+v", loaded.Hooks.PostCreate)
+	}
+	if len(loaded.Hooks.Info) != 1 || len(loaded.Hooks.PreDelete) != 0 {
+		t.Errorf("expected only the configured hooks, got:\n%s", data)
+	}
+}
+
+func TestGenerateOmitsUnsetValues(t *testing.T) {
+	data, err := Generate(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for _, key := range []string{"default_branch", "hooks"} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("expected no %s, got:\n%s", key, data)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalConfigFileName is an untracked file merged over .wt.yaml for personal settings
+const LocalConfigFileName = ".wt.local.yaml"
+
+// replaceTag marks a list that replaces the one it's merged over instead of extending it,
+// e.g. "post_create: !replace [...]"
+const replaceTag = "!replace"
+
+// merged is the repository config after merging .wt.yaml, its includes and .wt.local.yaml
+type merged struct {
+	root    *yaml.Node            // Merged mapping
+	origins map[*yaml.Node]string // File each node came from, relative to the repo root where possible
+	data    []byte                // Merged document, for decoding
+	files   []string              // Files merged, in merge order
+}
+
+// loadMerged reads .wt.yaml and .wt.local.yaml with their includes, strictly decoding
+// each file on its own so errors name the right file and line, and merges them:
+//   - included files first, then the file that includes them
+//   - .wt.local.yaml (and its includes) last
+//
+// Mappings are merged key by key and scalars replaced. Hook lists are appended to,
+// other lists replaced; a list tagged !replace always replaces.
+func loadMerged(repoRoot string) (*merged, error) {
+	m := &merged{root: &yaml.Node{Kind: yaml.MappingNode}, origins: map[*yaml.Node]string{}}
+
+	if err := m.mergeFile(repoRoot, filepath.Join(repoRoot, ConfigFileName), nil); err != nil {
+		return nil, err
+	}
+	local := filepath.Join(repoRoot, LocalConfigFileName)
+	if _, err := os.Stat(local); err == nil {
+		if err := m.mergeFile(repoRoot, local, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	clearTags(m.root)
+	data, err := yaml.Marshal(m.root)
+	if err != nil {
+		return nil, err
+	}
+	m.data = data
+	return m, nil
+}
+
+// mergeFile merges a config file's includes and then the file itself. stack holds the
+// files including this one, to reject include cycles.
+func (m *merged) mergeFile(repoRoot, path string, stack []string) error {
+	display := displayPath(repoRoot, path)
+	for _, p := range stack {
+		if p == path {
+			return fmt.Errorf("include cycle: %s", strings.Join(append(displayPaths(repoRoot, stack), display), " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if len(stack) > 0 {
+			return fmt.Errorf("%s: include %s: %w", displayPath(repoRoot, stack[len(stack)-1]), display, err)
+		}
+		return err
+	}
+	if data, err = upgrade(display, data); err != nil {
+		return err
+	}
+	// Check each file strictly on its own, so problems point at the right file
+	var cfg Config
+	if err := decode(display, data, &cfg); err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil // Empty file
+	}
+	root := doc.Content[0]
+	m.setOrigin(root, display)
+
+	// Included files are merged first, so the including file wins
+	for _, include := range cfg.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		if err := m.mergeFile(repoRoot, include, append(stack, path)); err != nil {
+			return err
+		}
+	}
+
+	mergeNodes(m.root, root, nil)
+	if !slices.Contains(m.files, path) {
+		m.files = append(m.files, path)
+	}
+	return nil
+}
+
+// setOrigin records the file of a node and everything under it
+func (m *merged) setOrigin(n *yaml.Node, file string) {
+	m.origins[n] = file
+	for _, c := range n.Content {
+		m.setOrigin(c, file)
+	}
+}
+
+// mergeNodes merges the mapping src into dst. path is the keys leading to dst.
+func mergeNodes(dst, src *yaml.Node, path []string) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		if len(path) == 0 && key.Value == "include" {
+			continue // Already merged
+		}
+
+		j := mappingIndex(dst, key.Value)
+		if j < 0 {
+			dst.Content = append(dst.Content, key, value)
+			continue
+		}
+		existing := dst.Content[j+1]
+		switch {
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeNodes(existing, value, append(path, key.Value))
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode &&
+			value.Tag != replaceTag && isHookList(append(path, key.Value)):
+			existing.Content = append(existing.Content, value.Content...)
+		default:
+			dst.Content[j], dst.Content[j+1] = key, value
+		}
+	}
+}
+
+// isHookList reports whether the keys lead to a hook event's list, e.g. hooks.post_create
+func isHookList(path []string) bool {
+	return len(path) == 2 && path[0] == "hooks"
+}
+
+// mappingIndex returns the index of key in a mapping node's content, or -1
+func mappingIndex(n *yaml.Node, key string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// clearTags drops !replace markers, which have done their job once merged
+func clearTags(n *yaml.Node) {
+	if n.Tag == replaceTag {
+		n.Tag = ""
+	}
+	for _, c := range n.Content {
+		clearTags(c)
+	}
+}
+
+// displayPath shows a config file relative to the repository root when it's inside it
+func displayPath(repoRoot, path string) string {
+	if rel, err := filepath.Rel(repoRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func displayPaths(repoRoot string, paths []string) []string {
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = displayPath(repoRoot, p)
+	}
+	return out
+}
+
+// locate returns the file and line of the value at a dotted key (map keys and sequence
+// indexes), or of the deepest part of the key that exists
+func (m *merged) locate(key string) (file string, line int) {
+	node := m.root
+	for _, step := range strings.Split(key, ".") {
+		var next, at *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			if i := mappingIndex(node, step); i >= 0 {
+				at, next = node.Content[i], node.Content[i+1]
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(step); err == nil && i >= 0 && i < len(node.Content) {
+				at, next = node.Content[i], node.Content[i]
+			}
+		}
+		if next == nil {
+			break
+		}
+		file, line = m.origins[at], at.Line
+		node = next
+	}
+	return file, line
+}
+
+// Setting is a repository config value and the file it came from
+type Setting struct {
+	Key   string // e.g. "worktree_dir" or "hooks.post_create[0].script"
+	Value string
+	File  string // Relative to the repository root when inside it
+}
+
+// Settings lists every value set in the repository config files, in file order,
+// with the file that set it
+func Settings(repoRoot string) ([]Setting, error) {
+	m, err := loadMerged(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	var settings []Setting
+	var walk func(n *yaml.Node, key string)
+	walk = func(n *yaml.Node, key string) {
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				child := n.Content[i].Value
+				if key != "" {
+					child = key + "." + child
+				}
+				walk(n.Content[i+1], child)
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				walk(c, fmt.Sprintf("%s[%d]", key, i))
+			}
+		default:
+			settings = append(settings, Setting{Key: key, Value: n.Value, File: m.origins[n]})
+		}
+	}
+	walk(m.root, "")
+	return settings, nil
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes config files into a new repo directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func hookScripts(entries []HookEntry) []string {
+	var scripts []string
+	for _, e := range entries {
+		scripts = append(scripts, e.Script)
+	}
+	return scripts
+}
+
+func TestLoadMergesLocalAndIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		ConfigFileName: `version: 1
+include: [shared/hooks.yaml]
+worktree_dir: trees
+clone_dirs: [node_modules]
+hooks:
+  post_create:
+    - script: main.sh
+env:
+  APP: main
+  SHARED: main
+`,
+		"shared/hooks.yaml": `hooks:
+  post_create:
+    - script: shared.sh
+  info:
+    - script: info.sh
+env:
+  SHARED: shared
+  ONLY_SHARED: "1"
+`,
+		LocalConfigFileName: `worktree_dir: my-trees
+clone_dirs: [target]
+hooks:
+  post_create:
+    - script: mine.sh
+  info: !replace
+    - script: my-info.sh
+env:
+  APP: mine
+`,
+	})
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.WorktreeDir != "my-trees" {
+		t.Errorf("local scalar should win, got worktree_dir %q", cfg.WorktreeDir)
+	}
+	// Hook lists are appended in merge order: includes, .wt.yaml, .wt.local.yaml
+	if got, want := hookScripts(cfg.Hooks.PostCreate), []string{"shared.sh", "main.sh", "mine.sh"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("post_create = %v, want %v", got, want)
+	}
+	// unless the list is tagged !replace
+	if got, want := hookScripts(cfg.Hooks.Info), []string{"my-info.sh"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("info = %v, want %v", got, want)
+	}
+	// Other lists are replaced
+	if !reflect.DeepEqual(cfg.CloneDirs, []CloneDirConfig{{Path: "target"}}) {
+		t.Errorf("clone_dirs = %v, want [target]", cfg.CloneDirs)
+	}
+	// Maps are merged key by key
+	wantEnv := map[string]string{"APP": "mine", "SHARED": "main", "ONLY_SHARED": "1"}
+	if !reflect.DeepEqual(cfg.Env, wantEnv) {
+		t.Errorf("env = %v, want %v", cfg.Env, wantEnv)
+	}
+	if cfg.Include != nil {
+		t.Errorf("include should be cleared once merged, got %v", cfg.Include)
+	}
+}
+
+func TestLoadIncludeErrors(t *testing.T) {
+	t.Run("cycle", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			ConfigFileName: "include: [a.yaml]\n",
+			"a.yaml":       "include: [.wt.yaml]\n",
+		})
+		_, err := Load(dir)
+		if err == nil || !strings.Contains(err.Error(), "include cycle: .wt.yaml -> a.yaml -> .wt.yaml") {
+			t.Errorf("expected an include cycle error, got %v", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{ConfigFileName: "include: [missing.yaml]\n"})
+		_, err := Load(dir)
+		if err == nil || !strings.Contains(err.Error(), ".wt.yaml: include missing.yaml") {
+			t.Errorf("expected a missing include error, got %v", err)
+		}
+	})
+
+	t.Run("unknown key in local file", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			ConfigFileName:      "version: 1\n",
+			LocalConfigFileName: "version: 1\nworktree_dri: x\n",
+		})
+		_, err := Load(dir)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Path != LocalConfigFileName || parseErr.Problems[0].Line != 2 {
+			t.Errorf("expected a problem on line 2 of %s, got %v", LocalConfigFileName, err)
+		}
+	})
+}
+
+func TestSettings(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		ConfigFileName:      "version: 1\nworktree_dir: trees\nhooks:\n  post_create:\n    - script: main.sh\n",
+		LocalConfigFileName: "worktree_dir: mine\nhooks:\n  post_create:\n    - script: mine.sh\n",
+	})
+
+	settings, err := Settings(dir)
+	if err != nil {
+		t.Fatalf("Settings failed: %v", err)
+	}
+	want := []Setting{
+		{Key: "version", Value: "1", File: ConfigFileName},
+		{Key: "worktree_dir", Value: "mine", File: LocalConfigFileName},
+		{Key: "hooks.post_create[0].script", Value: "main.sh", File: ConfigFileName},
+		{Key: "hooks.post_create[1].script", Value: "mine.sh", File: LocalConfigFileName},
+	}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("settings = %+v\nwant %+v", settings, want)
+	}
+}
+
+func TestCheckLocatesProblemsInTheirFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		ConfigFileName:      "version: 1\n",
+		LocalConfigFileName: "# Mine\nbranch_pattern: mine\n",
+	})
+	_, problems, err := Check(dir)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0].File != LocalConfigFileName || problems[0].Line != 2 {
+		t.Errorf("expected one problem on line 2 of %s, got %+v", LocalConfigFileName, problems)
+	}
+}
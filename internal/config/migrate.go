@@ -0,0 +1,194 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// migration upgrades a config file's top-level mapping from one version to the next, in
+// place, and reports whether it changed anything
+type migration func(root *yaml.Node) (changed bool, err error)
+
+// migrations upgrade older config files as they're read: migrations[i] turns a
+// version i+1 file into version i+2. Appending one bumps CurrentVersion.
+var migrations []migration
+
+// CurrentVersion returns the config format version this build of wt reads and writes
+func CurrentVersion() int {
+	return len(migrations) + 1
+}
+
+// fileVersion returns the version a config file declares and the line declaring it.
+// A file without a version is taken to be current, as included files and
+// .wt.local.yaml usually leave it out.
+func fileVersion(root *yaml.Node) (version, line int) {
+	if i := mappingIndex(root, "version"); i >= 0 {
+		value := root.Content[i+1]
+		if v, err := strconv.Atoi(value.Value); err == nil && value.Kind == yaml.ScalarNode {
+			return v, value.Line
+		}
+		// A version that isn't a number is left for the decoder to report
+	}
+	return CurrentVersion(), 0
+}
+
+// checkVersion rejects versions this build of wt can't read
+func checkVersion(path string, version, line int) error {
+	var msg string
+	switch {
+	case version < 1:
+		msg = fmt.Sprintf("version %d is not valid (versions start at 1)", version)
+	case version > CurrentVersion():
+		msg = fmt.Sprintf("version %d is newer than this wt supports (%d); upgrade wt", version, CurrentVersion())
+	default:
+		return nil
+	}
+	return &ParseError{Path: path, Problems: []Problem{{Key: "version", File: path, Line: line, Message: msg}}}
+}
+
+// parseRoot parses a config file into its document node and top-level mapping.
+// root is nil for an empty file, or one that isn't a mapping.
+func parseRoot(data []byte) (doc *yaml.Node, root *yaml.Node, err error) {
+	doc = &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return doc, nil, nil
+	}
+	return doc, doc.Content[0], nil
+}
+
+// upgrade returns a config file's content migrated to the current version
+func upgrade(path string, data []byte) ([]byte, error) {
+	doc, root, err := parseRoot(data)
+	if err != nil || root == nil {
+		return data, nil // Left for the decoder to report
+	}
+	version, line := fileVersion(root)
+	if err := checkVersion(path, version, line); err != nil {
+		return nil, err
+	}
+	if version == CurrentVersion() {
+		return data, nil
+	}
+	out, err := migrateFile(data, doc, root, version)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+// migrateFile runs the migrations from a version up to the current one and returns
+// the file with its version updated to match. The file is only re-encoded if a
+// migration changed it; otherwise just the version number is replaced, leaving the
+// rest of the file byte for byte as it was.
+func migrateFile(data []byte, doc, root *yaml.Node, from int) ([]byte, error) {
+	i := mappingIndex(root, "version")
+	if i < 0 {
+		return data, nil // Files without a version are current
+	}
+	version := root.Content[i+1]
+	written := *version // Where the version was written, before migrating
+
+	changed := false
+	for v := from; v < CurrentVersion(); v++ {
+		c, err := migrations[v-1](root)
+		if err != nil {
+			return nil, fmt.Errorf("migrating from version %d: %w", v, err)
+		}
+		changed = changed || c
+	}
+	if changed {
+		version.Value = strconv.Itoa(CurrentVersion())
+		return encode(doc)
+	}
+	return replaceVersion(data, &written)
+}
+
+// replaceVersion replaces the version number a file was parsed with by the current version
+func replaceVersion(data []byte, version *yaml.Node) ([]byte, error) {
+	offset := 0
+	for line := 1; line < version.Line; line++ {
+		next := bytes.IndexByte(data[offset:], '\n')
+		if next < 0 {
+			break
+		}
+		offset += next + 1
+	}
+	offset += version.Column - 1
+	if offset > len(data) || !bytes.HasPrefix(data[offset:], []byte(version.Value)) {
+		return nil, fmt.Errorf("version %s not found at line %d", version.Value, version.Line)
+	}
+	return slices.Concat(data[:offset], []byte(strconv.Itoa(CurrentVersion())), data[offset+len(version.Value):]), nil
+}
+
+// encode writes a config document back out, keeping its comments
+func encode(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MigratedFile is a config file older than the current version
+type MigratedFile struct {
+	File string // Relative to the repository root when inside it
+	From int    // Version before migrating
+}
+
+// Migrate rewrites the repository's config files that are older than the current
+// version (.wt.yaml, its includes and .wt.local.yaml), keeping their comments.
+// Files no migration changes only have their version number updated, and current
+// files aren't touched. With dryRun the files are only reported.
+func Migrate(repoRoot string, dryRun bool) ([]MigratedFile, error) {
+	// Loading first checks every file can be read once migrated
+	m, err := loadMerged(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []MigratedFile
+	for _, path := range m.files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return migrated, err
+		}
+		doc, root, err := parseRoot(data)
+		if err != nil || root == nil {
+			continue
+		}
+		version, _ := fileVersion(root)
+		if version >= CurrentVersion() {
+			continue
+		}
+		migrated = append(migrated, MigratedFile{File: displayPath(repoRoot, path), From: version})
+		if dryRun {
+			continue
+		}
+
+		out, err := migrateFile(data, doc, root, version)
+		if err != nil {
+			return migrated, fmt.Errorf("%s: %w", displayPath(repoRoot, path), err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return migrated, err
+		}
+		if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+			return migrated, err
+		}
+	}
+	return migrated, nil
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// withMigration adds a migration for the length of a test
+func withMigration(t *testing.T, m migration) {
+	t.Helper()
+	old := migrations
+	migrations = append(slices.Clone(migrations), m)
+	t.Cleanup(func() { migrations = old })
+}
+
+// renameKey is a migration renaming a top-level key
+func renameKey(from, to string) migration {
+	return func(root *yaml.Node) (bool, error) {
+		i := mappingIndex(root, from)
+		if i >= 0 {
+			root.Content[i].Value = to
+		}
+		return i >= 0, nil
+	}
+}
+
+func TestLoadVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "current", content: "version: 1\n"},
+		{name: "missing", content: "worktree_dir: trees\n"},
+		{name: "newer", content: "# Config\nversion: 2\n", wantErr: "invalid .wt.yaml: line 2: version 2 is newer than this wt supports (1); upgrade wt"},
+		{name: "zero", content: "version: 0\n", wantErr: "version 0 is not valid"},
+		{name: "not a number", content: "version: one\n", wantErr: "cannot unmarshal !!str `one` into int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{ConfigFileName: tt.content})
+			cfg, err := Load(dir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Load failed: %v", err)
+				}
+				if cfg.Version != CurrentVersion() {
+					t.Errorf("Version = %d, want %d", cfg.Version, CurrentVersion())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// Included and local files are checked too
+	dir := writeFiles(t, map[string]string{ConfigFileName: "version: 1\n", LocalConfigFileName: "version: 3\n"})
+	var parseErr *ParseError
+	if _, err := Load(dir); !errors.As(err, &parseErr) || parseErr.Path != LocalConfigFileName {
+		t.Errorf("expected a version error in %s, got %v", LocalConfigFileName, err)
+	}
+}
+
+func TestLoadMigratesOlderVersions(t *testing.T) {
+	withMigration(t, renameKey("worktree_directory", "worktree_dir"))
+	if CurrentVersion() != 2 {
+		t.Fatalf("CurrentVersion() = %d, want 2", CurrentVersion())
+	}
+
+	dir := writeFiles(t, map[string]string{
+		ConfigFileName:      "version: 1\nworktree_directory: trees\n",
+		LocalConfigFileName: "version: 2\nbranch_pattern: me/{name}\n",
+	})
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Version != 2 || cfg.WorktreeDir != "trees" || cfg.BranchPattern != "me/{name}" {
+		t.Errorf("unexpected config after migrating: %+v", cfg)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	withMigration(t, renameKey("worktree_directory", "worktree_dir"))
+
+	original := `# Shared wt config
+version: 1 # Format version
+
+# Where worktrees go
+worktree_directory: trees
+hooks:
+  post_create:
+    - script: setup.sh # Installs dependencies
+`
+	dir := writeFiles(t, map[string]string{
+		ConfigFileName:      original,
+		LocalConfigFileName: "version: 2\n",
+		"setup.sh":          "true\n",
+	})
+
+	// A dry run reports the file without changing it
+	migrated, err := Migrate(dir, true)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if want := []MigratedFile{{File: ConfigFileName, From: 1}}; !reflect.DeepEqual(migrated, want) {
+		t.Errorf("migrated = %+v, want %+v", migrated, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ConfigFileName)); string(data) != original {
+		t.Errorf("dry run changed the file:\n%s", data)
+	}
+
+	if _, err := Migrate(dir, false); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ConfigFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Shared wt config\n",
+		"version: 2 # Format version\n",
+		"# Where worktrees go\nworktree_dir: trees\n",
+		"    - script: setup.sh # Installs dependencies\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in the migrated file:\n%s", want, data)
+		}
+	}
+
+	// Migrating again finds nothing to do, and leaves the files alone
+	path := filepath.Join(dir, ConfigFileName)
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err = Migrate(dir, false)
+	if err != nil || len(migrated) != 0 {
+		t.Errorf("expected nothing left to migrate, got %+v, %v", migrated, err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("expected %s not to be rewritten", ConfigFileName)
+	}
+	if again, _ := os.ReadFile(path); string(again) != string(data) {
+		t.Errorf("file changed without a migration:\n%s", again)
+	}
+}
+
+func TestMigrateOnlyBumpsVersion(t *testing.T) {
+	// A migration that doesn't apply to a file leaves everything but its version alone
+	withMigration(t, renameKey("worktree_directory", "worktree_dir"))
+
+	for _, tt := range []struct{ original, want string }{
+		{"# Shared\nversion:   1   # Format\nworktree_dir:   'trees'\n", "# Shared\nversion:   2   # Format\nworktree_dir:   'trees'\n"},
+		{"worktree_dir: trees\nversion: 1", "worktree_dir: trees\nversion: 2"},
+	} {
+		dir := writeFiles(t, map[string]string{ConfigFileName: tt.original})
+		migrated, err := Migrate(dir, false)
+		if err != nil {
+			t.Fatalf("Migrate failed: %v", err)
+		}
+		if want := []MigratedFile{{File: ConfigFileName, From: 1}}; !reflect.DeepEqual(migrated, want) {
+			t.Errorf("migrated = %+v, want %+v", migrated, want)
+		}
+		if data, _ := os.ReadFile(filepath.Join(dir, ConfigFileName)); string(data) != tt.want {
+			t.Errorf("migrated file = %q, want %q", data, tt.want)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed schema.json
+var schemaJSON []byte
+
+// Schema returns the JSON Schema for .wt.yaml
+func Schema() []byte {
+	return schemaJSON
+}
+
+// Problem is a mistake found in a config file
+type Problem struct {
+	Key     string // Dotted path to the value, e.g. "hooks.post_create.0.script"; may be empty
+	File    string // Config file the value came from, relative to the repo root
+	Line    int    // Line in the file, or 0 if unknown
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+	}
+	return p.Message
+}
+
+// ParseError reports the problems that stopped a config file from being decoded
+type ParseError struct {
+	Path     string
+	Problems []Problem
+}
+
+func (e *ParseError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.String()
+	}
+	return fmt.Sprintf("invalid %s: %s", e.Path, strings.Join(msgs, "; "))
+}
+
+// decode strictly decodes a config file into cfg: unknown keys and wrong types are errors
+func decode(path string, data []byte, cfg *Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err := dec.Decode(cfg)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+	problems := decodeProblems(err)
+	for i := range problems {
+		problems[i].File = path
+	}
+	return &ParseError{Path: path, Problems: problems}
+}
+
+var (
+	// yaml.v3 reports each decoding error as "line N: message"
+	yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	// and unknown keys as "field post_creat not found in type config.HooksConfig"
+	unknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type config\.(\w+)$`)
+)
+
+// decodeProblems turns a yaml.v3 error into problems with line numbers
+func decodeProblems(err error) []Problem {
+	var msgs []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+
+	problems := make([]Problem, 0, len(msgs))
+	for _, msg := range msgs {
+		var p Problem
+		if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+		p.Message = strings.TrimPrefix(msg, "yaml: ")
+		if m := unknownFieldPattern.FindStringSubmatch(p.Message); m != nil {
+			p.Message = fmt.Sprintf("unknown key %q", m[1])
+			if s := suggestKey(m[1], knownKeys(m[2])); s != "" {
+				p.Message += fmt.Sprintf(" (did you mean %q?)", s)
+			}
+		}
+		problems = append(problems, p)
+	}
+	return problems
+}
+
+// knownKeys returns the YAML keys of a config struct type, found by name
+func knownKeys(typeName string) []string {
+	var keys []string
+	seen := map[reflect.Type]bool{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Slice || t.Kind() == reflect.Map || t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || seen[t] {
+			return
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if t.Name() == typeName {
+				keys = append(keys, yamlKey(field))
+			}
+			walk(field.Type)
+		}
+	}
+	walk(reflect.TypeOf(Config{}))
+	return keys
+}
+
+// yamlKey returns the key a struct field is decoded from
+func yamlKey(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// suggestKey returns the known key closest to a misspelt one, if any is close enough
+func suggestKey(key string, known []string) string {
+	best, bestDist := "", 3
+	for _, k := range known {
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Validator checks a decoded config for problems the config package can't see itself
+type Validator func(cfg *Config) []Problem
+
+// Check loads the repository config like Load, then checks the values make sense
+// with Validate and any extra validators, filling in the file and line of each
+// problem's key. A file that can't be decoded is reported as problems, with a nil
+// config; the returned error is for files that can't be read or merged.
+func Check(repoRoot string, extra ...Validator) (*Config, []Problem, error) {
+	m, err := loadMerged(repoRoot)
+	if err != nil {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.Problems, nil
+		}
+		return nil, nil, err
+	}
+
+	cfg := defaultConfigFor(repoRoot)
+	if err := decode(ConfigFileName, m.data, cfg); err != nil {
+		return nil, nil, err
+	}
+	applyDefaults(cfg)
+
+	var problems []Problem
+	if err := resolveWorktreeDir(cfg, repoRoot); err != nil {
+		problems = append(problems, Problem{Key: "worktree_dir", Message: err.Error()})
+	}
+	problems = append(problems, Validate(repoRoot, cfg)...)
+	for _, v := range extra {
+		problems = append(problems, v(cfg)...)
+	}
+
+	for i := range problems {
+		if problems[i].Line == 0 && problems[i].Key != "" {
+			problems[i].File, problems[i].Line = m.locate(problems[i].Key)
+		}
+		if problems[i].File == "" {
+			problems[i].File = ConfigFileName
+		}
+	}
+	return cfg, problems, nil
+}
+
+// Validate checks the values of a decoded config
+func Validate(repoRoot string, cfg *Config) []Problem {
+	var problems []Problem
+	add := func(key string, format string, args ...any) {
+		problems = append(problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if err := checkWorktreesDir(cfg.WorktreesDir(repoRoot), repoRoot); err != nil {
+		add("worktree_dir", "%v", err)
+	}
+
+	if placeholders, err := parseBranchPattern(cfg.BranchPattern); err != nil {
+		add("branch_pattern", "branch_pattern %q: %v", cfg.BranchPattern, err)
+	} else if !usesName(placeholders) {
+		add("branch_pattern", "branch_pattern %q must contain {name}", cfg.BranchPattern)
+	}
+
+	if cfg.Index.Max < 0 {
+		add("index.max", "index.max must not be negative")
+	}
+	for i, r := range cfg.Index.Reserved {
+		if r < 1 {
+			add(fmt.Sprintf("index.reserved.%d", i), "index.reserved: %d is not a valid index (indexes start at 1)", r)
+		} else if cfg.Index.Max > 0 && r > cfg.Index.Max {
+			add(fmt.Sprintf("index.reserved.%d", i), "index.reserved: %d is above index.max (%d)", r, cfg.Index.Max)
+		}
+	}
+	if cfg.Index.Max > 0 && reservedCount(cfg.Index) >= cfg.Index.Max {
+		add("index.reserved", "index.reserved leaves no index free up to index.max (%d)", cfg.Index.Max)
+	}
+
+	if cfg.Logs.MaxFiles < 0 {
+		add("logs.max_files", "logs.max_files must not be negative")
+	}
+	if cfg.Logs.MaxBytes < 0 {
+		add("logs.max_bytes", "logs.max_bytes must not be negative")
+	}
+
+	for i, dir := range cfg.CloneDirs {
+		key := fmt.Sprintf("clone_dirs.%d", i)
+		if dir.Path == "" {
+			add(key, "clone_dirs[%d]: path is required", i)
+		}
+		if m := dir.Method; m != "" && m != CloneMethodCopy && m != CloneMethodHardlink {
+			add(key+".method", "clone_dirs[%d]: method must be %q or %q, not %q", i, CloneMethodCopy, CloneMethodHardlink, m)
+		}
+	}
+
+	if d := cfg.MergeDetection; d != "" && d != MergeDetectionRemoval && d != MergeDetectionStatus {
+		add("merge_detection", "merge_detection must be %q or %q, not %q", MergeDetectionRemoval, MergeDetectionStatus, d)
+	}
+
+	for _, event := range cfg.Hooks.byKey() {
+		for i, entry := range event.entries {
+			key := fmt.Sprintf("hooks.%s.%d", event.key, i)
+			label := fmt.Sprintf("hooks.%s[%d]", event.key, i)
+			if entry.Script == "" {
+				add(key, "%s: script is required", label)
+				continue
+			}
+			script := entry.Script
+			if !filepath.IsAbs(script) {
+				script = filepath.Join(repoRoot, script)
+			}
+			if info, err := os.Stat(script); err != nil {
+				add(key+".script", "%s: script %s not found", label, entry.Script)
+			} else if info.IsDir() {
+				add(key+".script", "%s: script %s is a directory", label, entry.Script)
+			}
+
+			if event.key != "info" {
+				for _, f := range []struct{ field, value string }{{"format", entry.Format}, {"timeout", entry.Timeout}, {"cache_ttl", entry.CacheTTL}} {
+					if field, value := f.field, f.value; value != "" {
+						add(key+"."+field, "%s: %s only applies to info hooks", label, field)
+					}
+				}
+				continue
+			}
+			if entry.Format != "" && entry.Format != "text" && entry.Format != "json" {
+				add(key+".format", "%s: format must be \"text\" or \"json\", not %q", label, entry.Format)
+			}
+			for _, f := range []struct{ field, value string }{{"timeout", entry.Timeout}, {"cache_ttl", entry.CacheTTL}} {
+				if field, value := f.field, f.value; value != "" && !validDuration(value) {
+					add(key+"."+field, "%s: %s %q is not a duration like \"5s\" or \"1m\"", label, field, value)
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// validDuration reports whether s parses as a duration, as hook timeouts are
+func validDuration(s string) bool {
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
+
+// reservedCount counts the distinct valid reserved indexes
+func reservedCount(idx IndexConfig) int {
+	seen := map[int]bool{}
+	for _, r := range idx.Reserved {
+		if r >= 1 && (idx.Max == 0 || r <= idx.Max) {
+			seen[r] = true
+		}
+	}
+	return len(seen)
+}
+
+// hookList is the hooks configured for one event, by its YAML key
+type hookList struct {
+	key     string
+	entries []HookEntry
+}
+
+// byKey lists the hooks for each event in the order they appear in the config type
+func (h *HooksConfig) byKey() []hookList {
+	v := reflect.ValueOf(h).Elem()
+	lists := make([]hookList, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		lists = append(lists, hookList{key: yamlKey(v.Type().Field(i)), entries: v.Field(i).Interface().([]HookEntry)})
+	}
+	return lists
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return dir
+}
+
+func TestLoadStrict(t *testing.T) {
+	tests := []struct {
+		name       string
+		configYAML string
+		want       []string // Problem strings
+	}{
+		{
+			name:       "unknown top-level key",
+			configYAML: "version: 1\nworktre_dir: x\n",
+			want:       []string{`line 2: unknown key "worktre_dir" (did you mean "worktree_dir"?)`},
+		},
+		{
+			name:       "unknown hook event",
+			configYAML: "hooks:\n  post_creat:\n    - script: a.sh\n",
+			want:       []string{`line 2: unknown key "post_creat" (did you mean "post_create"?)`},
+		},
+		{
+			name:       "unknown key without a close match",
+			configYAML: "hooks:\n  post_create:\n    - script: a.sh\n      something: 1\n",
+			want:       []string{`line 4: unknown key "something"`},
+		},
+		{
+			name:       "unknown key in a port mapping",
+			configYAML: "resources:\n  web:\n    port: 3000\n    cnt: 2\n",
+			want:       []string{`line 4: unknown key "cnt" (did you mean "count"?)`},
+		},
+		{
+			name:       "unknown key in a clone_dirs mapping",
+			configYAML: "clone_dirs:\n  - path: target\n    mehtod: hardlink\n",
+			want:       []string{`line 3: unknown key "mehtod" (did you mean "method"?)`},
+		},
+		{
+			name:       "wrong type",
+			configYAML: "index:\n  max: lots\n",
+			want:       []string{"line 2: cannot unmarshal !!str `lots` into int"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.configYAML))
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a ParseError, got %v", err)
+			}
+			var got []string
+			for _, p := range parseErr.Problems {
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("problems = %q, want %q", got, tt.want)
+			}
+			if !strings.HasPrefix(err.Error(), "invalid .wt.yaml: line ") {
+				t.Errorf("error should name the file and line, got %q", err)
+			}
+		})
+	}
+}
+
+func TestLoadEmpty(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("empty config should load: %v", err)
+	}
+	if cfg.WorktreeDir != "worktrees" {
+		t.Errorf("expected default worktree_dir, got %q", cfg.WorktreeDir)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := writeConfig(t, `version: 1
+branch_pattern: feature/x
+index:
+  max: 2
+  reserved: [0, 1, 2]
+logs:
+  max_files: -1
+merge_detection: always
+hooks:
+  post_create:
+    - script: exists.sh
+    - script: missing.sh
+      format: json
+  info:
+    - script: exists.sh
+      format: xml
+      timeout: soon
+clone_dirs:
+  - node_modules
+  - path: target
+    method: symlink
+`)
+	if err := os.WriteFile(filepath.Join(dir, "exists.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	extra := func(cfg *Config) []Problem {
+		return []Problem{{Key: "hooks.info.0.script", Message: "extra check"}}
+	}
+	cfg, problems, err := Check(dir, extra)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if cfg == nil {
+		t.Fatal("expected the decoded config")
+	}
+
+	want := []string{
+		`line 2: branch_pattern "feature/x" must contain {name}`,
+		"line 5: index.reserved: 0 is not a valid index (indexes start at 1)",
+		"line 5: index.reserved leaves no index free up to index.max (2)",
+		"line 7: logs.max_files must not be negative",
+		`line 21: clone_dirs[1]: method must be "copy" or "hardlink", not "symlink"`,
+		`line 8: merge_detection must be "removal" or "status", not "always"`,
+		"line 12: hooks.post_create[1]: script missing.sh not found",
+		"line 13: hooks.post_create[1]: format only applies to info hooks",
+		`line 16: hooks.info[0]: format must be "text" or "json", not "xml"`,
+		`line 17: hooks.info[0]: timeout "soon" is not a duration like "5s" or "1m"`,
+		"line 15: extra check",
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckValid(t *testing.T) {
+	_, problems, err := Check(writeConfig(t, "version: 1\nworktree_dir: trees\nbranch_pattern: \"feature/{name}\"\n"))
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+}
+
+func TestCheckParseError(t *testing.T) {
+	cfg, problems, err := Check(writeConfig(t, "version: 1\nhookz: {}\n"))
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if cfg != nil {
+		t.Error("expected no config for a file that can't be decoded")
+	}
+	if len(problems) != 1 || problems[0].Line != 2 {
+		t.Errorf("expected one problem on line 2, got %v", problems)
+	}
+}
+
+func TestSchemaCoversConfig(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(Schema(), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	defs, _ := schema["$defs"].(map[string]any)
+
+	// resolve follows a $ref to its definition, and a oneOf to its object form
+	resolve := func(s map[string]any) map[string]any {
+		if ref, ok := s["$ref"].(string); ok {
+			def, _ := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+			return def
+		}
+		if oneOf, ok := s["oneOf"].([]any); ok {
+			for _, alt := range oneOf {
+				if alt, _ := alt.(map[string]any); alt["type"] == "object" {
+					return alt
+				}
+			}
+		}
+		return s
+	}
+
+	// Every field of each struct must be a property of the matching schema object
+	var walk func(typ reflect.Type, s map[string]any, path string)
+	walk = func(typ reflect.Type, s map[string]any, path string) {
+		s = resolve(s)
+		for typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+			items, _ := s["items"].(map[string]any)
+			s = resolve(items)
+		}
+		if typ.Kind() != reflect.Struct || typ == reflect.TypeOf(PortConfig{}) {
+			return
+		}
+		props, _ := s["properties"].(map[string]any)
+		for i := 0; i < typ.NumField(); i++ {
+			key := yamlKey(typ.Field(i))
+			prop, ok := props[key].(map[string]any)
+			if !ok {
+				t.Errorf("schema is missing %s", path+key)
+				continue
+			}
+			walk(typ.Field(i).Type, prop, path+key+".")
+		}
+		if len(props) != typ.NumField() {
+			t.Errorf("schema for %q has %d properties, the config type has %d fields", path, len(props), typ.NumField())
+		}
+	}
+	walk(reflect.TypeOf(Config{}), schema, "")
+
+	version, _ := schema["properties"].(map[string]any)["version"].(map[string]any)
+	if v, _ := version["const"].(float64); int(v) != CurrentVersion() {
+		t.Errorf("schema version is %v, the current version is %d", version["const"], CurrentVersion())
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/agarcher/wt/internal/git"
+)
+
+// xdgDefaults are the XDG base directories, relative to the home directory, used
+// when their variable isn't set
+var xdgDefaults = map[string]string{
+	"XDG_DATA_HOME":  ".local/share",
+	"XDG_STATE_HOME": ".local/state",
+	"XDG_CACHE_HOME": ".cache",
+}
+
+var (
+	// worktreeDirVariable matches $VAR and ${VAR} in worktree_dir; any other $ is literal
+	worktreeDirVariable = regexp.MustCompile(`\$(?:([A-Za-z_][A-Za-z0-9_]*)|\{([A-Za-z_][A-Za-z0-9_]*)\})`)
+	// worktreeDirPlaceholder matches {...} placeholders in worktree_dir
+	worktreeDirPlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+)
+
+// expandWorktreeDir resolves worktree_dir to an absolute, clean path:
+//
+//	~, ~/...          the home directory
+//	$VAR, ${VAR}      environment variables, which must be set (XDG_DATA_HOME,
+//	                  XDG_STATE_HOME and XDG_CACHE_HOME default as the spec says);
+//	                  a $ not starting a variable name is kept as is
+//	{repo}            the repository directory's name
+//
+// Relative paths are relative to the repository root.
+func expandWorktreeDir(dir, repoRoot string) (string, error) {
+	// Environment variables first, so ${VAR} isn't taken for a placeholder
+	var home string
+	homeDir := func() string {
+		if home == "" {
+			home, _ = os.UserHomeDir()
+		}
+		return home
+	}
+
+	var unset []string
+	dir = worktreeDirVariable.ReplaceAllStringFunc(dir, func(match string) string {
+		m := worktreeDirVariable.FindStringSubmatch(match)
+		name := m[1] + m[2]
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		if rel, ok := xdgDefaults[name]; ok && homeDir() != "" {
+			return filepath.Join(homeDir(), rel)
+		}
+		if !slices.Contains(unset, "$"+name) {
+			unset = append(unset, "$"+name)
+		}
+		return match
+	})
+	if len(unset) == 1 {
+		return "", fmt.Errorf("%s is not set", unset[0])
+	}
+	if len(unset) > 1 {
+		return "", fmt.Errorf("%s are not set", strings.Join(unset, ", "))
+	}
+
+	var unknown []string
+	dir = worktreeDirPlaceholder.ReplaceAllStringFunc(dir, func(match string) string {
+		if strings.TrimSpace(match[1:len(match)-1]) == "repo" {
+			return filepath.Base(repoRoot)
+		}
+		unknown = append(unknown, match)
+		return match
+	})
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown variable %s (only {repo} is supported)", unknown[0])
+	}
+
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if homeDir() == "" {
+			return "", fmt.Errorf("can't expand ~: no home directory")
+		}
+		dir = filepath.Join(homeDir(), dir[1:])
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoRoot, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// resolveWorktreeDir expands cfg.WorktreeDir in place, leaving it relative to the
+// repository root when it's inside it and absolute otherwise
+func resolveWorktreeDir(cfg *Config, repoRoot string) error {
+	dir, err := expandWorktreeDir(cfg.WorktreeDir, repoRoot)
+	if err != nil {
+		return fmt.Errorf("worktree_dir %q: %w", cfg.WorktreeDir, err)
+	}
+	rel, err := filepath.Rel(repoRoot, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		cfg.WorktreeDir = dir
+	} else {
+		cfg.WorktreeDir = rel
+	}
+	return nil
+}
+
+// WorktreesDir returns the absolute directory worktrees are created in
+func (c *Config) WorktreesDir(repoRoot string) string {
+	if filepath.IsAbs(c.WorktreeDir) {
+		return c.WorktreeDir
+	}
+	return filepath.Join(repoRoot, c.WorktreeDir)
+}
+
+// checkWorktreesDir reports a worktree directory that is the repository root or
+// contains it, where every directory of the checkout would look like a worktree. In
+// a bare repository, whose root is a worktree itself, it may contain the root but
+// must be outside the git dir.
+func checkWorktreesDir(worktreesDir, repoRoot string) error {
+	if git.IsGitDir(repoRoot) {
+		if isWithin(repoRoot, worktreesDir) {
+			return fmt.Errorf("worktree_dir %s must be outside the bare repository", worktreesDir)
+		}
+		return nil
+	}
+	if worktreesDir == repoRoot || (isWithin(worktreesDir, repoRoot) && !git.IsBareRepository(repoRoot)) {
+		return fmt.Errorf("worktree_dir %s must not contain the repository", worktreesDir)
+	}
+	return nil
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveWorktreeDir(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("WT_TEST_DIR", "/srv/trees")
+
+	tests := []struct {
+		dir     string
+		want    string
+		wantErr string
+	}{
+		{dir: "worktrees", want: "worktrees"},
+		{dir: "./.worktrees/", want: ".worktrees"},
+		{dir: "../{repo}-worktrees", want: "/src/myrepo-worktrees"},
+		{dir: "~", want: "/home/me"},
+		{dir: "~/worktrees/{ repo }", want: "/home/me/worktrees/myrepo"},
+		{dir: "$XDG_DATA_HOME/wt/{repo}", want: "/home/me/.local/share/wt/myrepo"},
+		{dir: "${WT_TEST_DIR}/{repo}", want: "/srv/trees/myrepo"},
+		{dir: "/abs/trees", want: "/abs/trees"},
+		{dir: "/src/myrepo/inside", want: "inside"},
+		{dir: "/srv/$/trees$", want: "/srv/$/trees$"},
+		{dir: "/srv/price$5/a$-b", want: "/srv/price$5/a$-b"},
+		{dir: "$WT_TEST_UNSET/trees", wantErr: `worktree_dir "$WT_TEST_UNSET/trees": $WT_TEST_UNSET is not set`},
+		{dir: "$WT_TEST_UNSET/${WT_TEST_UNSET2}/$WT_TEST_UNSET", wantErr: "$WT_TEST_UNSET, $WT_TEST_UNSET2 are not set"},
+		{dir: "{name}", wantErr: "unknown variable {name} (only {repo} is supported)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			cfg := &Config{WorktreeDir: tt.dir}
+			err := resolveWorktreeDir(cfg, "/src/myrepo")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveWorktreeDir failed: %v", err)
+			}
+			if cfg.WorktreeDir != tt.want {
+				t.Errorf("WorktreeDir = %q, want %q", cfg.WorktreeDir, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorktreesDir(t *testing.T) {
+	if got := (&Config{WorktreeDir: "worktrees"}).WorktreesDir("/repo"); got != "/repo/worktrees" {
+		t.Errorf("relative: got %q", got)
+	}
+	if got := (&Config{WorktreeDir: "/elsewhere/trees"}).WorktreesDir("/repo"); got != "/elsewhere/trees" {
+		t.Errorf("absolute: got %q", got)
+	}
+}
+
+func TestValidateWorktreeDirContainingRepo(t *testing.T) {
+	for _, dir := range []string{".", "..", "/"} {
+		cfg := DefaultConfig()
+		cfg.WorktreeDir = dir
+		problems := Validate("/src/myrepo", cfg)
+		if len(problems) == 0 || !strings.Contains(problems[0].Message, "must not contain the repository") {
+			t.Errorf("worktree_dir %q: expected a problem, got %+v", dir, problems)
+		}
+	}
+}
@@ -52,6 +52,17 @@ func RemoveWorktree(repoRoot, worktreePath string, force bool) error {
 	return cmd.Run()
 }
 
+// RemoveEmptyParents removes the directories between a removed worktree and
+// worktreesDir that are left empty, e.g. "feature" after deleting "feature/foo"
+func RemoveEmptyParents(worktreesDir, worktreePath string) {
+	for dir := filepath.Dir(worktreePath); IsInsideWorktree(worktreesDir, dir); dir = filepath.Dir(dir) {
+		// os.Remove fails on a directory that isn't empty
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
 // ListWorktrees returns all worktrees for a repository
 func ListWorktrees(repoRoot string) ([]Worktree, error) {
 	cmd := exec.Command("git", "worktree", "list", "--porcelain")
@@ -101,6 +112,26 @@ func BranchExists(repoRoot, branchName string) bool {
 	return cmd.Run() == nil
 }
 
+// CheckBranchName returns an error if name isn't a valid branch name, by git's rules
+func CheckBranchName(name string) error {
+	cmd := exec.Command("git", "check-ref-format", "--branch", name)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%q is not a valid branch name", name)
+	}
+	return nil
+}
+
+// GetUserName returns the repository's git user.name, or "" if it isn't set
+func GetUserName(repoRoot string) string {
+	cmd := exec.Command("git", "config", "user.name")
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
 // GetCurrentBranch returns the current branch name
 func GetCurrentBranch(repoRoot string) (string, error) {
 	cmd := exec.Command("git", "branch", "--show-current")
@@ -161,13 +192,20 @@ func HasUnpushedCommits(path string) (bool, error) {
 	return count != "0", nil
 }
 
-// GetWorktreeName extracts the worktree name from a path
-func GetWorktreeName(repoRoot, worktreePath, worktreeDir string) string {
-	worktreesPath := filepath.Join(repoRoot, worktreeDir)
-	rel, err := filepath.Rel(worktreesPath, worktreePath)
+// GetWorktreeName extracts the worktree name from a path inside worktreesDir, the
+// absolute directory worktrees are created in. Names can contain slashes, e.g.
+// "feature/foo", so the name is the path of the nearest enclosing worktree checkout,
+// or the first path component when there is none.
+func GetWorktreeName(worktreesDir, worktreePath string) string {
+	rel, err := filepath.Rel(worktreesDir, worktreePath)
 	if err != nil {
 		return filepath.Base(worktreePath)
 	}
+	for dir := rel; dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if IsWorktreeCheckout(filepath.Join(worktreesDir, dir)) {
+			return filepath.ToSlash(dir)
+		}
+	}
 	// Get the first component of the relative path
 	parts := strings.Split(rel, string(filepath.Separator))
 	if len(parts) > 0 {
@@ -176,14 +214,21 @@ func GetWorktreeName(repoRoot, worktreePath, worktreeDir string) string {
 	return filepath.Base(worktreePath)
 }
 
-// IsInsideWorktree checks if the given path is inside a worktree directory
-func IsInsideWorktree(repoRoot, path, worktreeDir string) bool {
-	worktreesPath := filepath.Join(repoRoot, worktreeDir)
-	rel, err := filepath.Rel(worktreesPath, path)
+// IsWorktreeCheckout reports whether path is the top of a git checkout, as opposed to
+// a directory inside one or a directory grouping nested worktree names
+func IsWorktreeCheckout(path string) bool {
+	_, err := os.Lstat(filepath.Join(path, ".git"))
+	return err == nil
+}
+
+// IsInsideWorktree checks if the given path is inside a worktree in worktreesDir,
+// the absolute directory worktrees are created in
+func IsInsideWorktree(worktreesDir, path string) bool {
+	rel, err := filepath.Rel(worktreesDir, path)
 	if err != nil {
 		return false
 	}
-	return !strings.HasPrefix(rel, "..") && rel != "."
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
 // PruneWorktrees cleans up stale worktree references
@@ -224,6 +269,32 @@ func FetchRemoteQuiet(repoRoot, remote string) error {
 	return cmd.Run()
 }
 
+// Clone clones url into dir, as a bare repository if bare is set. Its output goes
+// to stderr, as stdout carries paths for the shell wrapper. A bare clone is given
+// remote-tracking branches, which git only sets up for a normal clone.
+func Clone(url, dir string, bare bool) error {
+	args := []string{"clone", url, dir}
+	if bare {
+		args = []string{"clone", "--bare", url, dir}
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil || !bare {
+		return err
+	}
+
+	cmd = exec.Command("git", "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	if err := FetchRemoteQuiet(dir, "origin"); err != nil {
+		return err
+	}
+	return UpdateRemoteHead(dir, "origin")
+}
+
 // RefExists checks if a git ref (branch, tag, or remote ref) exists
 func RefExists(repoRoot, ref string) bool {
 	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref)
@@ -440,71 +511,6 @@ func matchesBranchName(line, branchName string) bool {
 	return strings.Contains(line, pattern)
 }
 
-// SetWorktreeCreatedAt stores the creation timestamp in the worktree's git config
-func SetWorktreeCreatedAt(repoRoot, worktreeName string, timestamp time.Time) error {
-	configPath := filepath.Join(repoRoot, ".git", "worktrees", worktreeName, "config")
-
-	// Verify the worktree directory exists (git will create the config file)
-	worktreeDir := filepath.Dir(configPath)
-	if _, err := os.Stat(worktreeDir); os.IsNotExist(err) {
-		return fmt.Errorf("worktree directory not found: %s", worktreeDir)
-	}
-
-	cmd := exec.Command("git", "config", "--file", configPath, "wt.createdAt", strconv.FormatInt(timestamp.Unix(), 10))
-	cmd.Dir = repoRoot
-	return cmd.Run()
-}
-
-// GetWorktreeCreatedAt retrieves the creation timestamp from the worktree's git config
-func GetWorktreeCreatedAt(repoRoot, worktreeName string) (time.Time, error) {
-	configPath := filepath.Join(repoRoot, ".git", "worktrees", worktreeName, "config")
-
-	cmd := exec.Command("git", "config", "--file", configPath, "--get", "wt.createdAt")
-	cmd.Dir = repoRoot
-
-	output, err := cmd.Output()
-	if err != nil {
-		return time.Time{}, nil // Not set, return zero time
-	}
-
-	timestamp, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
-	if err != nil {
-		return time.Time{}, nil
-	}
-
-	return time.Unix(timestamp, 0), nil
-}
-
-// SetWorktreeInitialCommit stores the initial commit SHA in the worktree's git config
-func SetWorktreeInitialCommit(repoRoot, worktreeName, commitSHA string) error {
-	configPath := filepath.Join(repoRoot, ".git", "worktrees", worktreeName, "config")
-
-	// Verify the worktree directory exists (git will create the config file)
-	worktreeDir := filepath.Dir(configPath)
-	if _, err := os.Stat(worktreeDir); os.IsNotExist(err) {
-		return fmt.Errorf("worktree directory not found: %s", worktreeDir)
-	}
-
-	cmd := exec.Command("git", "config", "--file", configPath, "wt.initialCommit", commitSHA)
-	cmd.Dir = repoRoot
-	return cmd.Run()
-}
-
-// GetWorktreeInitialCommit retrieves the initial commit SHA from the worktree's git config
-func GetWorktreeInitialCommit(repoRoot, worktreeName string) (string, error) {
-	configPath := filepath.Join(repoRoot, ".git", "worktrees", worktreeName, "config")
-
-	cmd := exec.Command("git", "config", "--file", configPath, "--get", "wt.initialCommit")
-	cmd.Dir = repoRoot
-
-	output, err := cmd.Output()
-	if err != nil {
-		return "", nil // Not set, return empty string
-	}
-
-	return strings.TrimSpace(string(output)), nil
-}
-
 // GetCurrentCommit returns the current HEAD commit SHA for a path
 func GetCurrentCommit(path string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "HEAD")
@@ -547,72 +553,20 @@ func GetWorktreeStatus(repoRoot, worktreePath, worktreeName, branchName, mainBra
 	}
 
 	// Check if still on initial commit (new worktree with no changes committed)
-	initialCommit, _ := GetWorktreeInitialCommit(repoRoot, worktreeName)
+	meta := OpenMetadata(repoRoot)
+	initialCommit, _ := meta.InitialCommit(worktreeName)
 	if initialCommit != "" {
 		currentCommit, _ := GetCurrentCommit(worktreePath)
 		status.IsNew = (currentCommit == initialCommit)
 	}
 
 	// Get creation time
-	createdAt, _ := GetWorktreeCreatedAt(repoRoot, worktreeName)
+	createdAt, _ := meta.CreatedAt(worktreeName)
 	status.CreatedAt = createdAt
 
 	// Get worktree index
-	index, _ := GetWorktreeIndex(repoRoot, worktreeName)
+	index, _ := meta.Index(worktreeName)
 	status.Index = index
 
 	return status, nil
 }
-
-// SetWorktreeIndex stores the index in the worktree's metadata directory
-func SetWorktreeIndex(repoRoot, worktreeName string, index int) error {
-	indexPath := filepath.Join(repoRoot, ".git", "worktrees", worktreeName, "wt-index")
-
-	// Verify the worktree directory exists
-	worktreeDir := filepath.Dir(indexPath)
-	if _, err := os.Stat(worktreeDir); os.IsNotExist(err) {
-		return fmt.Errorf("worktree directory not found: %s", worktreeDir)
-	}
-
-	return os.WriteFile(indexPath, []byte(strconv.Itoa(index)+"\n"), 0644)
-}
-
-// GetWorktreeIndex retrieves the index from the worktree's metadata directory
-func GetWorktreeIndex(repoRoot, worktreeName string) (int, error) {
-	indexPath := filepath.Join(repoRoot, ".git", "worktrees", worktreeName, "wt-index")
-
-	data, err := os.ReadFile(indexPath)
-	if err != nil {
-		return 0, err
-	}
-
-	return strconv.Atoi(strings.TrimSpace(string(data)))
-}
-
-// AllocateIndex finds the lowest unused index for a new worktree
-func AllocateIndex(repoRoot string, maxIndex int) (int, error) {
-	used := make(map[int]bool)
-
-	// Scan all existing worktree indexes
-	worktreesDir := filepath.Join(repoRoot, ".git", "worktrees")
-	entries, err := os.ReadDir(worktreesDir)
-	if err != nil && !os.IsNotExist(err) {
-		return 0, err
-	}
-
-	for _, entry := range entries {
-		if idx, err := GetWorktreeIndex(repoRoot, entry.Name()); err == nil {
-			used[idx] = true
-		}
-	}
-
-	// Find lowest unused (starting at 1, reserve 0 for main repo)
-	for i := 1; ; i++ {
-		if maxIndex > 0 && i > maxIndex {
-			return 0, fmt.Errorf("no available index: all indexes 1-%d are in use", maxIndex)
-		}
-		if !used[i] {
-			return i, nil
-		}
-	}
-}
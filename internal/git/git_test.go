@@ -167,6 +167,19 @@ func TestBranchExists(t *testing.T) {
 	}
 }
 
+func TestCheckBranchName(t *testing.T) {
+	for _, name := range []string{"feature-x", "jane/2026-10-16-topic", "fix.login"} {
+		if err := CheckBranchName(name); err != nil {
+			t.Errorf("expected %q to be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "a..b", "a b", "topic.lock", "a/", "-x", "a~1"} {
+		if err := CheckBranchName(name); err == nil {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+}
+
 func TestGetCurrentBranch(t *testing.T) {
 	repoRoot, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -213,37 +226,39 @@ func TestHasUncommittedChanges(t *testing.T) {
 func TestGetWorktreeName(t *testing.T) {
 	tests := []struct {
 		name         string
-		repoRoot     string
+		worktreesDir string
 		worktreePath string
-		worktreeDir  string
 		expected     string
 	}{
 		{
 			name:         "simple path",
-			repoRoot:     "/repo",
+			worktreesDir: "/repo/worktrees",
 			worktreePath: "/repo/worktrees/feature-x",
-			worktreeDir:  "worktrees",
 			expected:     "feature-x",
 		},
 		{
 			name:         "nested path",
-			repoRoot:     "/repo",
+			worktreesDir: "/repo/worktrees",
 			worktreePath: "/repo/worktrees/feature-x/src",
-			worktreeDir:  "worktrees",
 			expected:     "feature-x",
 		},
 		{
 			name:         "custom worktree dir",
-			repoRoot:     "/repo",
+			worktreesDir: "/repo/.wt",
 			worktreePath: "/repo/.wt/my-feature",
-			worktreeDir:  ".wt",
+			expected:     "my-feature",
+		},
+		{
+			name:         "outside the repository",
+			worktreesDir: "/home/me/repo-worktrees",
+			worktreePath: "/home/me/repo-worktrees/my-feature",
 			expected:     "my-feature",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := GetWorktreeName(tt.repoRoot, tt.worktreePath, tt.worktreeDir)
+			got := GetWorktreeName(tt.worktreesDir, tt.worktreePath)
 			if got != tt.expected {
 				t.Errorf("expected %q, got %q", tt.expected, got)
 			}
@@ -251,47 +266,74 @@ func TestGetWorktreeName(t *testing.T) {
 	}
 }
 
+func TestGetWorktreeNameNested(t *testing.T) {
+	worktreesDir := t.TempDir()
+	checkout := filepath.Join(worktreesDir, "feature", "foo")
+	if err := os.MkdirAll(filepath.Join(checkout, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(checkout, ".git"), []byte("gitdir: elsewhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{checkout, filepath.Join(checkout, "src")} {
+		if got := GetWorktreeName(worktreesDir, path); got != "feature/foo" {
+			t.Errorf("GetWorktreeName(%s) = %q, want feature/foo", path, got)
+		}
+	}
+	if IsWorktreeCheckout(filepath.Join(worktreesDir, "feature")) {
+		t.Error("grouping directory reported as a worktree checkout")
+	}
+}
+
 func TestIsInsideWorktree(t *testing.T) {
 	tests := []struct {
-		name        string
-		repoRoot    string
-		path        string
-		worktreeDir string
-		expected    bool
+		name         string
+		worktreesDir string
+		path         string
+		expected     bool
 	}{
 		{
-			name:        "inside worktree",
-			repoRoot:    "/repo",
-			path:        "/repo/worktrees/feature-x/src",
-			worktreeDir: "worktrees",
-			expected:    true,
+			name:         "inside worktree",
+			worktreesDir: "/repo/worktrees",
+			path:         "/repo/worktrees/feature-x/src",
+			expected:     true,
 		},
 		{
-			name:        "at worktree root",
-			repoRoot:    "/repo",
-			path:        "/repo/worktrees/feature-x",
-			worktreeDir: "worktrees",
-			expected:    true,
+			name:         "at worktree root",
+			worktreesDir: "/repo/worktrees",
+			path:         "/repo/worktrees/feature-x",
+			expected:     true,
 		},
 		{
-			name:        "outside worktree",
-			repoRoot:    "/repo",
-			path:        "/repo/src",
-			worktreeDir: "worktrees",
-			expected:    false,
+			name:         "outside worktree",
+			worktreesDir: "/repo/worktrees",
+			path:         "/repo/src",
+			expected:     false,
 		},
 		{
-			name:        "at repo root",
-			repoRoot:    "/repo",
-			path:        "/repo",
-			worktreeDir: "worktrees",
-			expected:    false,
+			name:         "at repo root",
+			worktreesDir: "/repo/worktrees",
+			path:         "/repo",
+			expected:     false,
+		},
+		{
+			name:         "sibling with the same prefix",
+			worktreesDir: "/repo/worktrees",
+			path:         "/repo/worktrees-old/feature-x",
+			expected:     false,
+		},
+		{
+			name:         "outside the repository",
+			worktreesDir: "/home/me/repo-worktrees",
+			path:         "/home/me/repo-worktrees/feature-x",
+			expected:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := IsInsideWorktree(tt.repoRoot, tt.path, tt.worktreeDir)
+			got := IsInsideWorktree(tt.worktreesDir, tt.path)
 			if got != tt.expected {
 				t.Errorf("expected %v, got %v", tt.expected, got)
 			}
@@ -519,43 +561,6 @@ func TestIsBranchMerged(t *testing.T) {
 	}
 }
 
-func TestSetAndGetWorktreeCreatedAt(t *testing.T) {
-	repoRoot, cleanup := setupTestRepo(t)
-	defer cleanup()
-
-	// Create a worktree
-	worktreePath := filepath.Join(repoRoot, "worktrees", "test-wt")
-	worktreeName := "test-wt"
-	if err := CreateWorktree(repoRoot, worktreePath, "test-branch"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	defer func() { _ = RemoveWorktree(repoRoot, worktreePath, true) }()
-
-	// Initially should return zero time
-	createdAt, err := GetWorktreeCreatedAt(repoRoot, worktreeName)
-	if err != nil {
-		t.Fatalf("failed to get created at: %v", err)
-	}
-	if !createdAt.IsZero() {
-		t.Errorf("expected zero time, got %v", createdAt)
-	}
-
-	// Set creation time
-	now := time.Now().Truncate(time.Second) // Truncate to second precision
-	if err := SetWorktreeCreatedAt(repoRoot, worktreeName, now); err != nil {
-		t.Fatalf("failed to set created at: %v", err)
-	}
-
-	// Get it back
-	createdAt, err = GetWorktreeCreatedAt(repoRoot, worktreeName)
-	if err != nil {
-		t.Fatalf("failed to get created at: %v", err)
-	}
-	if createdAt.Unix() != now.Unix() {
-		t.Errorf("expected %v, got %v", now, createdAt)
-	}
-}
-
 func TestGetWorktreeStatus(t *testing.T) {
 	repoRoot, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -576,7 +581,7 @@ func TestGetWorktreeStatus(t *testing.T) {
 
 	// Set creation time
 	now := time.Now()
-	_ = SetWorktreeCreatedAt(repoRoot, worktreeName, now)
+	_ = OpenMetadata(repoRoot).SetCreatedAt(worktreeName, now)
 
 	// Get status
 	status, err := GetWorktreeStatus(repoRoot, worktreePath, worktreeName, branchName, mainBranch, nil)
@@ -619,48 +624,6 @@ func TestGetWorktreeStatus(t *testing.T) {
 	}
 }
 
-func TestSetAndGetWorktreeInitialCommit(t *testing.T) {
-	repoRoot, cleanup := setupTestRepo(t)
-	defer cleanup()
-
-	// Create a worktree
-	worktreePath := filepath.Join(repoRoot, "worktrees", "test-wt")
-	worktreeName := "test-wt"
-	if err := CreateWorktree(repoRoot, worktreePath, "test-branch"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	defer func() { _ = RemoveWorktree(repoRoot, worktreePath, true) }()
-
-	// Initially should return empty string
-	initialCommit, err := GetWorktreeInitialCommit(repoRoot, worktreeName)
-	if err != nil {
-		t.Fatalf("failed to get initial commit: %v", err)
-	}
-	if initialCommit != "" {
-		t.Errorf("expected empty string, got %q", initialCommit)
-	}
-
-	// Get current commit
-	currentCommit, err := GetCurrentCommit(worktreePath)
-	if err != nil {
-		t.Fatalf("failed to get current commit: %v", err)
-	}
-
-	// Set initial commit
-	if err := SetWorktreeInitialCommit(repoRoot, worktreeName, currentCommit); err != nil {
-		t.Fatalf("failed to set initial commit: %v", err)
-	}
-
-	// Get it back
-	initialCommit, err = GetWorktreeInitialCommit(repoRoot, worktreeName)
-	if err != nil {
-		t.Fatalf("failed to get initial commit: %v", err)
-	}
-	if initialCommit != currentCommit {
-		t.Errorf("expected %q, got %q", currentCommit, initialCommit)
-	}
-}
-
 func TestIsNewStatus(t *testing.T) {
 	repoRoot, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -681,7 +644,7 @@ func TestIsNewStatus(t *testing.T) {
 
 	// Store initial commit (simulating what create command does)
 	initialCommit, _ := GetCurrentCommit(worktreePath)
-	_ = SetWorktreeInitialCommit(repoRoot, worktreeName, initialCommit)
+	_ = OpenMetadata(repoRoot).SetInitialCommit(worktreeName, initialCommit)
 
 	// Should be marked as new (still on initial commit)
 	status, err := GetWorktreeStatus(repoRoot, worktreePath, worktreeName, branchName, mainBranch, nil)
@@ -872,189 +835,6 @@ func TestPRNumberRegex(t *testing.T) {
 	}
 }
 
-func TestSetAndGetWorktreeIndex(t *testing.T) {
-	repoRoot, cleanup := setupTestRepo(t)
-	defer cleanup()
-
-	// Create a worktree
-	worktreePath := filepath.Join(repoRoot, "worktrees", "test-wt")
-	worktreeName := "test-wt"
-	if err := CreateWorktree(repoRoot, worktreePath, "test-branch"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	defer func() { _ = RemoveWorktree(repoRoot, worktreePath, true) }()
-
-	// Initially should return error (no index file)
-	_, err := GetWorktreeIndex(repoRoot, worktreeName)
-	if err == nil {
-		t.Error("expected error for missing index, got nil")
-	}
-
-	// Set index
-	if err := SetWorktreeIndex(repoRoot, worktreeName, 5); err != nil {
-		t.Fatalf("failed to set index: %v", err)
-	}
-
-	// Get it back
-	index, err := GetWorktreeIndex(repoRoot, worktreeName)
-	if err != nil {
-		t.Fatalf("failed to get index: %v", err)
-	}
-	if index != 5 {
-		t.Errorf("expected index 5, got %d", index)
-	}
-
-	// Update index
-	if err := SetWorktreeIndex(repoRoot, worktreeName, 10); err != nil {
-		t.Fatalf("failed to update index: %v", err)
-	}
-
-	index, err = GetWorktreeIndex(repoRoot, worktreeName)
-	if err != nil {
-		t.Fatalf("failed to get updated index: %v", err)
-	}
-	if index != 10 {
-		t.Errorf("expected index 10, got %d", index)
-	}
-}
-
-func TestAllocateIndex(t *testing.T) {
-	repoRoot, cleanup := setupTestRepo(t)
-	defer cleanup()
-
-	// First allocation should return 1
-	index, err := AllocateIndex(repoRoot, 0)
-	if err != nil {
-		t.Fatalf("failed to allocate index: %v", err)
-	}
-	if index != 1 {
-		t.Errorf("expected index 1, got %d", index)
-	}
-
-	// Create worktrees and assign indexes
-	worktree1 := filepath.Join(repoRoot, "worktrees", "wt1")
-	if err := CreateWorktree(repoRoot, worktree1, "branch1"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	defer func() { _ = RemoveWorktree(repoRoot, worktree1, true) }()
-	_ = SetWorktreeIndex(repoRoot, "wt1", 1)
-
-	worktree2 := filepath.Join(repoRoot, "worktrees", "wt2")
-	if err := CreateWorktree(repoRoot, worktree2, "branch2"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	defer func() { _ = RemoveWorktree(repoRoot, worktree2, true) }()
-	_ = SetWorktreeIndex(repoRoot, "wt2", 2)
-
-	// Next allocation should return 3
-	index, err = AllocateIndex(repoRoot, 0)
-	if err != nil {
-		t.Fatalf("failed to allocate index: %v", err)
-	}
-	if index != 3 {
-		t.Errorf("expected index 3, got %d", index)
-	}
-}
-
-func TestAllocateIndexReusesFreedIndex(t *testing.T) {
-	repoRoot, cleanup := setupTestRepo(t)
-	defer cleanup()
-
-	// Create 3 worktrees with indexes 1, 2, 3
-	worktree1 := filepath.Join(repoRoot, "worktrees", "wt1")
-	if err := CreateWorktree(repoRoot, worktree1, "branch1"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	_ = SetWorktreeIndex(repoRoot, "wt1", 1)
-
-	worktree2 := filepath.Join(repoRoot, "worktrees", "wt2")
-	if err := CreateWorktree(repoRoot, worktree2, "branch2"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	_ = SetWorktreeIndex(repoRoot, "wt2", 2)
-
-	worktree3 := filepath.Join(repoRoot, "worktrees", "wt3")
-	if err := CreateWorktree(repoRoot, worktree3, "branch3"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	defer func() { _ = RemoveWorktree(repoRoot, worktree3, true) }()
-	_ = SetWorktreeIndex(repoRoot, "wt3", 3)
-
-	// Delete worktree 2 (frees index 2)
-	_ = RemoveWorktree(repoRoot, worktree2, true)
-
-	// Next allocation should reuse index 2
-	index, err := AllocateIndex(repoRoot, 0)
-	if err != nil {
-		t.Fatalf("failed to allocate index: %v", err)
-	}
-	if index != 2 {
-		t.Errorf("expected index 2 (reused), got %d", index)
-	}
-
-	// Delete worktree 1 (frees index 1)
-	_ = RemoveWorktree(repoRoot, worktree1, true)
-
-	// Next allocation should reuse index 1 (lowest available)
-	index, err = AllocateIndex(repoRoot, 0)
-	if err != nil {
-		t.Fatalf("failed to allocate index: %v", err)
-	}
-	if index != 1 {
-		t.Errorf("expected index 1 (lowest available), got %d", index)
-	}
-}
-
-func TestAllocateIndexMaxLimit(t *testing.T) {
-	repoRoot, cleanup := setupTestRepo(t)
-	defer cleanup()
-
-	// Create worktrees using all available indexes up to max
-	worktree1 := filepath.Join(repoRoot, "worktrees", "wt1")
-	if err := CreateWorktree(repoRoot, worktree1, "branch1"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	defer func() { _ = RemoveWorktree(repoRoot, worktree1, true) }()
-	_ = SetWorktreeIndex(repoRoot, "wt1", 1)
-
-	worktree2 := filepath.Join(repoRoot, "worktrees", "wt2")
-	if err := CreateWorktree(repoRoot, worktree2, "branch2"); err != nil {
-		t.Fatalf("failed to create worktree: %v", err)
-	}
-	defer func() { _ = RemoveWorktree(repoRoot, worktree2, true) }()
-	_ = SetWorktreeIndex(repoRoot, "wt2", 2)
-
-	// Try to allocate with max=2, should fail
-	_, err := AllocateIndex(repoRoot, 2)
-	if err == nil {
-		t.Error("expected error when max index reached, got nil")
-	}
-
-	// Verify error message
-	expectedErr := "no available index: all indexes 1-2 are in use"
-	if err.Error() != expectedErr {
-		t.Errorf("expected error %q, got %q", expectedErr, err.Error())
-	}
-
-	// With max=3, should succeed
-	index, err := AllocateIndex(repoRoot, 3)
-	if err != nil {
-		t.Fatalf("failed to allocate index with max=3: %v", err)
-	}
-	if index != 3 {
-		t.Errorf("expected index 3, got %d", index)
-	}
-
-	// With max=0 (no limit), should also succeed
-	index, err = AllocateIndex(repoRoot, 0)
-	if err != nil {
-		t.Fatalf("failed to allocate index with no limit: %v", err)
-	}
-	if index != 3 {
-		t.Errorf("expected index 3, got %d", index)
-	}
-}
-
 func TestWorktreeStatusIncludesIndex(t *testing.T) {
 	repoRoot, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -1074,7 +854,7 @@ func TestWorktreeStatusIncludesIndex(t *testing.T) {
 	defer func() { _ = RemoveWorktree(repoRoot, worktreePath, true) }()
 
 	// Set index
-	_ = SetWorktreeIndex(repoRoot, worktreeName, 7)
+	_ = OpenMetadata(repoRoot).SetIndex(worktreeName, 7)
 
 	// Get status - should include index
 	status, err := GetWorktreeStatus(repoRoot, worktreePath, worktreeName, branchName, mainBranch, nil)
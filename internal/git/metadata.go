@@ -0,0 +1,306 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Files wt keeps in a worktree's metadata directory, besides the config values below
+const (
+	nameFile  = "wt-name"  // The worktree's wt name
+	indexFile = "wt-index" // The worktree's index
+)
+
+// Config keys wt keeps in a worktree's metadata directory, in its config file
+const (
+	createdAtKey     = "wt.createdAt"
+	initialCommitKey = "wt.initialCommit"
+	mergeNotifiedKey = "wt.mergeNotifiedCommit"
+)
+
+// Metadata is the state wt keeps for a repository's worktrees, each in git's
+// administrative directory for the worktree (<common dir>/worktrees/<id>).
+//
+// git names that directory after the last element of the worktree path and adds a
+// number on collision, so "feature/foo" and "foo" may be "foo" and "foo1", and the
+// common dir needn't be <repo>/.git. A worktree's directory is found by the wt name
+// recorded in it when the worktree is registered, or for worktrees created before
+// names were recorded, by git's gitdir back-pointer to the checkout.
+type Metadata struct {
+	commonDir string
+	dirs      map[string]string // wt name -> metadata directory, loaded on first use
+}
+
+// OpenMetadata returns the metadata store of the repository at repoRoot. It reads
+// files only, so it's cheap enough for a shell prompt.
+func OpenMetadata(repoRoot string) *Metadata {
+	return &Metadata{commonDir: gitCommonDir(repoRoot)}
+}
+
+// gitCommonDir returns the git directory shared by a repository's worktrees,
+// honoring GIT_DIR and following a .git file (a separate git dir) and its
+// commondir file. repoRoot may be a bare repository's git dir itself.
+func gitCommonDir(repoRoot string) string {
+	if dir := os.Getenv("GIT_COMMON_DIR"); dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			return abs
+		}
+	}
+	gitDir := filepath.Join(repoRoot, ".git")
+	if dir := os.Getenv("GIT_DIR"); dir != "" {
+		gitDir, _ = filepath.Abs(dir)
+	} else if IsGitDir(repoRoot) {
+		gitDir = repoRoot
+	}
+	if data, err := os.ReadFile(gitDir); err == nil {
+		if target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:"); ok {
+			gitDir = resolveFrom(repoRoot, strings.TrimSpace(target))
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		gitDir = resolveFrom(gitDir, strings.TrimSpace(string(data)))
+	}
+	return gitDir
+}
+
+// IsGitDir reports whether dir is a git dir rather than a checkout, as a bare
+// repository is
+func IsGitDir(dir string) bool {
+	if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(dir, "HEAD"))
+	return err == nil
+}
+
+// IsBareRepository reports whether the repository at repoRoot, its git dir or one
+// of its worktrees, is bare: it has no main checkout, only linked worktrees
+func IsBareRepository(repoRoot string) bool {
+	output, err := exec.Command("git", "config", "--file", filepath.Join(gitCommonDir(repoRoot), "config"), "--bool", "--get", "core.bare").Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// resolveFrom returns path, made absolute relative to dir
+func resolveFrom(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
+
+// Register records the wt name of a new worktree in its metadata directory, which
+// git reports from inside the worktree. It must be called before other metadata is
+// stored, as that is found by name.
+func (m *Metadata) Register(worktreePath, name string) error {
+	cmd := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to find metadata directory of %s: %w", worktreePath, err)
+	}
+	dir := strings.TrimSpace(string(output))
+	if err := os.WriteFile(filepath.Join(dir, nameFile), []byte(name+"\n"), 0644); err != nil {
+		return err
+	}
+	m.load()
+	m.dirs[name] = dir
+	return nil
+}
+
+// load maps wt names to metadata directories. A recorded name wins over one taken
+// from the gitdir back-pointer, which only knows the checkout's last path element.
+func (m *Metadata) load() {
+	if m.dirs != nil {
+		return
+	}
+	m.dirs = make(map[string]string)
+	recorded := make(map[string]bool)
+	for _, dir := range m.worktreeDirs() {
+		name := readTrimmed(filepath.Join(dir, nameFile))
+		if name != "" {
+			m.dirs[name] = dir
+			recorded[name] = true
+			continue
+		}
+		// gitdir holds the path of the checkout's .git file
+		gitFile := readTrimmed(filepath.Join(dir, "gitdir"))
+		if gitFile == "" {
+			continue
+		}
+		name = filepath.Base(filepath.Dir(resolveFrom(dir, gitFile)))
+		if _, ok := m.dirs[name]; !ok || (!recorded[name] && filepath.Base(dir) == name) {
+			m.dirs[name] = dir
+		}
+	}
+}
+
+// worktreeDirs returns the metadata directories of all linked worktrees
+func (m *Metadata) worktreeDirs() []string {
+	adminDir := filepath.Join(m.commonDir, "worktrees")
+	entries, _ := os.ReadDir(adminDir)
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(adminDir, entry.Name()))
+		}
+	}
+	return dirs
+}
+
+// readTrimmed returns a file's contents without surrounding space, or "" if it
+// can't be read
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// CommonDir returns the git directory shared by the repository's worktrees
+func (m *Metadata) CommonDir() string {
+	return m.commonDir
+}
+
+// Dir returns a worktree's metadata directory. For an unknown worktree it's the
+// directory git would use for a flat name, which may not exist.
+func (m *Metadata) Dir(name string) string {
+	m.load()
+	if dir, ok := m.dirs[name]; ok {
+		return dir
+	}
+	return filepath.Join(m.commonDir, "worktrees", name)
+}
+
+// existingDir returns a worktree's metadata directory, or an error if it doesn't exist
+func (m *Metadata) existingDir(name string) (string, error) {
+	dir := m.Dir(name)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return "", fmt.Errorf("worktree directory not found: %s", dir)
+	}
+	return dir, nil
+}
+
+// setConfig stores a value in a worktree's metadata config (git creates the file)
+func (m *Metadata) setConfig(name, key, value string) error {
+	dir, err := m.existingDir(name)
+	if err != nil {
+		return err
+	}
+	return exec.Command("git", "config", "--file", filepath.Join(dir, "config"), key, value).Run()
+}
+
+// getConfig returns a value from a worktree's metadata config, or "" if it isn't set
+func (m *Metadata) getConfig(name, key string) string {
+	output, err := exec.Command("git", "config", "--file", filepath.Join(m.Dir(name), "config"), "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// SetCreatedAt stores a worktree's creation time
+func (m *Metadata) SetCreatedAt(name string, timestamp time.Time) error {
+	return m.setConfig(name, createdAtKey, strconv.FormatInt(timestamp.Unix(), 10))
+}
+
+// CreatedAt returns a worktree's creation time, or the zero time if it isn't known
+func (m *Metadata) CreatedAt(name string) (time.Time, error) {
+	timestamp, err := strconv.ParseInt(m.getConfig(name, createdAtKey), 10, 64)
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return time.Unix(timestamp, 0), nil
+}
+
+// SetInitialCommit stores the commit a worktree was created at
+func (m *Metadata) SetInitialCommit(name, commitSHA string) error {
+	return m.setConfig(name, initialCommitKey, commitSHA)
+}
+
+// InitialCommit returns the commit a worktree was created at, or "" if it isn't known
+func (m *Metadata) InitialCommit(name string) (string, error) {
+	return m.getConfig(name, initialCommitKey), nil
+}
+
+// SetMergeNotified records the commit at which post_merge hooks last ran for a worktree
+func (m *Metadata) SetMergeNotified(name, commitSHA string) error {
+	return m.setConfig(name, mergeNotifiedKey, commitSHA)
+}
+
+// MergeNotified returns the commit at which post_merge hooks last ran for a worktree
+func (m *Metadata) MergeNotified(name string) (string, error) {
+	return m.getConfig(name, mergeNotifiedKey), nil
+}
+
+// SetIndex stores a worktree's index
+func (m *Metadata) SetIndex(name string, index int) error {
+	dir, err := m.existingDir(name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, indexFile), []byte(strconv.Itoa(index)+"\n"), 0644)
+}
+
+// Index returns a worktree's index
+func (m *Metadata) Index(name string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(m.Dir(name), indexFile))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// ReleaseIndex removes a worktree's index so it can be allocated again
+func (m *Metadata) ReleaseIndex(name string) error {
+	return os.Remove(filepath.Join(m.Dir(name), indexFile))
+}
+
+// Indexes returns the index of every worktree that has one, keyed by worktree name
+func (m *Metadata) Indexes() (map[string]int, error) {
+	m.load()
+	indexes := make(map[string]int)
+	for name, dir := range m.dirs {
+		data, err := os.ReadFile(filepath.Join(dir, indexFile))
+		if err != nil {
+			continue
+		}
+		if idx, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			indexes[name] = idx
+		}
+	}
+	return indexes, nil
+}
+
+// AllocateIndex finds the lowest unused index for a new worktree, skipping reserved indexes
+func (m *Metadata) AllocateIndex(maxIndex int, reserved ...int) (int, error) {
+	used := make(map[int]bool)
+	for _, idx := range reserved {
+		used[idx] = true
+	}
+
+	// Every metadata directory's index is in use, even one no name maps to
+	for _, dir := range m.worktreeDirs() {
+		if idx, err := strconv.Atoi(readTrimmed(filepath.Join(dir, indexFile))); err == nil {
+			used[idx] = true
+		}
+	}
+
+	// Find lowest unused (starting at 1, reserve 0 for main repo)
+	for i := 1; ; i++ {
+		if maxIndex > 0 && i > maxIndex {
+			if len(reserved) > 0 {
+				return 0, fmt.Errorf("no available index: all indexes 1-%d are in use or reserved", maxIndex)
+			}
+			return 0, fmt.Errorf("no available index: all indexes 1-%d are in use", maxIndex)
+		}
+		if !used[i] {
+			return i, nil
+		}
+	}
+}
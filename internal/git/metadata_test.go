@@ -0,0 +1,456 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetAndGetWorktreeCreatedAt(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Create a worktree
+	worktreePath := filepath.Join(repoRoot, "worktrees", "test-wt")
+	worktreeName := "test-wt"
+	if err := CreateWorktree(repoRoot, worktreePath, "test-branch"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktreePath, true) }()
+
+	// Initially should return zero time
+	createdAt, err := OpenMetadata(repoRoot).CreatedAt(worktreeName)
+	if err != nil {
+		t.Fatalf("failed to get created at: %v", err)
+	}
+	if !createdAt.IsZero() {
+		t.Errorf("expected zero time, got %v", createdAt)
+	}
+
+	// Set creation time
+	now := time.Now().Truncate(time.Second) // Truncate to second precision
+	if err := OpenMetadata(repoRoot).SetCreatedAt(worktreeName, now); err != nil {
+		t.Fatalf("failed to set created at: %v", err)
+	}
+
+	// Get it back
+	createdAt, err = OpenMetadata(repoRoot).CreatedAt(worktreeName)
+	if err != nil {
+		t.Fatalf("failed to get created at: %v", err)
+	}
+	if createdAt.Unix() != now.Unix() {
+		t.Errorf("expected %v, got %v", now, createdAt)
+	}
+}
+
+func TestSetAndGetWorktreeInitialCommit(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Create a worktree
+	worktreePath := filepath.Join(repoRoot, "worktrees", "test-wt")
+	worktreeName := "test-wt"
+	if err := CreateWorktree(repoRoot, worktreePath, "test-branch"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktreePath, true) }()
+
+	// Initially should return empty string
+	initialCommit, err := OpenMetadata(repoRoot).InitialCommit(worktreeName)
+	if err != nil {
+		t.Fatalf("failed to get initial commit: %v", err)
+	}
+	if initialCommit != "" {
+		t.Errorf("expected empty string, got %q", initialCommit)
+	}
+
+	// Get current commit
+	currentCommit, err := GetCurrentCommit(worktreePath)
+	if err != nil {
+		t.Fatalf("failed to get current commit: %v", err)
+	}
+
+	// Set initial commit
+	if err := OpenMetadata(repoRoot).SetInitialCommit(worktreeName, currentCommit); err != nil {
+		t.Fatalf("failed to set initial commit: %v", err)
+	}
+
+	// Get it back
+	initialCommit, err = OpenMetadata(repoRoot).InitialCommit(worktreeName)
+	if err != nil {
+		t.Fatalf("failed to get initial commit: %v", err)
+	}
+	if initialCommit != currentCommit {
+		t.Errorf("expected %q, got %q", currentCommit, initialCommit)
+	}
+}
+
+func TestSetAndGetWorktreeIndex(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Create a worktree
+	worktreePath := filepath.Join(repoRoot, "worktrees", "test-wt")
+	worktreeName := "test-wt"
+	if err := CreateWorktree(repoRoot, worktreePath, "test-branch"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktreePath, true) }()
+
+	// Initially should return error (no index file)
+	_, err := OpenMetadata(repoRoot).Index(worktreeName)
+	if err == nil {
+		t.Error("expected error for missing index, got nil")
+	}
+
+	// Set index
+	if err := OpenMetadata(repoRoot).SetIndex(worktreeName, 5); err != nil {
+		t.Fatalf("failed to set index: %v", err)
+	}
+
+	// Get it back
+	index, err := OpenMetadata(repoRoot).Index(worktreeName)
+	if err != nil {
+		t.Fatalf("failed to get index: %v", err)
+	}
+	if index != 5 {
+		t.Errorf("expected index 5, got %d", index)
+	}
+
+	// Update index
+	if err := OpenMetadata(repoRoot).SetIndex(worktreeName, 10); err != nil {
+		t.Fatalf("failed to update index: %v", err)
+	}
+
+	index, err = OpenMetadata(repoRoot).Index(worktreeName)
+	if err != nil {
+		t.Fatalf("failed to get updated index: %v", err)
+	}
+	if index != 10 {
+		t.Errorf("expected index 10, got %d", index)
+	}
+}
+
+func TestAllocateIndex(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// First allocation should return 1
+	index, err := OpenMetadata(repoRoot).AllocateIndex(0)
+	if err != nil {
+		t.Fatalf("failed to allocate index: %v", err)
+	}
+	if index != 1 {
+		t.Errorf("expected index 1, got %d", index)
+	}
+
+	// Create worktrees and assign indexes
+	worktree1 := filepath.Join(repoRoot, "worktrees", "wt1")
+	if err := CreateWorktree(repoRoot, worktree1, "branch1"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktree1, true) }()
+	_ = OpenMetadata(repoRoot).SetIndex("wt1", 1)
+
+	worktree2 := filepath.Join(repoRoot, "worktrees", "wt2")
+	if err := CreateWorktree(repoRoot, worktree2, "branch2"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktree2, true) }()
+	_ = OpenMetadata(repoRoot).SetIndex("wt2", 2)
+
+	// Next allocation should return 3
+	index, err = OpenMetadata(repoRoot).AllocateIndex(0)
+	if err != nil {
+		t.Fatalf("failed to allocate index: %v", err)
+	}
+	if index != 3 {
+		t.Errorf("expected index 3, got %d", index)
+	}
+}
+
+func TestAllocateIndexReusesFreedIndex(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Create 3 worktrees with indexes 1, 2, 3
+	worktree1 := filepath.Join(repoRoot, "worktrees", "wt1")
+	if err := CreateWorktree(repoRoot, worktree1, "branch1"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	_ = OpenMetadata(repoRoot).SetIndex("wt1", 1)
+
+	worktree2 := filepath.Join(repoRoot, "worktrees", "wt2")
+	if err := CreateWorktree(repoRoot, worktree2, "branch2"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	_ = OpenMetadata(repoRoot).SetIndex("wt2", 2)
+
+	worktree3 := filepath.Join(repoRoot, "worktrees", "wt3")
+	if err := CreateWorktree(repoRoot, worktree3, "branch3"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktree3, true) }()
+	_ = OpenMetadata(repoRoot).SetIndex("wt3", 3)
+
+	// Delete worktree 2 (frees index 2)
+	_ = RemoveWorktree(repoRoot, worktree2, true)
+
+	// Next allocation should reuse index 2
+	index, err := OpenMetadata(repoRoot).AllocateIndex(0)
+	if err != nil {
+		t.Fatalf("failed to allocate index: %v", err)
+	}
+	if index != 2 {
+		t.Errorf("expected index 2 (reused), got %d", index)
+	}
+
+	// Delete worktree 1 (frees index 1)
+	_ = RemoveWorktree(repoRoot, worktree1, true)
+
+	// Next allocation should reuse index 1 (lowest available)
+	index, err = OpenMetadata(repoRoot).AllocateIndex(0)
+	if err != nil {
+		t.Fatalf("failed to allocate index: %v", err)
+	}
+	if index != 1 {
+		t.Errorf("expected index 1 (lowest available), got %d", index)
+	}
+}
+
+func TestAllocateIndexMaxLimit(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Create worktrees using all available indexes up to max
+	worktree1 := filepath.Join(repoRoot, "worktrees", "wt1")
+	if err := CreateWorktree(repoRoot, worktree1, "branch1"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktree1, true) }()
+	_ = OpenMetadata(repoRoot).SetIndex("wt1", 1)
+
+	worktree2 := filepath.Join(repoRoot, "worktrees", "wt2")
+	if err := CreateWorktree(repoRoot, worktree2, "branch2"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktree2, true) }()
+	_ = OpenMetadata(repoRoot).SetIndex("wt2", 2)
+
+	// Try to allocate with max=2, should fail
+	_, err := OpenMetadata(repoRoot).AllocateIndex(2)
+	if err == nil {
+		t.Error("expected error when max index reached, got nil")
+	}
+
+	// Verify error message
+	expectedErr := "no available index: all indexes 1-2 are in use"
+	if err.Error() != expectedErr {
+		t.Errorf("expected error %q, got %q", expectedErr, err.Error())
+	}
+
+	// With max=3, should succeed
+	index, err := OpenMetadata(repoRoot).AllocateIndex(3)
+	if err != nil {
+		t.Fatalf("failed to allocate index with max=3: %v", err)
+	}
+	if index != 3 {
+		t.Errorf("expected index 3, got %d", index)
+	}
+
+	// With max=0 (no limit), should also succeed
+	index, err = OpenMetadata(repoRoot).AllocateIndex(0)
+	if err != nil {
+		t.Fatalf("failed to allocate index with no limit: %v", err)
+	}
+	if index != 3 {
+		t.Errorf("expected index 3, got %d", index)
+	}
+}
+
+func TestAllocateIndexSkipsReserved(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	worktree1 := filepath.Join(repoRoot, "worktrees", "wt1")
+	if err := CreateWorktree(repoRoot, worktree1, "branch1"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktree1, true) }()
+	_ = OpenMetadata(repoRoot).SetIndex("wt1", 1)
+
+	// 2 and 3 are reserved, so the next index is 4
+	index, err := OpenMetadata(repoRoot).AllocateIndex(0, 2, 3)
+	if err != nil {
+		t.Fatalf("failed to allocate index: %v", err)
+	}
+	if index != 4 {
+		t.Errorf("expected index 4, got %d", index)
+	}
+
+	// Reserved indexes count towards the max
+	_, err = OpenMetadata(repoRoot).AllocateIndex(3, 2, 3)
+	expectedErr := "no available index: all indexes 1-3 are in use or reserved"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error %q, got %v", expectedErr, err)
+	}
+}
+
+func TestReleaseWorktreeIndex(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	worktree1 := filepath.Join(repoRoot, "worktrees", "wt1")
+	if err := CreateWorktree(repoRoot, worktree1, "branch1"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktree1, true) }()
+	_ = OpenMetadata(repoRoot).SetIndex("wt1", 1)
+
+	worktree2 := filepath.Join(repoRoot, "worktrees", "wt2")
+	if err := CreateWorktree(repoRoot, worktree2, "branch2"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktree2, true) }()
+	_ = OpenMetadata(repoRoot).SetIndex("wt2", 2)
+
+	indexes, err := OpenMetadata(repoRoot).Indexes()
+	if err != nil {
+		t.Fatalf("GetWorktreeIndexes failed: %v", err)
+	}
+	if len(indexes) != 2 || indexes["wt1"] != 1 || indexes["wt2"] != 2 {
+		t.Errorf("unexpected indexes: %v", indexes)
+	}
+
+	if err := OpenMetadata(repoRoot).ReleaseIndex("wt1"); err != nil {
+		t.Fatalf("ReleaseWorktreeIndex failed: %v", err)
+	}
+	if _, err := OpenMetadata(repoRoot).Index("wt1"); err == nil {
+		t.Error("expected no index after release")
+	}
+
+	// The released index is allocated again
+	index, err := OpenMetadata(repoRoot).AllocateIndex(0)
+	if err != nil {
+		t.Fatalf("failed to allocate index: %v", err)
+	}
+	if index != 1 {
+		t.Errorf("expected released index 1 to be reused, got %d", index)
+	}
+}
+
+func TestMetadataCollidingNames(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// git names both metadata directories after "foo"
+	meta := OpenMetadata(repoRoot)
+	paths := map[string]string{
+		"feature/foo": filepath.Join(repoRoot, "worktrees", "feature", "foo"),
+		"foo":         filepath.Join(repoRoot, "worktrees", "foo"),
+	}
+	for i, name := range []string{"feature/foo", "foo"} {
+		if err := CreateWorktree(repoRoot, paths[name], "branch"+name); err != nil {
+			t.Fatalf("failed to create worktree: %v", err)
+		}
+		if err := meta.Register(paths[name], name); err != nil {
+			t.Fatalf("Register(%s) failed: %v", name, err)
+		}
+		if err := meta.SetIndex(name, i+1); err != nil {
+			t.Fatalf("SetIndex(%s) failed: %v", name, err)
+		}
+	}
+
+	if meta.Dir("feature/foo") == meta.Dir("foo") {
+		t.Fatalf("both worktrees use %s", meta.Dir("foo"))
+	}
+	// A fresh store finds each worktree by its recorded name
+	fresh := OpenMetadata(repoRoot)
+	for name, want := range map[string]int{"feature/foo": 1, "foo": 2} {
+		if idx, err := fresh.Index(name); err != nil || idx != want {
+			t.Errorf("Index(%s) = %d, %v; want %d", name, idx, err, want)
+		}
+	}
+	indexes, _ := fresh.Indexes()
+	if len(indexes) != 2 || indexes["feature/foo"] != 1 || indexes["foo"] != 2 {
+		t.Errorf("unexpected indexes: %v", indexes)
+	}
+
+	// Removing one leaves the other's metadata alone
+	if err := RemoveWorktree(repoRoot, paths["feature/foo"], true); err != nil {
+		t.Fatalf("failed to remove worktree: %v", err)
+	}
+	if idx, err := OpenMetadata(repoRoot).Index("foo"); err != nil || idx != 2 {
+		t.Errorf("Index(foo) after removing feature/foo = %d, %v; want 2", idx, err)
+	}
+}
+
+func TestMetadataFindsUnregisteredWorktreeByBackPointer(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// The second checkout named foo gets metadata directory foo1, and keeps it once
+	// the first is gone
+	first := filepath.Join(repoRoot, "elsewhere", "foo")
+	if err := CreateWorktree(repoRoot, first, "first"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	second := filepath.Join(repoRoot, "worktrees", "foo")
+	if err := CreateWorktree(repoRoot, second, "second"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, second, true) }()
+	if err := RemoveWorktree(repoRoot, first, true); err != nil {
+		t.Fatalf("failed to remove worktree: %v", err)
+	}
+
+	meta := OpenMetadata(repoRoot)
+	if got := filepath.Base(meta.Dir("foo")); got != "foo1" {
+		t.Fatalf("expected metadata directory foo1, got %s", got)
+	}
+	if err := meta.SetIndex("foo", 3); err != nil {
+		t.Fatalf("SetIndex failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoRoot, ".git", "worktrees", "foo1", "wt-index")); err != nil {
+		t.Errorf("index not stored in foo1: %v", err)
+	}
+}
+
+func TestMetadataSeparateGitDir(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Move the repository's git dir out of the checkout, leaving a .git file
+	gitDir := filepath.Join(t.TempDir(), "repo.git")
+	cmd := exec.Command("git", "init", "--separate-git-dir", gitDir)
+	cmd.Dir = repoRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to separate git dir: %v\n%s", err, out)
+	}
+	gitDir, _ = filepath.EvalSymlinks(gitDir)
+
+	worktreePath := filepath.Join(repoRoot, "worktrees", "test-wt")
+	if err := CreateWorktree(repoRoot, worktreePath, "test-branch"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktreePath, true) }()
+
+	meta := OpenMetadata(repoRoot)
+	if err := meta.Register(worktreePath, "test-wt"); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if want := filepath.Join(gitDir, "worktrees", "test-wt"); meta.Dir("test-wt") != want {
+		t.Errorf("expected metadata directory %s, got %s", want, meta.Dir("test-wt"))
+	}
+	now := time.Now()
+	if err := meta.SetCreatedAt("test-wt", now); err != nil {
+		t.Fatalf("SetCreatedAt failed: %v", err)
+	}
+
+	// The store is the same from inside the worktree, via its commondir file
+	if createdAt, _ := OpenMetadata(worktreePath).CreatedAt("test-wt"); createdAt.Unix() != now.Unix() {
+		t.Errorf("expected created at %v from the worktree, got %v", now, createdAt)
+	}
+}
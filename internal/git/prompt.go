@@ -0,0 +1,78 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GetPromptStatus gathers a worktree's status for a shell prompt: at most three local
+// git commands, all bound to ctx, and no network access. Merged PRs aren't looked up.
+// If ctx expires, the status holds whatever was gathered so far and ctx's error is
+// returned alongside it.
+func GetPromptStatus(ctx context.Context, repoRoot, worktreePath, worktreeName, comparisonRef string) (status *WorktreeStatus, branch string, err error) {
+	status = &WorktreeStatus{}
+	meta := OpenMetadata(repoRoot)
+	status.Index, _ = meta.Index(worktreeName)
+
+	// One status call gives the branch, HEAD and whether anything is uncommitted
+	output, err := promptGit(ctx, worktreePath, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		if ctx.Err() != nil {
+			return status, "", ctx.Err()
+		}
+		return status, "", err
+	}
+	var head string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "# branch.oid "):
+			head = strings.TrimPrefix(line, "# branch.oid ")
+		case strings.HasPrefix(line, "# branch.head "):
+			branch = strings.TrimPrefix(line, "# branch.head ")
+			if branch == "(detached)" {
+				branch = ""
+			}
+		case !strings.HasPrefix(line, "#"):
+			status.HasUncommittedChanges = true
+		}
+	}
+
+	configPath := filepath.Join(meta.Dir(worktreeName), "config")
+	if output, err := promptGit(ctx, repoRoot, "config", "--file", configPath, "--get", "wt.initialCommit"); err == nil {
+		initial := strings.TrimSpace(string(output))
+		status.IsNew = initial != "" && initial == head
+	} else if ctx.Err() != nil {
+		return status, branch, ctx.Err()
+	}
+
+	// Format: <behind>\t<ahead>
+	output, err = promptGit(ctx, worktreePath, "rev-list", "--count", "--left-right", comparisonRef+"...HEAD")
+	if err != nil {
+		return status, branch, ctx.Err()
+	}
+	parts := strings.Split(strings.TrimSpace(string(output)), "\t")
+	if len(parts) == 2 {
+		status.CommitsBehind, _ = strconv.Atoi(parts[0])
+		status.CommitsAhead, _ = strconv.Atoi(parts[1])
+		// HEAD is contained in the comparison ref, as for git branch --merged
+		status.IsMerged = status.CommitsAhead == 0
+	}
+	return status, branch, nil
+}
+
+// promptGit runs a git command in dir, killed when ctx expires. Optional locks are
+// disabled so a prompt never contends with git commands the user is running.
+func promptGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	return cmd.Output()
+}
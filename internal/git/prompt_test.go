@@ -0,0 +1,77 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetPromptStatus(t *testing.T) {
+	repoRoot, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	mainBranch, err := GetCurrentBranch(repoRoot)
+	if err != nil {
+		t.Fatalf("failed to get current branch: %v", err)
+	}
+
+	worktreePath := filepath.Join(repoRoot, "worktrees", "test-wt")
+	worktreeName := "test-wt"
+	if err := CreateWorktree(repoRoot, worktreePath, "test-branch"); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	defer func() { _ = RemoveWorktree(repoRoot, worktreePath, true) }()
+
+	initialCommit, _ := GetCurrentCommit(worktreePath)
+	_ = OpenMetadata(repoRoot).SetInitialCommit(worktreeName, initialCommit)
+	_ = OpenMetadata(repoRoot).SetIndex(worktreeName, 3)
+
+	status, branch, err := GetPromptStatus(context.Background(), repoRoot, worktreePath, worktreeName, mainBranch)
+	if err != nil {
+		t.Fatalf("GetPromptStatus failed: %v", err)
+	}
+	if branch != "test-branch" {
+		t.Errorf("expected branch test-branch, got %q", branch)
+	}
+	if !status.IsNew || status.HasUncommittedChanges || status.CommitsAhead != 0 || status.Index != 3 {
+		t.Errorf("unexpected status for a new worktree: %+v", status)
+	}
+
+	// Commit, then leave an uncommitted file
+	if err := os.WriteFile(filepath.Join(worktreePath, "new-file.txt"), []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "Add new file"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = worktreePath
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "dirty.txt"), []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, _, err = GetPromptStatus(context.Background(), repoRoot, worktreePath, worktreeName, mainBranch)
+	if err != nil {
+		t.Fatalf("GetPromptStatus failed: %v", err)
+	}
+	if status.IsNew || !status.HasUncommittedChanges || status.CommitsAhead != 1 || status.IsMerged {
+		t.Errorf("unexpected status after committing: %+v", status)
+	}
+
+	// An expired budget returns what's known without running git
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	status, _, err = GetPromptStatus(ctx, repoRoot, worktreePath, worktreeName, mainBranch)
+	if err == nil {
+		t.Error("expected an error for an expired context")
+	}
+	if status.Index != 3 || status.HasUncommittedChanges {
+		t.Errorf("expected only the index for an expired context, got %+v", status)
+	}
+}
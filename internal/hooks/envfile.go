@@ -0,0 +1,72 @@
+package hooks
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/agarcher/wt/internal/git"
+)
+
+// EnvFileName is the file inside a worktree's metadata dir where hooks write
+// environment variables for the shell integration to load (passed as WT_ENV_FILE)
+const EnvFileName = "wt-env"
+
+// envNamePattern matches valid environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvFilePath returns the path of a worktree's environment file
+func EnvFilePath(repoRoot, worktreeName string) string {
+	return filepath.Join(git.OpenMetadata(repoRoot).Dir(worktreeName), EnvFileName)
+}
+
+// ValidEnvName reports whether name can be used as an environment variable name
+func ValidEnvName(name string) bool {
+	return envNamePattern.MatchString(name)
+}
+
+// ReadEnvFile parses a dotenv-style file of KEY=VALUE lines. Blank lines, comments
+// and invalid names are skipped; an "export " prefix and surrounding quotes are
+// removed, and later lines win. A missing file has no variables.
+func ReadEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !ValidEnvName(name) {
+			continue
+		}
+		vars[name] = unquoteEnvValue(strings.TrimSpace(value))
+	}
+	return vars, scanner.Err()
+}
+
+// unquoteEnvValue removes matching single or double quotes around a value
+func unquoteEnvValue(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		inner := v[1 : len(v)-1]
+		if v[0] == '"' {
+			inner = strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\n`, "\n").Replace(inner)
+		}
+		return inner
+	}
+	return v
+}
@@ -0,0 +1,84 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agarcher/wt/internal/git"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EnvFileName)
+	content := `# written by post_create
+DATABASE_URL=postgres://localhost/app_2
+export API_URL="http://localhost:5193"
+QUOTED='single quoted'
+ESCAPED="say \"hi\""
+  SPACED = value  
+
+not a variable
+1BAD=x
+API_URL=http://localhost:5194
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars, err := ReadEnvFile(path)
+	if err != nil {
+		t.Fatalf("ReadEnvFile failed: %v", err)
+	}
+	want := map[string]string{
+		"DATABASE_URL": "postgres://localhost/app_2",
+		"API_URL":      "http://localhost:5194",
+		"QUOTED":       "single quoted",
+		"ESCAPED":      `say "hi"`,
+		"SPACED":       "value",
+	}
+	if len(vars) != len(want) {
+		t.Errorf("expected %d variables, got %d: %v", len(want), len(vars), vars)
+	}
+	for name, value := range want {
+		if vars[name] != value {
+			t.Errorf("%s = %q, want %q", name, vars[name], value)
+		}
+	}
+}
+
+func TestReadEnvFileMissing(t *testing.T) {
+	vars, err := ReadEnvFile(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("expected no error for a missing file, got %v", err)
+	}
+	if len(vars) != 0 {
+		t.Errorf("expected no variables, got %v", vars)
+	}
+}
+
+func TestEnvFileInHookEnv(t *testing.T) {
+	repoRoot := t.TempDir()
+	env := &Env{Name: "feature", Path: filepath.Join(repoRoot, "worktrees", "feature"), RepoRoot: repoRoot, WorktreeDir: "worktrees"}
+
+	// No metadata dir yet (e.g. pre_create): no env file
+	for _, v := range env.ToEnvVars() {
+		if strings.HasPrefix(v, "WT_ENV_FILE=") {
+			t.Errorf("unexpected %s before the worktree exists", v)
+		}
+	}
+
+	if err := os.MkdirAll(git.OpenMetadata(repoRoot).Dir("feature"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	want := "WT_ENV_FILE=" + EnvFilePath(repoRoot, "feature")
+	found := false
+	for _, v := range env.ToEnvVars() {
+		if v == want {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected %s in %v", want, env.ToEnvVars())
+	}
+}
@@ -1,16 +1,111 @@
 package hooks
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+	"github.com/agarcher/wt/internal/provision"
 )
 
+// Hook event names, matching the keys under hooks: in .wt.yaml
+const (
+	EventPreCreate   = "pre_create"
+	EventPostCreate  = "post_create"
+	EventPreDelete   = "pre_delete"
+	EventPostDelete  = "post_delete"
+	EventPostSwitch  = "post_switch"
+	EventPreCleanup  = "pre_cleanup"
+	EventPostCleanup = "post_cleanup"
+	EventPostMerge   = "post_merge"
+	EventInfo        = "info"
+)
+
+// Events returns all hook event names in lifecycle order
+func Events() []string {
+	return []string{
+		EventPreCreate, EventPostCreate, EventPreDelete, EventPostDelete,
+		EventPostSwitch, EventPreCleanup, EventPostCleanup, EventPostMerge, EventInfo,
+	}
+}
+
+// Entries returns the hook entries configured for an event
+func Entries(cfg *config.Config, event string) ([]config.HookEntry, error) {
+	switch event {
+	case EventPreCreate:
+		return cfg.Hooks.PreCreate, nil
+	case EventPostCreate:
+		return cfg.Hooks.PostCreate, nil
+	case EventPreDelete:
+		return cfg.Hooks.PreDelete, nil
+	case EventPostDelete:
+		return cfg.Hooks.PostDelete, nil
+	case EventPostSwitch:
+		return cfg.Hooks.PostSwitch, nil
+	case EventPreCleanup:
+		return cfg.Hooks.PreCleanup, nil
+	case EventPostCleanup:
+		return cfg.Hooks.PostCleanup, nil
+	case EventPostMerge:
+		return cfg.Hooks.PostMerge, nil
+	case EventInfo:
+		return cfg.Hooks.Info, nil
+	}
+	return nil, fmt.Errorf("unknown hook event %q (valid: %s)", event, strings.Join(Events(), ", "))
+}
+
+// WorkDir returns the directory an event's hooks run in
+func WorkDir(event string, env *Env) string {
+	switch event {
+	case EventPreCreate, EventPostDelete, EventPreCleanup, EventPostCleanup:
+		return env.RepoRoot
+	}
+	return env.Path
+}
+
+// ResolveScript returns the absolute path of a hook script; relative paths are
+// resolved from the repository root
+func ResolveScript(entry config.HookEntry, repoRoot string) string {
+	if filepath.IsAbs(entry.Script) {
+		return entry.Script
+	}
+	return filepath.Join(repoRoot, entry.Script)
+}
+
+// RunEvent runs the lifecycle hooks for an event, as the command that triggers it would.
+// Info hooks capture their output instead; use RunInfo for them.
+func RunEvent(cfg *config.Config, event string, env *Env) error {
+	switch event {
+	case EventPreCreate:
+		return RunPreCreate(cfg, env)
+	case EventPostCreate:
+		return RunPostCreate(cfg, env)
+	case EventPreDelete:
+		return RunPreDelete(cfg, env)
+	case EventPostDelete:
+		return RunPostDelete(cfg, env)
+	case EventPostSwitch:
+		return RunPostSwitch(cfg, env)
+	case EventPreCleanup:
+		return RunPreCleanup(cfg, env)
+	case EventPostCleanup:
+		return RunPostCleanup(cfg, env)
+	case EventPostMerge:
+		return RunPostMerge(cfg, env)
+	case EventInfo:
+		return fmt.Errorf("info hooks capture their output; use RunInfo")
+	}
+	_, err := Entries(cfg, event)
+	return err
+}
+
 // Env contains environment variables passed to hooks
 type Env struct {
 	Name        string
@@ -19,6 +114,58 @@ type Env struct {
 	RepoRoot    string
 	WorktreeDir string
 	Index       int
+
+	// Event is the hook event being run; filled in by the runner when empty
+	Event string
+	// Force is true when the triggering command was run with --force
+	Force bool
+	// ComparisonRef is the ref status is measured against (e.g. "main" or "origin/main")
+	ComparisonRef string
+	// BaseCommit is the commit the worktree was created from
+	BaseCommit string
+	// Status is the worktree's state relative to ComparisonRef, when known
+	Status *Status
+
+	// Worktrees lists the worktrees affected by repo-wide events (pre_cleanup, post_cleanup)
+	Worktrees []WorktreeRef
+
+	// Ports are the worktree's named ports from resources:; filled in by the runner when nil
+	Ports []provision.Port
+}
+
+// Status describes a worktree's state for hooks
+type Status struct {
+	Dirty         bool `json:"dirty"`
+	Merged        bool `json:"merged"`
+	New           bool `json:"new"`
+	CommitsAhead  int  `json:"commits_ahead"`
+	CommitsBehind int  `json:"commits_behind"`
+}
+
+// WorktreeRef identifies a worktree passed to repo-wide hooks
+type WorktreeRef struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Branch string `json:"branch"`
+	Index  int    `json:"index,omitempty"`
+}
+
+// hookContext is the JSON document passed to hooks in $WT_CONTEXT_FILE
+type hookContext struct {
+	Event         string  `json:"event"`
+	Name          string  `json:"name,omitempty"`
+	Path          string  `json:"path,omitempty"`
+	Branch        string  `json:"branch,omitempty"`
+	RepoRoot      string  `json:"repo_root"`
+	WorktreeDir   string  `json:"worktree_dir"`
+	Index         int     `json:"index,omitempty"`
+	Force         bool    `json:"force"`
+	ComparisonRef string  `json:"comparison_ref,omitempty"`
+	BaseCommit    string  `json:"base_commit,omitempty"`
+	Status        *Status `json:"status,omitempty"`
+	Worktrees     any     `json:"worktrees,omitempty"` // Only set for repo-wide events, may be empty
+
+	Ports map[string]provision.PortJSON `json:"ports,omitempty"`
 }
 
 // ToEnvVars converts the Env struct to environment variable format
@@ -34,48 +181,200 @@ func (e *Env) ToEnvVars() []string {
 	if e.Index > 0 {
 		vars = append(vars, "WT_INDEX="+strconv.Itoa(e.Index))
 	}
+	if e.Event != "" {
+		vars = append(vars, "WT_EVENT="+e.Event)
+	}
+	if e.Force {
+		vars = append(vars, "WT_FORCE=true")
+	}
+	if e.ComparisonRef != "" {
+		vars = append(vars, "WT_COMPARISON_REF="+e.ComparisonRef)
+	}
+	if e.BaseCommit != "" {
+		vars = append(vars, "WT_BASE_COMMIT="+e.BaseCommit)
+	}
+	// Only include status when the command computed it
+	if e.Status != nil {
+		vars = append(vars,
+			"WT_DIRTY="+strconv.FormatBool(e.Status.Dirty),
+			"WT_MERGED="+strconv.FormatBool(e.Status.Merged),
+			"WT_NEW="+strconv.FormatBool(e.Status.New),
+			"WT_COMMITS_AHEAD="+strconv.Itoa(e.Status.CommitsAhead),
+			"WT_COMMITS_BEHIND="+strconv.Itoa(e.Status.CommitsBehind),
+		)
+	}
+	// Hooks can add to the worktree's environment once its metadata dir exists
+	if e.Name != "" {
+		if _, err := os.Stat(git.OpenMetadata(e.RepoRoot).Dir(e.Name)); err == nil {
+			vars = append(vars, "WT_ENV_FILE="+EnvFilePath(e.RepoRoot, e.Name))
+		}
+	}
+	// Named ports, e.g. WT_PORT_WEB=5193, plus WT_PORT_<NAME>_END for ranges
+	for _, p := range e.Ports {
+		vars = append(vars, p.EnvName()+"="+strconv.Itoa(p.Port))
+		if p.Count > 1 {
+			vars = append(vars, p.EnvName()+"_END="+strconv.Itoa(p.End()))
+		}
+	}
+	// Only include the worktree list for repo-wide events
+	if e.Worktrees != nil {
+		names := make([]string, len(e.Worktrees))
+		for i, wt := range e.Worktrees {
+			names[i] = wt.Name
+		}
+		vars = append(vars,
+			"WT_WORKTREES="+strings.Join(names, " "),
+			"WT_WORKTREE_COUNT="+strconv.Itoa(len(e.Worktrees)),
+		)
+	}
 	return vars
 }
 
+// ToJSON returns the JSON document passed to hooks in $WT_CONTEXT_FILE
+func (e *Env) ToJSON() ([]byte, error) {
+	ctx := hookContext{
+		Event:         e.Event,
+		Name:          e.Name,
+		Path:          e.Path,
+		Branch:        e.Branch,
+		RepoRoot:      e.RepoRoot,
+		WorktreeDir:   e.WorktreeDir,
+		Index:         e.Index,
+		Force:         e.Force,
+		ComparisonRef: e.ComparisonRef,
+		BaseCommit:    e.BaseCommit,
+		Status:        e.Status,
+		Ports:         provision.PortsJSON(e.Ports),
+	}
+	if e.Worktrees != nil {
+		ctx.Worktrees = e.Worktrees
+	}
+	return json.Marshal(ctx)
+}
+
+// writeContextFile writes the JSON context to a temporary file for $WT_CONTEXT_FILE,
+// returning its path and contents. The caller removes the file.
+func writeContextFile(env *Env) (string, []byte, error) {
+	data, err := env.ToJSON()
+	if err != nil {
+		return "", nil, err
+	}
+	f, err := os.CreateTemp("", "wt-context-*.json")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to write hook context: %w", err)
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", nil, fmt.Errorf("failed to write hook context: %w", err)
+	}
+	return f.Name(), data, nil
+}
+
+// withEvent returns env with Event set, copying it if needed so callers' values aren't modified
+func withEvent(env *Env, event string) *Env {
+	if event == "" || env.Event != "" {
+		return env
+	}
+	e := *env
+	e.Event = event
+	return &e
+}
+
+// WithPorts returns env with Ports resolved from resources for its index, copying it
+// if needed. Worktrees without an index (and invalid resources) get no ports.
+func WithPorts(env *Env, resources config.ResourcesConfig) *Env {
+	if env.Ports != nil || env.Index <= 0 || len(resources) == 0 {
+		return env
+	}
+	ports, err := provision.ResolvePorts(resources, env.Index)
+	if err != nil {
+		return env
+	}
+	e := *env
+	e.Ports = ports
+	return &e
+}
+
 // Run executes a list of hook entries
 func Run(entries []config.HookEntry, env *Env, workDir string) error {
+	return runEntries(entries, env, runOptions{workDir: workDir})
+}
+
+// runOptions controls how a list of hook entries is executed
+type runOptions struct {
+	event   string                 // Hook event name; enables persisting output to hook logs
+	workDir string                 // Working directory for the scripts
+	logs    config.LogsConfig      // Hook log retention limits
+	ports   config.ResourcesConfig // Named ports exported to the scripts
+	stdout  io.Writer              // Destination for script stdout (default: os.Stdout)
+}
+
+// runEntries executes a list of hook entries, stopping at the first failure
+func runEntries(entries []config.HookEntry, env *Env, opts runOptions) error {
+	env = WithPorts(withEvent(env, opts.event), opts.ports)
+	contextFile, _, err := writeContextFile(env)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(contextFile) }()
 	for _, entry := range entries {
-		if err := runHook(entry, env, workDir); err != nil {
+		if err := runHook(entry, env, contextFile, opts); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// runHook executes a single hook entry
-func runHook(entry config.HookEntry, env *Env, workDir string) error {
-	scriptPath := entry.Script
-
-	// Resolve relative paths from repo root
-	if !filepath.IsAbs(scriptPath) {
-		scriptPath = filepath.Join(env.RepoRoot, scriptPath)
-	}
+// runHook executes a single hook entry. The script shares wt's stdin, so it can prompt,
+// and finds the JSON context in $WT_CONTEXT_FILE.
+func runHook(entry config.HookEntry, env *Env, contextFile string, opts runOptions) error {
+	scriptPath := ResolveScript(entry, env.RepoRoot)
 
 	// Check if script exists
 	if _, err := os.Stat(scriptPath); err != nil {
 		return fmt.Errorf("hook script not found: %s", scriptPath)
 	}
 
+	stdout := opts.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
 	// Build the command
 	cmd := exec.Command("/bin/bash", scriptPath)
-	cmd.Dir = workDir
-	cmd.Stdout = os.Stdout
+	cmd.Dir = opts.workDir
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
 
 	// Set environment variables
 	cmd.Env = append(os.Environ(), env.ToEnvVars()...)
+	cmd.Env = append(cmd.Env, "WT_CONTEXT_FILE="+contextFile)
 
 	// Add custom environment variables from hook config
 	for k, v := range entry.Env {
 		cmd.Env = append(cmd.Env, k+"="+v)
 	}
 
-	return cmd.Run()
+	// Tee output into the worktree's hook log
+	var log *hookLog
+	if opts.event != "" && env.Name != "" {
+		log = openHookLog(logDirFor(env.RepoRoot, env.Name), opts.event, scriptPath)
+	}
+	if log == nil {
+		return cmd.Run()
+	}
+	cmd.Stdout = io.MultiWriter(stdout, log)
+	cmd.Stderr = io.MultiWriter(os.Stderr, log)
+
+	err := cmd.Run()
+	log.finish(err)
+	_ = PruneLogs(log.dir, opts.logs.MaxFiles, opts.logs.MaxBytes)
+	return err
 }
 
 // RunPreCreate runs pre-create hooks
@@ -85,7 +384,7 @@ func RunPreCreate(cfg *config.Config, env *Env) error {
 	}
 	fmt.Println("Running pre-create hooks...")
 	_ = os.Stdout.Sync() // Flush so message appears before hook output
-	return Run(cfg.Hooks.PreCreate, env, env.RepoRoot)
+	return runEntries(cfg.Hooks.PreCreate, env, runOptions{event: EventPreCreate, workDir: WorkDir(EventPreCreate, env), logs: cfg.Logs, ports: cfg.Resources})
 }
 
 // RunPostCreate runs post-create hooks
@@ -95,7 +394,7 @@ func RunPostCreate(cfg *config.Config, env *Env) error {
 	}
 	fmt.Println("Running post-create hooks...")
 	_ = os.Stdout.Sync() // Flush so message appears before hook output
-	return Run(cfg.Hooks.PostCreate, env, env.Path)
+	return runEntries(cfg.Hooks.PostCreate, env, runOptions{event: EventPostCreate, workDir: WorkDir(EventPostCreate, env), logs: cfg.Logs, ports: cfg.Resources})
 }
 
 // RunPreDelete runs pre-delete hooks
@@ -105,7 +404,7 @@ func RunPreDelete(cfg *config.Config, env *Env) error {
 	}
 	fmt.Println("Running pre-delete hooks...")
 	_ = os.Stdout.Sync() // Flush so message appears before hook output
-	return Run(cfg.Hooks.PreDelete, env, env.Path)
+	return runEntries(cfg.Hooks.PreDelete, env, runOptions{event: EventPreDelete, workDir: WorkDir(EventPreDelete, env), logs: cfg.Logs, ports: cfg.Resources})
 }
 
 // RunPostDelete runs post-delete hooks
@@ -115,63 +414,54 @@ func RunPostDelete(cfg *config.Config, env *Env) error {
 	}
 	fmt.Println("Running post-delete hooks...")
 	_ = os.Stdout.Sync() // Flush so message appears before hook output
-	return Run(cfg.Hooks.PostDelete, env, env.RepoRoot)
+	return runEntries(cfg.Hooks.PostDelete, env, runOptions{event: EventPostDelete, workDir: WorkDir(EventPostDelete, env), logs: cfg.Logs, ports: cfg.Resources})
 }
 
-// RunInfo runs info hooks and returns captured stdout
-func RunInfo(cfg *config.Config, env *Env) (string, error) {
-	if len(cfg.Hooks.Info) == 0 {
-		return "", nil
+// RunPostSwitch runs post-switch hooks after the shell wrapper changes into a worktree
+// (or back to the main repo, in which case env.Name is empty)
+func RunPostSwitch(cfg *config.Config, env *Env) error {
+	if len(cfg.Hooks.PostSwitch) == 0 {
+		return nil
 	}
-	return runAndCapture(cfg.Hooks.Info, env, env.Path)
+	return runEntries(cfg.Hooks.PostSwitch, env, runOptions{event: EventPostSwitch, workDir: WorkDir(EventPostSwitch, env), logs: cfg.Logs, ports: cfg.Resources})
 }
 
-// runAndCapture executes hooks and captures their stdout
-func runAndCapture(entries []config.HookEntry, env *Env, workDir string) (string, error) {
-	var output bytes.Buffer
-	for _, entry := range entries {
-		out, err := runHookCapture(entry, env, workDir)
-		if err != nil {
-			return "", err
-		}
-		output.WriteString(out)
+// RunPreCleanup runs pre-cleanup hooks once before a cleanup run deletes env.Worktrees
+func RunPreCleanup(cfg *config.Config, env *Env) error {
+	if len(cfg.Hooks.PreCleanup) == 0 {
+		return nil
 	}
-	return output.String(), nil
+	fmt.Println("Running pre-cleanup hooks...")
+	_ = os.Stdout.Sync() // Flush so message appears before hook output
+	return runCleanupHooks(cfg, EventPreCleanup, cfg.Hooks.PreCleanup, env)
 }
 
-// runHookCapture executes a single hook and captures its stdout
-func runHookCapture(entry config.HookEntry, env *Env, workDir string) (string, error) {
-	scriptPath := entry.Script
-
-	// Resolve relative paths from repo root
-	if !filepath.IsAbs(scriptPath) {
-		scriptPath = filepath.Join(env.RepoRoot, scriptPath)
-	}
-
-	// Check if script exists
-	if _, err := os.Stat(scriptPath); err != nil {
-		return "", fmt.Errorf("hook script not found: %s", scriptPath)
+// RunPostCleanup runs post-cleanup hooks once after a cleanup run deleted env.Worktrees
+func RunPostCleanup(cfg *config.Config, env *Env) error {
+	if len(cfg.Hooks.PostCleanup) == 0 {
+		return nil
 	}
+	fmt.Println("Running post-cleanup hooks...")
+	_ = os.Stdout.Sync() // Flush so message appears before hook output
+	return runCleanupHooks(cfg, EventPostCleanup, cfg.Hooks.PostCleanup, env)
+}
 
-	// Build the command
-	cmd := exec.Command("/bin/bash", scriptPath)
-	cmd.Dir = workDir
-
-	// Capture stdout, let stderr go to os.Stderr
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = os.Stderr
-
-	// Set environment variables
-	cmd.Env = append(os.Environ(), env.ToEnvVars()...)
-
-	// Add custom environment variables from hook config
-	for k, v := range entry.Env {
-		cmd.Env = append(cmd.Env, k+"="+v)
+// runCleanupHooks runs repo-wide cleanup hooks, always passing a (possibly empty) worktree list
+func runCleanupHooks(cfg *config.Config, event string, entries []config.HookEntry, env *Env) error {
+	if env.Worktrees == nil {
+		e := *env
+		e.Worktrees = []WorktreeRef{}
+		env = &e
 	}
+	return runEntries(entries, env, runOptions{event: event, workDir: WorkDir(event, env), logs: cfg.Logs, ports: cfg.Resources})
+}
 
-	if err := cmd.Run(); err != nil {
-		return "", err
+// RunPostMerge runs post-merge hooks for a worktree whose branch was detected merged.
+// Hook stdout goes to stderr so it doesn't interleave with listing output.
+func RunPostMerge(cfg *config.Config, env *Env) error {
+	if len(cfg.Hooks.PostMerge) == 0 {
+		return nil
 	}
-	return stdout.String(), nil
+	_, _ = fmt.Fprintf(os.Stderr, "Running post-merge hooks for %s...\n", env.Name)
+	return runEntries(cfg.Hooks.PostMerge, env, runOptions{event: EventPostMerge, workDir: WorkDir(EventPostMerge, env), logs: cfg.Logs, ports: cfg.Resources, stdout: os.Stderr})
 }
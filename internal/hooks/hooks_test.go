@@ -1,8 +1,10 @@
 package hooks
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/agarcher/wt/internal/config"
@@ -351,6 +353,353 @@ echo "post-create ran in $(pwd)" > "` + markerPath + `"
 	}
 }
 
+func TestEnvToEnvVarsWithWorktrees(t *testing.T) {
+	env := &Env{
+		RepoRoot:    "/repo",
+		WorktreeDir: "worktrees",
+		Worktrees: []WorktreeRef{
+			{Name: "alpha", Path: "/repo/worktrees/alpha", Branch: "alpha", Index: 1},
+			{Name: "beta", Path: "/repo/worktrees/beta", Branch: "beta", Index: 2},
+		},
+	}
+
+	vars := env.ToEnvVars()
+
+	found := map[string]bool{}
+	for _, v := range vars {
+		found[v] = true
+	}
+	if !found["WT_WORKTREES=alpha beta"] {
+		t.Errorf("expected WT_WORKTREES=alpha beta in %v", vars)
+	}
+	if !found["WT_WORKTREE_COUNT=2"] {
+		t.Errorf("expected WT_WORKTREE_COUNT=2 in %v", vars)
+	}
+}
+
+func TestRunPreCleanupHooks(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "wt-hooks-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// Script saves its context and working directory
+	stdinPath := filepath.Join(tmpDir, "context.json")
+	pwdPath := filepath.Join(tmpDir, "pwd.txt")
+	scriptPath := filepath.Join(tmpDir, "pre-cleanup.sh")
+	scriptContent := `#!/bin/bash
+cat "$WT_CONTEXT_FILE" > "` + stdinPath + `"
+pwd > "` + pwdPath + `"
+`
+	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("failed to write test script: %v", err)
+	}
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			PreCleanup: []config.HookEntry{
+				{Script: scriptPath},
+			},
+		},
+	}
+
+	env := &Env{
+		RepoRoot:    tmpDir,
+		WorktreeDir: "worktrees",
+		Worktrees:   []WorktreeRef{{Name: "alpha", Path: "/repo/worktrees/alpha", Branch: "alpha"}},
+	}
+
+	if err := RunPreCleanup(cfg, env); err != nil {
+		t.Fatalf("RunPreCleanup failed: %v", err)
+	}
+
+	data, err := os.ReadFile(stdinPath)
+	if err != nil {
+		t.Fatalf("pre-cleanup hook did not run: %v", err)
+	}
+	if !contains(string(data), `"event":"pre_cleanup"`) || !contains(string(data), `"name":"alpha"`) {
+		t.Errorf("unexpected context JSON: %s", data)
+	}
+
+	pwd, _ := os.ReadFile(pwdPath)
+	resolved, _ := filepath.EvalSymlinks(tmpDir)
+	if got := string(pwd); got != tmpDir+"\n" && got != resolved+"\n" {
+		t.Errorf("expected pre-cleanup hook to run in repo root, ran in %q", got)
+	}
+}
+
+func TestRunPostSwitchHooks(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "wt-hooks-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	markerPath := filepath.Join(tmpDir, "marker.txt")
+	scriptPath := filepath.Join(tmpDir, "post-switch.sh")
+	scriptContent := `#!/bin/bash
+echo "switched to $WT_NAME" > "` + markerPath + `"
+`
+	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("failed to write test script: %v", err)
+	}
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			PostSwitch: []config.HookEntry{
+				{Script: scriptPath},
+			},
+		},
+	}
+
+	env := &Env{
+		Name:        "test-wt",
+		Path:        tmpDir,
+		Branch:      "test-branch",
+		RepoRoot:    tmpDir,
+		WorktreeDir: "worktrees",
+	}
+
+	if err := RunPostSwitch(cfg, env); err != nil {
+		t.Fatalf("RunPostSwitch failed: %v", err)
+	}
+
+	data, err := os.ReadFile(markerPath)
+	if err != nil {
+		t.Fatalf("post-switch hook did not run: %v", err)
+	}
+	if !contains(string(data), "switched to test-wt") {
+		t.Errorf("unexpected hook output: %s", data)
+	}
+}
+
+func TestRunPostMergeHooks(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "wt-hooks-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	markerPath := filepath.Join(tmpDir, "marker.txt")
+	scriptPath := filepath.Join(tmpDir, "post-merge.sh")
+	scriptContent := `#!/bin/bash
+echo "$WT_BRANCH merged" > "` + markerPath + `"
+`
+	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("failed to write test script: %v", err)
+	}
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			PostMerge: []config.HookEntry{
+				{Script: scriptPath},
+			},
+		},
+	}
+
+	env := &Env{
+		Name:        "test-wt",
+		Path:        tmpDir,
+		Branch:      "test-branch",
+		RepoRoot:    tmpDir,
+		WorktreeDir: "worktrees",
+	}
+
+	if err := RunPostMerge(cfg, env); err != nil {
+		t.Fatalf("RunPostMerge failed: %v", err)
+	}
+
+	data, err := os.ReadFile(markerPath)
+	if err != nil {
+		t.Fatalf("post-merge hook did not run: %v", err)
+	}
+	if !contains(string(data), "test-branch merged") {
+		t.Errorf("unexpected hook output: %s", data)
+	}
+}
+
+func TestEnvToEnvVarsWithStatus(t *testing.T) {
+	env := &Env{
+		Name:          "test-wt",
+		Path:          "/path/to/worktree",
+		Branch:        "test-branch",
+		RepoRoot:      "/path/to/repo",
+		WorktreeDir:   "worktrees",
+		Event:         EventPreDelete,
+		Force:         true,
+		ComparisonRef: "origin/main",
+		BaseCommit:    "abc123",
+		Status:        &Status{Dirty: true, CommitsAhead: 2, CommitsBehind: 1},
+	}
+
+	vars := env.ToEnvVars()
+
+	found := map[string]bool{}
+	for _, v := range vars {
+		found[v] = true
+	}
+	for _, want := range []string{
+		"WT_EVENT=pre_delete",
+		"WT_FORCE=true",
+		"WT_COMPARISON_REF=origin/main",
+		"WT_BASE_COMMIT=abc123",
+		"WT_DIRTY=true",
+		"WT_MERGED=false",
+		"WT_NEW=false",
+		"WT_COMMITS_AHEAD=2",
+		"WT_COMMITS_BEHIND=1",
+	} {
+		if !found[want] {
+			t.Errorf("expected %s in %v", want, vars)
+		}
+	}
+}
+
+func TestHooksReceivePorts(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Script saves its port variables and context
+	outPath := filepath.Join(tmpDir, "ports.txt")
+	scriptPath := filepath.Join(tmpDir, "post-create.sh")
+	scriptContent := `#!/bin/bash
+echo "$WT_PORT_WEB $WT_PORT_WORKERS $WT_PORT_WORKERS_END" > "` + outPath + `"
+cat "$WT_CONTEXT_FILE" >> "` + outPath + `"
+`
+	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("failed to write test script: %v", err)
+	}
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			PostCreate: []config.HookEntry{{Script: scriptPath}},
+		},
+		Resources: config.ResourcesConfig{
+			"web":     {Port: "5173+index*10"},
+			"workers": {Port: "6000+index*10", Count: 10},
+		},
+	}
+	env := &Env{Name: "test-wt", Path: tmpDir, RepoRoot: tmpDir, WorktreeDir: "worktrees", Index: 2}
+
+	if err := RunPostCreate(cfg, env); err != nil {
+		t.Fatalf("RunPostCreate failed: %v", err)
+	}
+	if env.Ports != nil {
+		t.Error("expected caller's env to be left unmodified")
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("post-create hook did not run: %v", err)
+	}
+	if !contains(string(data), "5193 6020 6029\n") {
+		t.Errorf("expected port variables, got %q", data)
+	}
+	if !contains(string(data), `"ports":{"web":{"port":5193},"workers":{"port":6020,"end":6029}}`) {
+		t.Errorf("expected ports in context, got %q", data)
+	}
+
+	// Worktrees without an index get no ports
+	if got := WithPorts(&Env{Name: "x"}, cfg.Resources); got.Ports != nil {
+		t.Errorf("expected no ports without an index, got %v", got.Ports)
+	}
+}
+
+func TestHooksReceiveContextFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "wt-hooks-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// Script saves its context, WT_EVENT, and an answer read from stdin
+	stdinPath := filepath.Join(tmpDir, "context.json")
+	eventPath := filepath.Join(tmpDir, "event.txt")
+	scriptPath := filepath.Join(tmpDir, "pre-delete.sh")
+	scriptContent := `#!/bin/bash
+cat "$WT_CONTEXT_FILE" > "` + stdinPath + `"
+read -r answer
+echo "$WT_EVENT $answer $WT_CONTEXT_FILE" > "` + eventPath + `"
+`
+	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("failed to write test script: %v", err)
+	}
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			PreDelete: []config.HookEntry{
+				{Script: scriptPath},
+			},
+		},
+	}
+
+	env := &Env{
+		Name:          "test-wt",
+		Path:          tmpDir,
+		Branch:        "test-branch",
+		RepoRoot:      tmpDir,
+		WorktreeDir:   "worktrees",
+		Index:         3,
+		ComparisonRef: "main",
+		Status:        &Status{Merged: true},
+	}
+
+	// Hooks share wt's stdin, so they can prompt
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.WriteString("yes\n")
+	_ = w.Close()
+	oldStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = oldStdin }()
+
+	if err := RunPreDelete(cfg, env); err != nil {
+		t.Fatalf("RunPreDelete failed: %v", err)
+	}
+	if env.Event != "" {
+		t.Error("expected caller's env to be left unmodified")
+	}
+
+	out, _ := os.ReadFile(eventPath)
+	fields := strings.Fields(string(out))
+	if len(fields) != 3 || fields[0] != "pre_delete" || fields[1] != "yes" {
+		t.Fatalf("expected WT_EVENT=pre_delete, the answer from stdin and WT_CONTEXT_FILE, got %q", out)
+	}
+	if _, err := os.Stat(fields[2]); !os.IsNotExist(err) {
+		t.Errorf("expected the context file to be removed after the hooks ran, got %v", err)
+	}
+
+	data, err := os.ReadFile(stdinPath)
+	if err != nil {
+		t.Fatalf("pre-delete hook did not run: %v", err)
+	}
+	var ctx struct {
+		Event         string `json:"event"`
+		Name          string `json:"name"`
+		Index         int    `json:"index"`
+		Force         bool   `json:"force"`
+		ComparisonRef string `json:"comparison_ref"`
+		Status        *struct {
+			Merged bool `json:"merged"`
+		} `json:"status"`
+		Worktrees []WorktreeRef `json:"worktrees"`
+	}
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		t.Fatalf("context is not valid JSON: %v\n%s", err, data)
+	}
+	if ctx.Event != EventPreDelete || ctx.Name != "test-wt" || ctx.Index != 3 || ctx.ComparisonRef != "main" {
+		t.Errorf("unexpected context: %s", data)
+	}
+	if ctx.Status == nil || !ctx.Status.Merged {
+		t.Errorf("expected merged status in context: %s", data)
+	}
+	if contains(string(data), `"worktrees":`) {
+		t.Errorf("expected no worktree list for per-worktree events: %s", data)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsAt(s, substr, 0))
 }
@@ -0,0 +1,352 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agarcher/wt/internal/config"
+	"github.com/agarcher/wt/internal/git"
+)
+
+// Info hook output formats, set with format: on the hook entry
+const (
+	InfoFormatText = "text"
+	InfoFormatJSON = "json"
+)
+
+// Info field types that control how a value is displayed
+const (
+	FieldTypeURL      = "url"
+	FieldTypePort     = "port"
+	FieldTypeDuration = "duration"
+	FieldTypeStatus   = "status"
+)
+
+// InfoField is a single value reported by a JSON info hook
+type InfoField struct {
+	Key   string // Key in the hook's JSON object, used for hook.<key> list columns
+	Label string // Display label (defaults to Key)
+	Type  string // One of the FieldType constants, or empty for plain values
+	Value any    // Decoded JSON scalar: string, json.Number or bool
+}
+
+// String returns the field's value as plain text
+func (f InfoField) String() string {
+	if f.Value == nil {
+		return ""
+	}
+	return fmt.Sprint(f.Value)
+}
+
+// InfoOutput is the combined output of a worktree's info hooks
+type InfoOutput struct {
+	Text   string      // Output of text-format hooks ("Key: value" lines and raw text)
+	Fields []InfoField // Fields reported by JSON-format hooks, in hook and key order
+}
+
+// Field returns the field with the given key (the last one wins if hooks repeat a key)
+func (o *InfoOutput) Field(key string) (InfoField, bool) {
+	if o == nil {
+		return InfoField{}, false
+	}
+	for i := len(o.Fields) - 1; i >= 0; i-- {
+		if o.Fields[i].Key == key {
+			return o.Fields[i], true
+		}
+	}
+	return InfoField{}, false
+}
+
+// typedValue is the long form of a JSON info field: {"value": ..., "type": ..., "label": ...}
+type typedValue struct {
+	Value any    `json:"value"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+// ParseInfoJSON parses the output of a JSON info hook. The output must be a single
+// object whose members are either scalars or {"value", "type", "label"} objects.
+// Member order is preserved.
+func ParseInfoJSON(data []byte) ([]InfoField, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("info hook output must be a JSON object")
+	}
+
+	var fields []InfoField
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid info hook JSON: %w", err)
+		}
+		key := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid info hook JSON for %q: %w", key, err)
+		}
+
+		field := InfoField{Key: key, Label: key}
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+			var tv typedValue
+			if err := decodeNumbers(trimmed, &tv); err != nil {
+				return nil, fmt.Errorf("invalid info hook JSON for %q: %w", key, err)
+			}
+			field.Value = tv.Value
+			field.Type = strings.ToLower(tv.Type)
+			if tv.Label != "" {
+				field.Label = tv.Label
+			}
+		} else if err := decodeNumbers(trimmed, &field.Value); err != nil {
+			return nil, fmt.Errorf("invalid info hook JSON for %q: %w", key, err)
+		}
+
+		// Nested values aren't displayable; null means "nothing to show"
+		switch field.Value.(type) {
+		case nil:
+			continue
+		case map[string]any, []any:
+			return nil, fmt.Errorf("info hook field %q must be a string, number or boolean", key)
+		}
+		fields = append(fields, field)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("invalid info hook JSON: %w", err)
+	}
+	return fields, nil
+}
+
+// decodeNumbers unmarshals data keeping numbers as json.Number so integers stay exact
+func decodeNumbers(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// DefaultInfoTimeout is how long an info hook may run before it is killed
+const DefaultInfoTimeout = 10 * time.Second
+
+// InfoCacheDirName is the directory inside a worktree's metadata dir holding cached info hook output
+const InfoCacheDirName = "wt-info-cache"
+
+// infoResult is the parsed output of a single info hook
+type infoResult struct {
+	text   string
+	fields []InfoField
+}
+
+// RunInfo runs info hooks concurrently and returns their combined output in config order.
+// Each hook is killed after its timeout, and hooks with cache_ttl reuse output cached in
+// the worktree's metadata dir until it is stale. A failing hook contributes nothing; the
+// first failure is returned along with the output of the hooks that succeeded.
+func RunInfo(cfg *config.Config, env *Env) (*InfoOutput, error) {
+	entries := cfg.Hooks.Info
+	if len(entries) == 0 {
+		return &InfoOutput{}, nil
+	}
+
+	env = WithPorts(withEvent(env, EventInfo), cfg.Resources)
+	contextFile, stdin, err := writeContextFile(env)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(contextFile) }()
+	input := hookInput{contextFile: contextFile, stdin: stdin}
+
+	results := make([]infoResult, len(entries))
+	errs := make([]error, len(entries))
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = runInfoHook(entry, env, input)
+		}()
+	}
+	wg.Wait()
+
+	var text strings.Builder
+	output := &InfoOutput{}
+	for i := range entries {
+		text.WriteString(results[i].text)
+		output.Fields = append(output.Fields, results[i].fields...)
+	}
+	output.Text = text.String()
+	return output, errors.Join(errs...)
+}
+
+// hookInput is the context given to info hooks: in $WT_CONTEXT_FILE and, as they can't
+// prompt anyway, on stdin
+type hookInput struct {
+	contextFile string
+	stdin       []byte
+}
+
+// runInfoHook runs a single info hook (or reads its cached output) and parses the result
+func runInfoHook(entry config.HookEntry, env *Env, input hookInput) (infoResult, error) {
+	var cachePath string
+	ttl := parseDuration(entry.CacheTTL, 0)
+	if ttl > 0 && env.Name != "" {
+		cachePath = filepath.Join(git.OpenMetadata(env.RepoRoot).Dir(env.Name), InfoCacheDirName, infoCacheKey(entry))
+		if out, ok := readInfoCache(cachePath, ttl); ok {
+			if result, err := parseInfoOutput(entry, out); err == nil {
+				return result, nil
+			}
+		}
+	}
+
+	out, err := runHookCapture(entry, env, input, WorkDir(EventInfo, env), parseDuration(entry.Timeout, DefaultInfoTimeout))
+	if err != nil {
+		return infoResult{}, err
+	}
+	result, err := parseInfoOutput(entry, out)
+	if err != nil {
+		return infoResult{}, err
+	}
+
+	if cachePath != "" {
+		writeInfoCache(cachePath, out)
+	}
+	return result, nil
+}
+
+// parseInfoOutput interprets an info hook's stdout according to its format
+func parseInfoOutput(entry config.HookEntry, out string) (infoResult, error) {
+	switch entry.Format {
+	case "", InfoFormatText:
+		return infoResult{text: out}, nil
+	case InfoFormatJSON:
+		fields, err := ParseInfoJSON([]byte(out))
+		if err != nil {
+			return infoResult{}, fmt.Errorf("%s: %w", entry.Script, err)
+		}
+		return infoResult{fields: fields}, nil
+	default:
+		return infoResult{}, fmt.Errorf("%s: unknown info hook format %q", entry.Script, entry.Format)
+	}
+}
+
+// runHookCapture executes a single hook and captures its stdout, killing it
+// (and anything it started) if it runs longer than timeout
+func runHookCapture(entry config.HookEntry, env *Env, input hookInput, workDir string, timeout time.Duration) (string, error) {
+	scriptPath := ResolveScript(entry, env.RepoRoot)
+
+	// Check if script exists
+	if _, err := os.Stat(scriptPath); err != nil {
+		return "", fmt.Errorf("hook script not found: %s", scriptPath)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Build the command so a timeout kills the whole tree where possible
+	cmd := exec.CommandContext(ctx, "/bin/bash", scriptPath)
+	cmd.Dir = workDir
+	killTreeOnCancel(cmd)
+	cmd.WaitDelay = time.Second
+
+	// Capture stdout, let stderr go to os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = bytes.NewReader(input.stdin)
+
+	// Set environment variables
+	cmd.Env = append(os.Environ(), env.ToEnvVars()...)
+	cmd.Env = append(cmd.Env, "WT_CONTEXT_FILE="+input.contextFile)
+
+	// Add custom environment variables from hook config
+	for k, v := range entry.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("%s: timed out after %s", entry.Script, timeout)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// parseDuration parses a duration from config, returning def if it is empty or invalid
+func parseDuration(value string, def time.Duration) time.Duration {
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// infoCacheKey returns the cache file name for a hook entry. Everything that can change
+// the hook's output besides the worktree itself (script, format, env) is part of the key.
+func infoCacheKey(entry config.HookEntry) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00", entry.Script, entry.Format)
+	keys := make([]string, 0, len(entry.Env))
+	for k := range entry.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(h, "%s=%s\x00", k, entry.Env[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16] + ".out"
+}
+
+// readInfoCache returns cached output if it was written less than ttl ago
+func readInfoCache(path string, ttl time.Duration) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) >= ttl {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// writeInfoCache stores hook output, but only while the worktree's metadata dir exists
+func writeInfoCache(path, output string) {
+	dir := filepath.Dir(path)
+	if _, err := os.Stat(filepath.Dir(dir)); err != nil {
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+
+	// Write then rename so concurrent readers never see a partial file
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.WriteString(output)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
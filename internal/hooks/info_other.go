@@ -0,0 +1,13 @@
+//go:build !unix
+
+package hooks
+
+import "os/exec"
+
+// killTreeOnCancel kills cmd when its context is done. Without process groups,
+// processes the hook started are left running.
+func killTreeOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
+}
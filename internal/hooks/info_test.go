@@ -0,0 +1,251 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agarcher/wt/internal/config"
+)
+
+func TestParseInfoJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []InfoField
+		wantErr bool
+	}{
+		{
+			name:  "scalars keep key order",
+			input: `{"url": "http://localhost:5173", "port": 5173, "ready": true}`,
+			want: []InfoField{
+				{Key: "url", Label: "url", Value: "http://localhost:5173"},
+				{Key: "port", Label: "port", Value: json.Number("5173")},
+				{Key: "ready", Label: "ready", Value: true},
+			},
+		},
+		{
+			name:  "typed values",
+			input: `{"web": {"value": "http://localhost:5173", "type": "URL", "label": "Dev server"}, "uptime": {"value": 90, "type": "duration"}}`,
+			want: []InfoField{
+				{Key: "web", Label: "Dev server", Type: FieldTypeURL, Value: "http://localhost:5173"},
+				{Key: "uptime", Label: "uptime", Type: FieldTypeDuration, Value: json.Number("90")},
+			},
+		},
+		{
+			name:  "null values are skipped",
+			input: `{"db": null, "port": 1}`,
+			want:  []InfoField{{Key: "port", Label: "port", Value: json.Number("1")}},
+		},
+		{
+			name:  "empty object",
+			input: `{}`,
+			want:  nil,
+		},
+		{name: "not an object", input: `["a"]`, wantErr: true},
+		{name: "plain text", input: "Port: 3000\n", wantErr: true},
+		{name: "nested value", input: `{"db": {"value": {"host": "x"}}}`, wantErr: true},
+		{name: "truncated", input: `{"port": 1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInfoJSON([]byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d fields, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("field %d: expected %+v, got %+v", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunInfoMixedFormats(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	textScript := filepath.Join(tmpDir, "text.sh")
+	if err := os.WriteFile(textScript, []byte("#!/bin/bash\necho \"Database: dev_$WT_NAME\"\n"), 0755); err != nil {
+		t.Fatalf("failed to write test script: %v", err)
+	}
+	jsonScript := filepath.Join(tmpDir, "json.sh")
+	if err := os.WriteFile(jsonScript, []byte("#!/bin/bash\necho '{\"port\": {\"value\": '$((5173 + WT_INDEX * 10))', \"type\": \"port\"}}'\n"), 0755); err != nil {
+		t.Fatalf("failed to write test script: %v", err)
+	}
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			Info: []config.HookEntry{
+				{Script: textScript},
+				{Script: jsonScript, Format: InfoFormatJSON},
+			},
+		},
+	}
+	env := &Env{Name: "test-wt", Path: tmpDir, RepoRoot: tmpDir, WorktreeDir: "worktrees", Index: 2}
+
+	output, err := RunInfo(cfg, env)
+	if err != nil {
+		t.Fatalf("RunInfo failed: %v", err)
+	}
+	if output.Text != "Database: dev_test-wt\n" {
+		t.Errorf("unexpected text output: %q", output.Text)
+	}
+	port, ok := output.Field("port")
+	if !ok {
+		t.Fatalf("expected port field, got %+v", output.Fields)
+	}
+	if port.String() != "5193" || port.Type != FieldTypePort {
+		t.Errorf("unexpected port field: %+v", port)
+	}
+	if _, ok := output.Field("missing"); ok {
+		t.Error("expected no field for unknown key")
+	}
+}
+
+func TestRunInfoInvalidJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	script := filepath.Join(tmpDir, "bad.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/bash\necho 'Port: 3000'\n"), 0755); err != nil {
+		t.Fatalf("failed to write test script: %v", err)
+	}
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			Info: []config.HookEntry{{Script: script, Format: InfoFormatJSON}},
+		},
+	}
+	env := &Env{Name: "test-wt", Path: tmpDir, RepoRoot: tmpDir, WorktreeDir: "worktrees"}
+
+	if _, err := RunInfo(cfg, env); err == nil {
+		t.Error("expected error for non-JSON output from a json info hook")
+	}
+}
+
+func TestRunInfoReceivesContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	// The context is on stdin and in $WT_CONTEXT_FILE
+	script := writeScript(t, tmpDir, "context.sh", `grep -q '"name":"test-wt"' && echo "Stdin: test-wt"
+grep -q '"event":"info"' "$WT_CONTEXT_FILE" && echo "File: info"
+`)
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			Info: []config.HookEntry{{Script: script}},
+		},
+	}
+	env := &Env{Name: "test-wt", Path: tmpDir, RepoRoot: tmpDir, WorktreeDir: "worktrees"}
+
+	output, err := RunInfo(cfg, env)
+	if err != nil {
+		t.Fatalf("RunInfo failed: %v", err)
+	}
+	if output.Text != "Stdin: test-wt\nFile: info\n" {
+		t.Errorf("unexpected info output %q", output.Text)
+	}
+}
+
+func TestRunInfoRunsHooksConcurrently(t *testing.T) {
+	tmpDir := t.TempDir()
+	var entries []config.HookEntry
+	for _, name := range []string{"a.sh", "b.sh", "c.sh"} {
+		entries = append(entries, config.HookEntry{Script: writeScript(t, tmpDir, name, "sleep 0.5\necho \""+name+": done\"\n")})
+	}
+
+	cfg := &config.Config{Hooks: config.HooksConfig{Info: entries}}
+	env := &Env{Name: "test-wt", Path: tmpDir, RepoRoot: tmpDir, WorktreeDir: "worktrees"}
+
+	start := time.Now()
+	output, err := RunInfo(cfg, env)
+	if err != nil {
+		t.Fatalf("RunInfo failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1200*time.Millisecond {
+		t.Errorf("expected hooks to run concurrently, took %s", elapsed)
+	}
+
+	// Output keeps config order regardless of completion order
+	if output.Text != "a.sh: done\nb.sh: done\nc.sh: done\n" {
+		t.Errorf("unexpected output order: %q", output.Text)
+	}
+}
+
+func TestRunInfoTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+	slow := writeScript(t, tmpDir, "slow.sh", "echo 'Slow: yes'\nsleep 10\n")
+	fast := writeScript(t, tmpDir, "fast.sh", "echo 'Fast: yes'\n")
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			Info: []config.HookEntry{
+				{Script: slow, Timeout: "200ms"},
+				{Script: fast},
+			},
+		},
+	}
+	env := &Env{Name: "test-wt", Path: tmpDir, RepoRoot: tmpDir, WorktreeDir: "worktrees"}
+
+	start := time.Now()
+	output, err := RunInfo(cfg, env)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected slow hook to be killed, took %s", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+
+	// The hook that finished in time still contributes its output
+	if output.Text != "Fast: yes\n" {
+		t.Errorf("expected only the fast hook's output, got %q", output.Text)
+	}
+}
+
+func TestRunInfoCache(t *testing.T) {
+	repoRoot, env := setupLogTest(t)
+	counter := filepath.Join(repoRoot, "runs")
+	script := writeScript(t, repoRoot, "count.sh", "echo x >> \""+counter+"\"\necho \"Runs: $(wc -l < \""+counter+"\" | tr -d ' ')\"\n")
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			Info: []config.HookEntry{{Script: script, CacheTTL: "1h"}},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		output, err := RunInfo(cfg, env)
+		if err != nil {
+			t.Fatalf("RunInfo failed: %v", err)
+		}
+		if output.Text != "Runs: 1\n" {
+			t.Errorf("run %d: expected cached output, got %q", i, output.Text)
+		}
+	}
+
+	// Stale cache entries are refreshed
+	cacheDir := filepath.Join(repoRoot, ".git", "worktrees", "test-wt", InfoCacheDirName)
+	files, _ := os.ReadDir(cacheDir)
+	if len(files) != 1 {
+		t.Fatalf("expected 1 cache file, got %d", len(files))
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(cacheDir, files[0].Name()), old, old); err != nil {
+		t.Fatalf("failed to age cache file: %v", err)
+	}
+	output, _ := RunInfo(cfg, env)
+	if output.Text != "Runs: 2\n" {
+		t.Errorf("expected stale cache to be refreshed, got %q", output.Text)
+	}
+}
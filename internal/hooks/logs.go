@@ -0,0 +1,329 @@
+package hooks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agarcher/wt/internal/git"
+)
+
+const (
+	// LogDirName is the directory inside a worktree's metadata dir that holds hook logs
+	LogDirName = "wt-logs"
+
+	// DefaultLogMaxFiles is the number of hook logs kept per worktree when not configured
+	DefaultLogMaxFiles = 50
+	// DefaultLogMaxBytes is the total hook log size kept per worktree when not configured
+	DefaultLogMaxBytes = 5 * 1024 * 1024
+
+	// logTimeFormat is used for log file names so they sort chronologically
+	logTimeFormat = "20060102T150405.000"
+	// logFieldPrefix marks header and footer lines written by wt
+	logFieldPrefix = "# wt "
+	// maxLogNameAttempts bounds the suffixes tried when log names collide
+	maxLogNameAttempts = 100
+)
+
+// LogEntry describes a single persisted hook execution
+type LogEntry struct {
+	Path     string
+	Event    string
+	Script   string
+	Started  time.Time
+	PID      int // Process of the wt running the hook, which writes the footer; 0 if not recorded
+	ExitCode int
+	Duration time.Duration
+	Complete bool // false while the hook is still running (or if wt was interrupted)
+}
+
+// Stopped reports whether an incomplete log's wt process is gone, so the log will
+// never be finished. known is false if that can't be told: the PID wasn't recorded
+// or processes can't be checked on this platform.
+func (e LogEntry) Stopped() (stopped, known bool) {
+	if e.Complete {
+		return false, true
+	}
+	if e.PID == 0 {
+		return false, false
+	}
+	alive, known := processAlive(e.PID)
+	return !alive && known, known
+}
+
+// LogDir returns the hook log directory for a worktree
+func LogDir(repoRoot, worktreeName string) string {
+	return filepath.Join(git.OpenMetadata(repoRoot).Dir(worktreeName), LogDirName)
+}
+
+// DetachedLogDir returns the hook log directory for runs while a worktree has no
+// metadata dir (pre_create, post_delete): <common dir>/wt-logs/<name>. Unlike the
+// worktree's own logs, these outlive the worktree.
+func DetachedLogDir(repoRoot, worktreeName string) string {
+	return filepath.Join(git.OpenMetadata(repoRoot).CommonDir(), LogDirName, worktreeName)
+}
+
+// logDirFor returns where a hook run for a worktree is logged: its metadata dir if it
+// exists, the detached log dir otherwise
+func logDirFor(repoRoot, worktreeName string) string {
+	dir := LogDir(repoRoot, worktreeName)
+	if _, err := os.Stat(filepath.Dir(dir)); err != nil {
+		return DetachedLogDir(repoRoot, worktreeName)
+	}
+	return dir
+}
+
+// hookLog is an open log file receiving a hook's stdout and stderr
+type hookLog struct {
+	dir     string
+	file    *os.File
+	started time.Time
+
+	mu       sync.Mutex
+	lastByte byte
+}
+
+// openHookLog creates a new log file for a hook run.
+// Returns nil if the log cannot be created; logging never blocks a hook from running.
+func openHookLog(dir, event, scriptPath string) *hookLog {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil
+	}
+
+	started := time.Now()
+	file, err := createLogFile(dir, started.Format(logTimeFormat), event, filepath.Base(scriptPath))
+	if err != nil {
+		return nil
+	}
+
+	l := &hookLog{dir: dir, file: file, started: started, lastByte: '\n'}
+	_, _ = fmt.Fprintf(file, "%sevent: %s\n", logFieldPrefix, event)
+	_, _ = fmt.Fprintf(file, "%sscript: %s\n", logFieldPrefix, scriptPath)
+	_, _ = fmt.Fprintf(file, "%sstarted: %s\n", logFieldPrefix, started.Format(time.RFC3339Nano))
+	_, _ = fmt.Fprintf(file, "%spid: %d\n", logFieldPrefix, os.Getpid())
+	return l
+}
+
+// createLogFile creates a new log file named for its start time, event and script.
+// Runs of the same hook within a millisecond get a numbered suffix after the time,
+// which keeps the names sorting in the order they were created.
+func createLogFile(dir, stamp, event, script string) (*os.File, error) {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("%s-%s-%s.log", stamp, event, script)
+		if n > 1 {
+			name = fmt.Sprintf("%s.%02d-%s-%s.log", stamp, n, event, script)
+		}
+		file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil || !os.IsExist(err) || n >= maxLogNameAttempts {
+			return file, err
+		}
+	}
+}
+
+// Write appends hook output to the log. It is safe for concurrent use by
+// the stdout and stderr copiers.
+func (l *hookLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(p) > 0 {
+		l.lastByte = p[len(p)-1]
+	}
+	return l.file.Write(p)
+}
+
+// finish records the exit code and duration and closes the log
+func (l *hookLog) finish(runErr error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	exitCode := 0
+	if runErr != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+
+	// Footer must start on its own line even if the hook didn't end with a newline
+	if l.lastByte != '\n' {
+		_, _ = l.file.WriteString("\n")
+	}
+	_, _ = fmt.Fprintf(l.file, "%sexit: %d\n", logFieldPrefix, exitCode)
+	_, _ = fmt.Fprintf(l.file, "%sduration: %s\n", logFieldPrefix, time.Since(l.started).Round(time.Millisecond))
+	_ = l.file.Close()
+}
+
+// ParseLog reads the header and footer fields of a hook log
+func ParseLog(path string) (LogEntry, error) {
+	entry := LogEntry{Path: path}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return entry, err
+	}
+	defer func() { _ = f.Close() }()
+
+	// The header is the lines wt writes before the hook starts, ending with the pid, and
+	// the footer the last two lines of a complete log. Hook output in between may contain
+	// lines that look like either, so only those positions are read.
+	inHeader := true
+	var last [2]string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inHeader {
+			key, value, ok := cutLogField(line)
+			switch {
+			case ok && key == "event":
+				entry.Event = value
+				continue
+			case ok && key == "script":
+				entry.Script = value
+				continue
+			case ok && key == "started":
+				entry.Started, _ = time.Parse(time.RFC3339Nano, value)
+				continue
+			case ok && key == "pid":
+				entry.PID, _ = strconv.Atoi(value)
+				inHeader = false
+				continue
+			}
+			inHeader = false
+		}
+		last[0], last[1] = last[1], line
+	}
+	if err := scanner.Err(); err != nil {
+		return entry, err
+	}
+
+	exitKey, exitValue, _ := cutLogField(last[0])
+	durationKey, durationValue, _ := cutLogField(last[1])
+	if exitKey == "exit" && durationKey == "duration" {
+		if code, err := strconv.Atoi(exitValue); err == nil {
+			entry.ExitCode = code
+			entry.Complete = true
+			entry.Duration, _ = time.ParseDuration(durationValue)
+		}
+	}
+
+	return entry, nil
+}
+
+// cutLogField splits a header or footer line into its key and value
+func cutLogField(line string) (key, value string, ok bool) {
+	rest, ok := strings.CutPrefix(line, logFieldPrefix)
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, ": ")
+}
+
+// ListWorktreeLogs returns a worktree's hook logs, from its metadata dir and its
+// detached log dir, oldest first. If event is non-empty, only logs for that event
+// are returned.
+func ListWorktreeLogs(repoRoot, worktreeName, event string) ([]LogEntry, error) {
+	var entries []LogEntry
+	for _, dir := range []string{LogDir(repoRoot, worktreeName), DetachedLogDir(repoRoot, worktreeName)} {
+		logs, err := ListLogs(dir, event)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, logs...)
+	}
+	// File names start with the time the hook started, so they sort chronologically
+	sort.SliceStable(entries, func(i, j int) bool {
+		return filepath.Base(entries[i].Path) < filepath.Base(entries[j].Path)
+	})
+	return entries, nil
+}
+
+// ListLogs returns the hook logs in dir, oldest first.
+// If event is non-empty, only logs for that event are returned.
+func ListLogs(dir, event string) ([]LogEntry, error) {
+	names, err := logFileNames(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	for _, name := range names {
+		entry, err := ParseLog(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if event != "" && entry.Event != event {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// PruneLogs removes the oldest hook logs until at most maxFiles remain and their
+// combined size is at most maxBytes. Zero values use the defaults. The newest log
+// is always kept.
+func PruneLogs(dir string, maxFiles int, maxBytes int64) error {
+	if maxFiles <= 0 {
+		maxFiles = DefaultLogMaxFiles
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultLogMaxBytes
+	}
+
+	names, err := logFileNames(dir)
+	if err != nil {
+		return err
+	}
+
+	sizes := make([]int64, len(names))
+	var total int64
+	for i, name := range names {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			sizes[i] = info.Size()
+			total += sizes[i]
+		}
+	}
+
+	remaining := len(names)
+	for i := 0; i < len(names)-1; i++ {
+		if remaining <= maxFiles && total <= maxBytes {
+			break
+		}
+		if err := os.Remove(filepath.Join(dir, names[i])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		remaining--
+		total -= sizes[i]
+	}
+	return nil
+}
+
+// logFileNames returns the log file names in dir sorted oldest first
+func logFileNames(dir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range dirEntries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".log") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,295 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agarcher/wt/internal/config"
+)
+
+// setupLogTest creates a fake repo with a worktree metadata dir so hook logs are written
+func setupLogTest(t *testing.T) (repoRoot string, env *Env) {
+	t.Helper()
+
+	repoRoot = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoRoot, ".git", "worktrees", "test-wt"), 0755); err != nil {
+		t.Fatalf("failed to create metadata dir: %v", err)
+	}
+
+	env = &Env{
+		Name:        "test-wt",
+		Path:        repoRoot,
+		Branch:      "test-branch",
+		RepoRoot:    repoRoot,
+		WorktreeDir: "worktrees",
+	}
+	return repoRoot, env
+}
+
+func writeScript(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/bash\n"+content), 0755); err != nil {
+		t.Fatalf("failed to write test script: %v", err)
+	}
+	return path
+}
+
+func TestRunPostCreateWritesLog(t *testing.T) {
+	repoRoot, env := setupLogTest(t)
+	scriptPath := writeScript(t, repoRoot, "post-create.sh", "echo to-stdout\necho to-stderr >&2\n")
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			PostCreate: []config.HookEntry{{Script: scriptPath}},
+		},
+	}
+
+	if err := RunPostCreate(cfg, env); err != nil {
+		t.Fatalf("RunPostCreate failed: %v", err)
+	}
+
+	entries, err := ListLogs(LogDir(repoRoot, "test-wt"), "")
+	if err != nil {
+		t.Fatalf("ListLogs failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Event != EventPostCreate {
+		t.Errorf("expected event %q, got %q", EventPostCreate, entry.Event)
+	}
+	if entry.Script != scriptPath {
+		t.Errorf("expected script %q, got %q", scriptPath, entry.Script)
+	}
+	if !entry.Complete {
+		t.Error("expected log to be complete")
+	}
+	if entry.ExitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", entry.ExitCode)
+	}
+	if entry.Started.IsZero() {
+		t.Error("expected start time to be recorded")
+	}
+
+	data, err := os.ReadFile(entry.Path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	for _, want := range []string{"to-stdout", "to-stderr"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected log to contain %q, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestHookLogRecordsFailure(t *testing.T) {
+	repoRoot, env := setupLogTest(t)
+	scriptPath := writeScript(t, repoRoot, "fail.sh", "printf 'no newline'\nexit 3\n")
+
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			PreDelete: []config.HookEntry{{Script: scriptPath}},
+		},
+	}
+
+	if err := RunPreDelete(cfg, env); err == nil {
+		t.Fatal("expected error from failing hook, got nil")
+	}
+
+	entries, err := ListLogs(LogDir(repoRoot, "test-wt"), EventPreDelete)
+	if err != nil {
+		t.Fatalf("ListLogs failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(entries))
+	}
+	if !entries[0].Complete || entries[0].ExitCode != 3 {
+		t.Errorf("expected complete log with exit code 3, got complete=%v exit=%d", entries[0].Complete, entries[0].ExitCode)
+	}
+
+	// Event filter excludes other events
+	entries, _ = ListLogs(LogDir(repoRoot, "test-wt"), EventPostCreate)
+	if len(entries) != 0 {
+		t.Errorf("expected no post_create logs, got %d", len(entries))
+	}
+}
+
+func TestHookLogWithoutMetadataDir(t *testing.T) {
+	repoRoot := t.TempDir()
+	scriptPath := writeScript(t, repoRoot, "hook.sh", "echo hi\n")
+
+	env := &Env{Name: "test-wt", Path: repoRoot, RepoRoot: repoRoot, WorktreeDir: "worktrees"}
+	cfg := &config.Config{
+		Hooks: config.HooksConfig{
+			PreCreate: []config.HookEntry{{Script: scriptPath}},
+		},
+	}
+
+	if err := os.MkdirAll(filepath.Join(repoRoot, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunPreCreate(cfg, env); err != nil {
+		t.Fatalf("RunPreCreate failed: %v", err)
+	}
+	if _, err := os.Stat(LogDir(repoRoot, "test-wt")); !os.IsNotExist(err) {
+		t.Error("expected no log dir inside a missing worktree metadata dir")
+	}
+	entries, err := ListLogs(filepath.Join(repoRoot, ".git", "wt-logs", "test-wt"), EventPreCreate)
+	if err != nil || len(entries) != 1 || !entries[0].Complete {
+		t.Fatalf("expected a complete pre_create log under the common dir, got %+v (%v)", entries, err)
+	}
+
+	// Once the worktree exists, its logs are listed together
+	if err := os.MkdirAll(filepath.Join(repoRoot, ".git", "worktrees", "test-wt"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Hooks.PostCreate = cfg.Hooks.PreCreate
+	if err := RunPostCreate(cfg, env); err != nil {
+		t.Fatalf("RunPostCreate failed: %v", err)
+	}
+	entries, err = ListWorktreeLogs(repoRoot, "test-wt", "")
+	if err != nil || len(entries) != 2 || entries[0].Event != EventPreCreate || entries[1].Event != EventPostCreate {
+		t.Errorf("expected pre_create then post_create logs, got %+v (%v)", entries, err)
+	}
+}
+
+func TestParseLogIgnoresLookalikeOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spoofed.log")
+	content := "# wt event: post_create\n# wt script: /tmp/setup.sh\n# wt started: 2026-01-02T03:04:05Z\n# wt pid: 1\n" +
+		"# wt event: pre_delete\n# wt exit: 0\n# wt duration: 1s\nstill running...\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	entry, err := ParseLog(path)
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if entry.Event != "post_create" {
+		t.Errorf("hook output overrode the header: event %q", entry.Event)
+	}
+	if entry.Complete {
+		t.Error("hook output before the last lines was read as the footer")
+	}
+
+	// The real footer is the last two lines
+	content += "# wt exit: 3\n# wt duration: 2s\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	if entry, err = ParseLog(path); err != nil || !entry.Complete || entry.ExitCode != 3 || entry.Duration != 2*time.Second {
+		t.Errorf("expected exit 3 after 2s, got %+v (%v)", entry, err)
+	}
+}
+
+func TestParseLogIncomplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "running.log")
+	content := "# wt event: post_create\n# wt script: /tmp/setup.sh\n# wt started: 2026-01-02T03:04:05Z\ninstalling...\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	entry, err := ParseLog(path)
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if entry.Complete {
+		t.Error("expected log without footer to be incomplete")
+	}
+	if entry.Event != "post_create" || entry.Script != "/tmp/setup.sh" {
+		t.Errorf("unexpected header fields: %+v", entry)
+	}
+}
+
+func TestLogEntryStopped(t *testing.T) {
+	gone := exec.Command("true")
+	if err := gone.Run(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		entry   LogEntry
+		stopped bool
+		known   bool
+	}{
+		{"complete", LogEntry{PID: gone.Process.Pid, Complete: true}, false, true},
+		{"no pid recorded", LogEntry{}, false, false},
+		{"process running", LogEntry{PID: os.Getpid()}, false, true},
+		{"process gone", LogEntry{PID: gone.Process.Pid}, true, true},
+	}
+	for _, tt := range tests {
+		if stopped, known := tt.entry.Stopped(); stopped != tt.stopped || known != tt.known {
+			t.Errorf("%s: Stopped() = %v, %v, want %v, %v", tt.name, stopped, known, tt.stopped, tt.known)
+		}
+	}
+}
+
+func TestCreateLogFileAvoidsCollisions(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 3; i++ {
+		f, err := createLogFile(dir, "20260102T030405.000", EventPostCreate, "setup.sh")
+		if err != nil {
+			t.Fatalf("createLogFile failed: %v", err)
+		}
+		_, _ = fmt.Fprintf(f, "run %d\n", i)
+		_ = f.Close()
+	}
+
+	// Each run keeps its own log, sorting in the order they were created
+	names, err := logFileNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 {
+		t.Fatalf("expected 3 logs, got %v", names)
+	}
+	for i, name := range names {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		if want := fmt.Sprintf("run %d\n", i); string(data) != want {
+			t.Errorf("log %s = %q, want %q", name, data, want)
+		}
+	}
+}
+
+func TestPruneLogs(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxFiles int
+		maxBytes int64
+		want     []string
+	}{
+		{"count limit", 2, 1 << 20, []string{"3.log", "4.log"}},
+		{"size limit", 100, 25, []string{"3.log", "4.log"}},
+		{"newest always kept", 100, 1, []string{"4.log"}},
+		{"within limits", 10, 1 << 20, []string{"1.log", "2.log", "3.log", "4.log"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{"1.log", "2.log", "3.log", "4.log"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("0123456789"), 0644); err != nil {
+					t.Fatalf("failed to write log: %v", err)
+				}
+			}
+
+			if err := PruneLogs(dir, tt.maxFiles, tt.maxBytes); err != nil {
+				t.Fatalf("PruneLogs failed: %v", err)
+			}
+
+			got, _ := logFileNames(dir)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
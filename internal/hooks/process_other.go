@@ -0,0 +1,8 @@
+//go:build !unix
+
+package hooks
+
+// processAlive can't tell whether a process exists on this platform
+func processAlive(pid int) (alive, known bool) {
+	return false, false
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package hooks
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process exists; known is false if that can't be told
+func processAlive(pid int) (alive, known bool) {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM), true
+}
@@ -0,0 +1,220 @@
+package provision
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agarcher/wt/internal/config"
+)
+
+// writeTree creates files under dir, keyed by slash-separated relative path
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCloneDirs(t *testing.T) {
+	vars := setupProvisionTest(t)
+	writeTree(t, vars.RepoRoot, map[string]string{
+		"target/debug/app":      "binary",
+		"target/debug/deps/a.d": "deps",
+	})
+	mtime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(vars.RepoRoot, "target/debug/app"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("app", filepath.Join(vars.RepoRoot, "target/debug/latest")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.CloneDirs = []config.CloneDirConfig{{Path: "node_modules"}, {Path: "target"}}
+
+	var progressCalls int
+	stats, err := CloneDirs(cfg, vars, func(CloneStats) { progressCalls++ })
+	if err != nil {
+		t.Fatalf("CloneDirs failed: %v", err)
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("expected stats for 2 directories, got %v", stats)
+	}
+	target := stats[1]
+	if target.Path != "target" || target.Files != 2 || target.Bytes != int64(len("binary")+len("deps")) {
+		t.Errorf("unexpected stats for target: %+v", target)
+	}
+	if target.Reflinked+target.Written != target.Bytes || target.Hardlinked != 0 {
+		t.Errorf("expected files to be reflinked or copied, never hard linked by default: %+v", target)
+	}
+	if progressCalls != stats[0].Files+stats[1].Files {
+		t.Errorf("expected a progress call per file, got %d", progressCalls)
+	}
+
+	data, err := os.ReadFile(filepath.Join(vars.Path, "target/debug/deps/a.d"))
+	if err != nil || string(data) != "deps" {
+		t.Errorf("expected cloned file content, got %q (%v)", data, err)
+	}
+	if link, err := os.Readlink(filepath.Join(vars.Path, "target/debug/latest")); err != nil || link != "app" {
+		t.Errorf("expected symlink to be recreated, got %q (%v)", link, err)
+	}
+	info, err := os.Stat(filepath.Join(vars.Path, "target/debug/app"))
+	if err != nil || !info.ModTime().Equal(mtime) {
+		t.Errorf("expected modification time to be preserved, got %v (%v)", info, err)
+	}
+}
+
+func TestCloneDirsHardlinkMethod(t *testing.T) {
+	vars := setupProvisionTest(t)
+	writeTree(t, vars.RepoRoot, map[string]string{"target/debug/app": "binary"})
+
+	cfg := config.DefaultConfig()
+	cfg.CloneDirs = []config.CloneDirConfig{{Path: "target", Method: config.CloneMethodHardlink}}
+
+	stats, err := CloneDirs(cfg, vars, nil)
+	if err != nil {
+		t.Fatalf("CloneDirs failed: %v", err)
+	}
+	// Reflinked where supported, otherwise hard linked: the source and worktree share a filesystem
+	if len(stats) != 1 || stats[0].Written != 0 || stats[0].Reflinked+stats[0].Hardlinked != stats[0].Bytes {
+		t.Errorf("expected files to be reflinked or hard linked, got %+v", stats)
+	}
+}
+
+func TestClonerReadOnlyDirs(t *testing.T) {
+	src := t.TempDir()
+	dest := filepath.Join(t.TempDir(), "mod")
+	writeTree(t, src, map[string]string{"pkg@v1/go.mod": "module pkg\n"})
+	// Like the Go module cache, whose directories are read-only
+	for _, dir := range []string{filepath.Join(src, "pkg@v1"), src} {
+		if err := os.Chmod(dir, 0555); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, dir := range []string{src, filepath.Join(src, "pkg@v1"), dest, filepath.Join(dest, "pkg@v1")} {
+			_ = os.Chmod(dir, 0755)
+		}
+	})
+
+	c := &cloner{method: MethodCopy, stats: CloneStats{Path: "mod"}}
+	if err := c.cloneTree(src, dest); err != nil {
+		t.Fatalf("cloneTree failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "pkg@v1/go.mod")); err != nil || string(data) != "module pkg\n" {
+		t.Errorf("expected cloned file content, got %q (%v)", data, err)
+	}
+	for _, dir := range []string{dest, filepath.Join(dest, "pkg@v1")} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0555 {
+			t.Errorf("expected %s to end up read-only like its source, got %v", dir, info.Mode())
+		}
+	}
+}
+
+func TestClonerFallsBackToCopy(t *testing.T) {
+	src := t.TempDir()
+	dest := filepath.Join(t.TempDir(), "node_modules")
+	writeTree(t, src, map[string]string{"pkg/index.js": "module.exports = 1\n"})
+
+	c := &cloner{method: MethodCopy, stats: CloneStats{Path: "node_modules"}}
+	if err := c.cloneTree(src, dest); err != nil {
+		t.Fatalf("cloneTree failed: %v", err)
+	}
+	if c.stats.Written != int64(len("module.exports = 1\n")) || c.stats.Reflinked != 0 || c.stats.Hardlinked != 0 {
+		t.Errorf("expected a plain copy, got %+v", c.stats)
+	}
+
+	// Writing to the copy must not affect the source
+	if err := os.WriteFile(filepath.Join(dest, "pkg/index.js"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(src, "pkg/index.js"))
+	if string(data) != "module.exports = 1\n" {
+		t.Errorf("source was modified through the copy: %q", data)
+	}
+}
+
+func TestClonerKeepsReflinkOnOtherErrors(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reflinks are only attempted on Linux")
+	}
+	src := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(src, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A destination that can't be created isn't a reason to fall back to hard links
+	c := &cloner{method: MethodReflink, fallback: MethodHardlink}
+	if err := c.cloneFile(src, filepath.Join(t.TempDir(), "missing", "a.txt"), info); err == nil {
+		t.Fatal("expected an error for a missing destination directory")
+	}
+	if c.method != MethodReflink || c.stats.Hardlinked != 0 {
+		t.Errorf("expected the reflink method to be kept, got %v with %+v", c.method, c.stats)
+	}
+}
+
+func TestCloneDirsSkipsAndErrors(t *testing.T) {
+	vars := setupProvisionTest(t)
+	if err := os.MkdirAll(filepath.Join(vars.Path, "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.CloneDirs = []config.CloneDirConfig{{Path: "node_modules"}, {Path: ".env"}}
+
+	stats, err := CloneDirs(cfg, vars, nil)
+	if err == nil || !strings.Contains(err.Error(), "must be directories") {
+		t.Errorf("expected error for non-directory entry, got %v", err)
+	}
+	if len(stats) == 0 || !stats[0].Skipped {
+		t.Fatalf("expected existing node_modules to be skipped, got %+v", stats)
+	}
+	if got := stats[0].String(); got != "node_modules: skipped (already exists)" {
+		t.Errorf("unexpected summary %q", got)
+	}
+}
+
+func TestCloneStatsString(t *testing.T) {
+	s := CloneStats{Path: "node_modules", Files: 3, Bytes: 3 << 20, Reflinked: 2 << 20, Written: 1 << 20, Duration: 1234 * time.Millisecond}
+	want := "node_modules: 3 files, 3.0 MB (reflinked 2.0 MB, wrote 1.0 MB) in 1.2s"
+	if got := s.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{5 << 30, "5.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
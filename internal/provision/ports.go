@@ -0,0 +1,152 @@
+package provision
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agarcher/wt/internal/config"
+)
+
+// maxPort is the highest valid TCP port
+const maxPort = 65535
+
+// Port is a named port, or range of ports, allocated to a worktree from resources:
+type Port struct {
+	Name  string
+	Port  int // First port
+	Count int // Number of ports in the range; 1 for a single port
+}
+
+// End returns the last port in the range
+func (p Port) End() int {
+	return p.Port + p.Count - 1
+}
+
+// String formats the port for display, e.g. "5193" or "6020-6029"
+func (p Port) String() string {
+	if p.Count > 1 {
+		return fmt.Sprintf("%d-%d", p.Port, p.End())
+	}
+	return strconv.Itoa(p.Port)
+}
+
+// EnvName returns the environment variable holding the port, e.g. WT_PORT_WEB for "web"
+func (p Port) EnvName() string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, p.Name)
+	return "WT_PORT_" + strings.ToUpper(name)
+}
+
+// PortJSON is the JSON representation of a port; End is only set for ranges
+type PortJSON struct {
+	Port int `json:"port"`
+	End  int `json:"end,omitempty"`
+}
+
+// PortsJSON returns ports keyed by name for JSON output
+func PortsJSON(ports []Port) map[string]PortJSON {
+	if len(ports) == 0 {
+		return nil
+	}
+	m := make(map[string]PortJSON, len(ports))
+	for _, p := range ports {
+		j := PortJSON{Port: p.Port}
+		if p.Count > 1 {
+			j.End = p.End()
+		}
+		m[p.Name] = j
+	}
+	return m
+}
+
+// ResolvePorts evaluates the configured resources for a worktree index, sorted by name
+func ResolvePorts(resources config.ResourcesConfig, index int) ([]Port, error) {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ports := make([]Port, 0, len(names))
+	for _, name := range names {
+		res := resources[name]
+		start, err := EvalExpr(res.Port, index)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q: %w", name, err)
+		}
+		p := Port{Name: name, Port: start, Count: max(res.Count, 1)}
+		if p.Port < 1 || p.End() > maxPort {
+			return nil, fmt.Errorf("resource %q: port %s for index %d is outside 1-%d", name, p, index, maxPort)
+		}
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+// PortConflict describes a port that isn't free for a worktree
+type PortConflict struct {
+	Port   Port
+	Number int    // The conflicting port number within Port's range
+	Reason string // e.g. "in use" or `also allocated to "other" (web)`
+}
+
+// String formats the conflict for display, e.g. "web 5193: in use"
+func (c PortConflict) String() string {
+	return fmt.Sprintf("%s %d: %s", c.Port.Name, c.Number, c.Reason)
+}
+
+// CheckPorts reports ports that overlap with another worktree's allocation (others is
+// keyed by worktree name) or that can't be bound because something is listening on them.
+func CheckPorts(ports []Port, others map[string][]Port) []PortConflict {
+	var conflicts []PortConflict
+
+	owners := make([]string, 0, len(others))
+	for name := range others {
+		owners = append(owners, name)
+	}
+	sort.Strings(owners)
+
+	for _, p := range ports {
+		for n := p.Port; n <= p.End(); n++ {
+			if reason := allocatedTo(n, owners, others); reason != "" {
+				conflicts = append(conflicts, PortConflict{Port: p, Number: n, Reason: reason})
+			} else if !portFree(n) {
+				conflicts = append(conflicts, PortConflict{Port: p, Number: n, Reason: "in use"})
+			}
+		}
+	}
+	return conflicts
+}
+
+// allocatedTo describes which other worktree's resource covers port n, if any
+func allocatedTo(n int, owners []string, others map[string][]Port) string {
+	for _, owner := range owners {
+		for _, p := range others[owner] {
+			if n >= p.Port && n <= p.End() {
+				return fmt.Sprintf("also allocated to %q (%s)", owner, p.Name)
+			}
+		}
+	}
+	return ""
+}
+
+// portFree reports whether port n can be bound on both the loopback and wildcard
+// addresses. Both are probed because some platforms allow binding the wildcard
+// address while another process listens on loopback.
+func portFree(n int) bool {
+	for _, addr := range []string{"127.0.0.1", ""} {
+		l, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(n)))
+		if err != nil {
+			return false
+		}
+		_ = l.Close()
+	}
+	return true
+}
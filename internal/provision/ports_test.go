@@ -0,0 +1,122 @@
+package provision
+
+import (
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/agarcher/wt/internal/config"
+)
+
+func TestResolvePorts(t *testing.T) {
+	resources := config.ResourcesConfig{
+		"web":     {Port: "5173+index*10"},
+		"db":      {Port: "5432+index"},
+		"workers": {Port: "6000+index*10", Count: 10},
+	}
+
+	ports, err := ResolvePorts(resources, 2)
+	if err != nil {
+		t.Fatalf("ResolvePorts failed: %v", err)
+	}
+	want := []Port{
+		{Name: "db", Port: 5434, Count: 1},
+		{Name: "web", Port: 5193, Count: 1},
+		{Name: "workers", Port: 6020, Count: 10},
+	}
+	if !reflect.DeepEqual(ports, want) {
+		t.Errorf("ResolvePorts = %+v, want %+v", ports, want)
+	}
+	if got := ports[2].String(); got != "6020-6029" {
+		t.Errorf("range String() = %q", got)
+	}
+
+	tests := []struct {
+		name     string
+		resource config.PortConfig
+		want     string
+	}{
+		{"invalid expression", config.PortConfig{Port: "5173+"}, "invalid expression"},
+		{"empty", config.PortConfig{}, "invalid expression"},
+		{"too high", config.PortConfig{Port: "65530+index*10"}, "outside 1-65535"},
+		{"range too high", config.PortConfig{Port: "65530", Count: 10}, "outside 1-65535"},
+		{"zero", config.PortConfig{Port: "index-2"}, "outside 1-65535"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolvePorts(config.ResourcesConfig{"web": tt.resource}, 2)
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), `resource "web"`) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPortEnvName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"web", "WT_PORT_WEB"},
+		{"api-gateway", "WT_PORT_API_GATEWAY"},
+		{"db.replica", "WT_PORT_DB_REPLICA"},
+	}
+	for _, tt := range tests {
+		if got := (Port{Name: tt.name}).EnvName(); got != tt.want {
+			t.Errorf("EnvName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPortsJSON(t *testing.T) {
+	got := PortsJSON([]Port{{Name: "web", Port: 5193, Count: 1}, {Name: "workers", Port: 6020, Count: 10}})
+	want := map[string]PortJSON{"web": {Port: 5193}, "workers": {Port: 6020, End: 6029}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PortsJSON = %+v, want %+v", got, want)
+	}
+	if PortsJSON(nil) != nil {
+		t.Error("expected nil for no ports")
+	}
+}
+
+func TestCheckPorts(t *testing.T) {
+	// Hold a port so the bind probe sees it in use
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+	busy := l.Addr().(*net.TCPAddr).Port
+
+	// A free port: bind then release one
+	l2, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	free := l2.Addr().(*net.TCPAddr).Port
+	_ = l2.Close()
+
+	ports := []Port{
+		{Name: "web", Port: busy, Count: 1},
+		{Name: "db", Port: free, Count: 1},
+		{Name: "api", Port: 40010, Count: 1},
+	}
+	others := map[string][]Port{
+		"other": {{Name: "workers", Port: 40005, Count: 10}},
+	}
+
+	conflicts := CheckPorts(ports, others)
+	var got []string
+	for _, c := range conflicts {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"web " + strconv.Itoa(busy) + ": in use",
+		`api 40010: also allocated to "other" (workers)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckPorts = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,228 @@
+// Package provision copies, links and renders files from the main repository
+// into new worktrees, as configured by the files: section of .wt.yaml.
+package provision
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agarcher/wt/internal/config"
+)
+
+// Actions reported for provisioned files
+const (
+	ActionCopied   = "copied"
+	ActionLinked   = "linked"
+	ActionRendered = "rendered"
+	ActionSkipped  = "skipped"
+)
+
+// TemplateSuffix is dropped from template file names when rendered into a worktree
+const TemplateSuffix = ".tmpl"
+
+// Result describes one provisioned file or directory
+type Result struct {
+	Action string // One of the Action* constants
+	Path   string // Destination, relative to the worktree
+	Reason string // Why the file was skipped
+}
+
+// String formats the result for display, e.g. "copied .env"
+func (r Result) String() string {
+	if r.Reason != "" {
+		return fmt.Sprintf("%s %s (%s)", r.Action, r.Path, r.Reason)
+	}
+	return fmt.Sprintf("%s %s", r.Action, r.Path)
+}
+
+// Run provisions the configured files from vars.RepoRoot into the worktree at vars.Path.
+// Existing files in the worktree are never overwritten. Failures don't stop the
+// remaining files from being provisioned; they are returned joined together.
+func Run(cfg *config.Config, vars Vars) ([]Result, error) {
+	p := &provisioner{cfg: cfg, vars: vars}
+	p.each(cfg.Files.Copy, p.copy)
+	p.each(cfg.Files.Symlink, p.symlink)
+	p.each(cfg.Files.Template, p.template)
+	return p.results, errors.Join(p.errs...)
+}
+
+type provisioner struct {
+	cfg     *config.Config
+	vars    Vars
+	results []Result
+	errs    []error
+}
+
+// each expands the glob patterns and calls fn for every match, relative to the repo root
+func (p *provisioner) each(patterns []string, fn func(rel string) (Result, error)) {
+	for _, pattern := range patterns {
+		matches, err := p.glob(pattern)
+		if err != nil {
+			p.errs = append(p.errs, err)
+			continue
+		}
+		for _, rel := range matches {
+			result, err := fn(rel)
+			if err != nil {
+				p.errs = append(p.errs, fmt.Errorf("%s: %w", rel, err))
+				continue
+			}
+			p.results = append(p.results, result)
+		}
+	}
+}
+
+// glob returns the repo-relative paths matching pattern, excluding .git and the worktree directory
+func (p *provisioner) glob(pattern string) ([]string, error) {
+	clean := filepath.Clean(filepath.FromSlash(pattern))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("file pattern %q must be relative to the repository root", pattern)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(p.vars.RepoRoot, clean))
+	if err != nil {
+		return nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+	}
+
+	var rels []string
+	for _, match := range matches {
+		rel, err := filepath.Rel(p.vars.RepoRoot, match)
+		if err != nil || p.excluded(rel) {
+			continue
+		}
+		rels = append(rels, rel)
+	}
+	return rels, nil
+}
+
+// excluded reports whether rel is inside .git or the worktree directory
+func (p *provisioner) excluded(rel string) bool {
+	for _, dir := range []string{".git", filepath.Clean(p.cfg.WorktreeDir)} {
+		if rel == dir || strings.HasPrefix(rel, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepare returns the destination for rel and creates its parent directory.
+// exists is true if the destination is already present and should be left alone.
+func (p *provisioner) prepare(rel string) (dest string, exists bool, err error) {
+	dest = filepath.Join(p.vars.Path, rel)
+	if _, err := os.Lstat(dest); err == nil {
+		return dest, true, nil
+	}
+	return dest, false, os.MkdirAll(filepath.Dir(dest), 0755)
+}
+
+// skipped is the result for a destination that already exists
+func skipped(rel string) Result {
+	return Result{Action: ActionSkipped, Path: rel, Reason: "already exists"}
+}
+
+func (p *provisioner) copy(rel string) (Result, error) {
+	dest, exists, err := p.prepare(rel)
+	if err != nil || exists {
+		return skipped(rel), err
+	}
+	if err := copyTree(filepath.Join(p.vars.RepoRoot, rel), dest); err != nil {
+		return Result{}, err
+	}
+	return Result{Action: ActionCopied, Path: rel}, nil
+}
+
+func (p *provisioner) symlink(rel string) (Result, error) {
+	dest, exists, err := p.prepare(rel)
+	if err != nil || exists {
+		return skipped(rel), err
+	}
+	if err := os.Symlink(filepath.Join(p.vars.RepoRoot, rel), dest); err != nil {
+		return Result{}, err
+	}
+	return Result{Action: ActionLinked, Path: rel}, nil
+}
+
+func (p *provisioner) template(rel string) (Result, error) {
+	src := filepath.Join(p.vars.RepoRoot, rel)
+	info, err := os.Stat(src)
+	if err != nil {
+		return Result{}, err
+	}
+	if info.IsDir() {
+		return Result{}, fmt.Errorf("templates must be files")
+	}
+
+	rel = strings.TrimSuffix(rel, TemplateSuffix)
+	dest, exists, err := p.prepare(rel)
+	if err != nil || exists {
+		return skipped(rel), err
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return Result{}, err
+	}
+	expanded, err := Expand(string(data), p.vars)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := os.WriteFile(dest, []byte(expanded), info.Mode().Perm()); err != nil {
+		return Result{}, err
+	}
+	return Result{Action: ActionRendered, Path: rel}, nil
+}
+
+// copyTree copies a file, symlink or directory tree, preserving permissions
+func copyTree(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+// copyFile copies a regular file's contents to dest with the given permissions
+func copyFile(src, dest string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
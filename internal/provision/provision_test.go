@@ -0,0 +1,144 @@
+package provision
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agarcher/wt/internal/config"
+)
+
+// setupProvisionTest creates a fake main repo with a few files and an empty worktree
+func setupProvisionTest(t *testing.T) Vars {
+	t.Helper()
+
+	repoRoot := t.TempDir()
+	worktreePath := filepath.Join(repoRoot, "worktrees", "feature-x")
+
+	files := map[string]string{
+		".env":                  "SECRET=1\n",
+		".env.local":            "LOCAL=1\n",
+		"tracked.txt":           "main copy\n",
+		"config/dev.json.tmpl":  `{"name": "{name}", "port": {index*10+5173}}` + "\n",
+		"data/fixtures/a.json":  "a\n",
+		"data/fixtures/b.json":  "b\n",
+		"worktrees/other/.env":  "other\n",
+		".git/config":           "[core]\n",
+		"node_modules/pkg/x.js": "x\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoRoot, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The worktree already has tracked files checked out
+	if err := os.MkdirAll(worktreePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "tracked.txt"), []byte("checked out\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return Vars{Name: "feature-x", Branch: "feature-x", Path: worktreePath, RepoRoot: repoRoot, Index: 2}
+}
+
+func TestRun(t *testing.T) {
+	vars := setupProvisionTest(t)
+	cfg := config.DefaultConfig()
+	cfg.Files = config.FilesConfig{
+		Copy:     []string{".env*", "tracked.txt", "data/fixtures"},
+		Symlink:  []string{"node_modules"},
+		Template: []string{"config/*.tmpl"},
+	}
+
+	results, err := Run(cfg, vars)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var got []string
+	for _, r := range results {
+		got = append(got, r.String())
+	}
+	want := []string{
+		"copied .env",
+		"copied .env.local",
+		"skipped tracked.txt (already exists)",
+		"copied data/fixtures",
+		"linked node_modules",
+		"rendered config/dev.json",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected results:\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	assertFile := func(rel, want string) {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(vars.Path, rel))
+		if err != nil {
+			t.Errorf("expected %s in worktree: %v", rel, err)
+			return
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", rel, data, want)
+		}
+	}
+	assertFile(".env", "SECRET=1\n")
+	assertFile("tracked.txt", "checked out\n")
+	assertFile("data/fixtures/b.json", "b\n")
+	assertFile("config/dev.json", `{"name": "feature-x", "port": 5193}`+"\n")
+
+	link, err := os.Readlink(filepath.Join(vars.Path, "node_modules"))
+	if err != nil || link != filepath.Join(vars.RepoRoot, "node_modules") {
+		t.Errorf("expected node_modules symlink to main repo, got %q (%v)", link, err)
+	}
+}
+
+func TestRunSkipsGitAndWorktrees(t *testing.T) {
+	vars := setupProvisionTest(t)
+	cfg := config.DefaultConfig()
+	cfg.Files.Copy = []string{"*"}
+
+	results, err := Run(cfg, vars)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Path == ".git" || r.Path == "worktrees" {
+			t.Errorf("should not provision %s", r.Path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(vars.Path, "worktrees")); err == nil {
+		t.Error("worktree directory should not be copied into the worktree")
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	vars := setupProvisionTest(t)
+	cfg := config.DefaultConfig()
+	cfg.Files = config.FilesConfig{
+		Copy:     []string{"../outside", "/etc/passwd", ".env"},
+		Template: []string{"data"},
+	}
+
+	results, err := Run(cfg, vars)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{`"../outside" must be relative`, `"/etc/passwd" must be relative`, "templates must be files"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got: %v", want, err)
+		}
+	}
+
+	// Valid entries are still provisioned
+	if len(results) != 1 || results[0].Path != ".env" {
+		t.Errorf("expected .env to be copied despite errors, got %v", results)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build linux
+
+package provision
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// ficlone is the FICLONE ioctl request, _IOW(0x94, 9, int), supported by btrfs, XFS and others
+const ficlone = 0x40049409
+
+// reflinkUnsupported reports whether a reflinkFile error means the filesystem can't
+// clone the file (no FICLONE support, across filesystems, or an unsuitable file),
+// rather than a failure a fallback would hide
+func reflinkUnsupported(err error) bool {
+	return errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, syscall.EXDEV) || errors.Is(err, syscall.EINVAL)
+}
+
+// reflinkFile creates dest as a copy-on-write clone of src.
+// dest is removed again if the filesystem doesn't support reflinks.
+func reflinkFile(src, dest string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, out.Fd(), ficlone, in.Fd())
+	closeErr := out.Close()
+	if errno != 0 {
+		_ = os.Remove(dest)
+		return errno
+	}
+	return closeErr
+}
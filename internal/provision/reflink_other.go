@@ -0,0 +1,21 @@
+//go:build !linux
+
+package provision
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// errReflinkUnsupported is returned where the platform has no reflink support
+var errReflinkUnsupported = errors.New("reflinks not supported on this platform")
+
+// reflinkUnsupported reports whether a reflinkFile error means reflinks can't be used
+func reflinkUnsupported(err error) bool {
+	return errors.Is(err, errReflinkUnsupported)
+}
+
+// reflinkFile is not supported on this platform; callers fall back to hard links or copies
+func reflinkFile(src, dest string, perm fs.FileMode) error {
+	return errReflinkUnsupported
+}
@@ -0,0 +1,191 @@
+package provision
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Vars are the values available to file templates
+type Vars struct {
+	Name     string
+	Branch   string
+	Path     string
+	RepoRoot string
+	Index    int // 0 if the worktree has no index
+}
+
+// placeholderPattern matches {...} placeholders without nested braces or padding, so
+// "{ name }" in JSON or JavaScript isn't one
+var placeholderPattern = regexp.MustCompile(`\{[^{}\s](?:[^{}\n]*[^{}\s])?\}`)
+
+// Expand replaces placeholders in s:
+//
+//	{name}, {branch}, {path}, {repo_root}  worktree values
+//	{index}                                the worktree index
+//	{index*10+5173}                        integer arithmetic on the index (+ - * / % and parentheses)
+//
+// Anything else in braces is left untouched, so templates can contain JSON, shell
+// ${VARS} and the like. Index placeholders are an error for a worktree without an index.
+func Expand(s string, vars Vars) (string, error) {
+	var errs []error
+	out := placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		expr := match[1 : len(match)-1]
+		switch expr {
+		case "name":
+			return vars.Name
+		case "branch":
+			return vars.Branch
+		case "path":
+			return vars.Path
+		case "repo_root":
+			return vars.RepoRoot
+		}
+		// Expressions that don't reference the index are left alone so literals like {5} survive
+		if !strings.Contains(expr, "index") {
+			return match
+		}
+		n, err := EvalExpr(expr, vars.Index)
+		if err != nil {
+			return match
+		}
+		if vars.Index == 0 {
+			errs = append(errs, fmt.Errorf("%s: the worktree has no index", match))
+			return match
+		}
+		return strconv.Itoa(n)
+	})
+	return out, errors.Join(errs...)
+}
+
+// EvalExpr evaluates an integer expression such as "5173+index*10", where index is
+// the worktree index. Supports + - * / % and parentheses.
+func EvalExpr(expr string, index int) (int, error) {
+	p := &exprParser{input: expr, index: index}
+	n, err := p.parseExpr()
+	if err != nil {
+		return 0, fmt.Errorf("invalid expression %q: %w", expr, err)
+	}
+	p.skipSpace()
+	if p.pos != len(p.input) {
+		return 0, fmt.Errorf("invalid expression %q: unexpected input at %d", expr, p.pos)
+	}
+	return n, nil
+}
+
+// exprParser is a recursive descent parser for index arithmetic:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/" | "%") factor }
+//	factor = number | "index" | "(" expr ")" | "-" factor
+type exprParser struct {
+	input string
+	pos   int
+	index int
+}
+
+func (p *exprParser) parseExpr() (int, error) {
+	n, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '+':
+			p.pos++
+			m, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			n += m
+		case '-':
+			p.pos++
+			m, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			n -= m
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *exprParser) parseTerm() (int, error) {
+	n, err := p.parseFactor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return n, nil
+		}
+		p.pos++
+		m, err := p.parseFactor()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			n *= m
+		case '/', '%':
+			if m == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			if op == '/' {
+				n /= m
+			} else {
+				n %= m
+			}
+		}
+	}
+}
+
+func (p *exprParser) parseFactor() (int, error) {
+	switch c := p.peek(); {
+	case c == '(':
+		p.pos++
+		n, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return n, nil
+	case c == '-':
+		p.pos++
+		n, err := p.parseFactor()
+		return -n, err
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+		return strconv.Atoi(p.input[start:p.pos])
+	case strings.HasPrefix(p.input[p.pos:], "index"):
+		p.pos += len("index")
+		return p.index, nil
+	default:
+		return 0, fmt.Errorf("unexpected input at %d", p.pos)
+	}
+}
+
+// peek skips whitespace and returns the next byte, or 0 at the end of input
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
@@ -0,0 +1,68 @@
+package provision
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	vars := Vars{
+		Name:     "feature-x",
+		Branch:   "feature/feature-x",
+		Path:     "/repo/worktrees/feature-x",
+		RepoRoot: "/repo",
+		Index:    3,
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"{name}", "feature-x"},
+		{"{branch}", "feature/feature-x"},
+		{"{path}", "/repo/worktrees/feature-x"},
+		{"{repo_root}", "/repo"},
+		{"{index}", "3"},
+		{"PORT={index*10+5173}", "PORT=5203"},
+		{"{index * 10 + 5173}", "5203"},
+		{"{(index+1)*100}", "400"},
+		{"{5000-index}", "4997"},
+		{"{index/2} {index%2} {-index}", "1 1 -3"},
+		{"DB=app_{name}_{index}", "DB=app_feature-x_3"},
+		// Left untouched
+		{`{"port": 1}`, `{"port": 1}`},
+		{"${HOME}", "${HOME}"},
+		{"a{5}", "a{5}"},
+		{"{index/0}", "{index/0}"},
+		{"{index+}", "{index+}"},
+		{"{unknown}", "{unknown}"},
+		{"{ name }", "{ name }"},
+		{"const { index } = require('x')", "const { index } = require('x')"},
+		{"{ index*10 }", "{ index*10 }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Expand(tt.input, vars)
+			if err != nil {
+				t.Fatalf("Expand(%q) failed: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandWithoutIndex(t *testing.T) {
+	vars := Vars{Name: "feature-x"}
+
+	if got, err := Expand("{name} {5} {unknown}", vars); err != nil || got != "feature-x {5} {unknown}" {
+		t.Errorf("expected placeholders without the index to expand, got %q (%v)", got, err)
+	}
+	for _, input := range []string{"{index}", "PORT={index*10+5173}"} {
+		if _, err := Expand(input, vars); err == nil || !strings.Contains(err.Error(), "no index") {
+			t.Errorf("Expand(%q) should fail without an index, got %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package shell
+
+// GenerateElvish returns the Elvish shell integration script
+func GenerateElvish() string {
+	return `# wt shell integration for Elvish
+# Add this to your ~/.config/elvish/rc.elv: eval (wt init elvish | slurp)
+
+use file
+use os
+use str
+
+fn wt {|@args|
+  if (or (== (count $args) 0) (not (has-value [create delete cleanup switch cd exit clone] $args[0]))) {
+    e:wt $@args
+    return
+  }
+
+  # Use temp file to communicate cd target from Go
+  var f = (os:temp-file wt-cd-)
+  var cdfile = $f[name]
+  file:close $f
+  try {
+    tmp E:WT_CD_FILE = $cdfile
+    e:wt $@args
+  } finally {
+    # cd to path if one was written
+    var target = (str:trim-space (slurp < $cdfile))
+    os:remove $cdfile
+    if (and (!=s $target '') (os:is-dir $target)) {
+      cd $target
+    }
+  }
+}
+
+# Completions for wt. Candidates come from the binary (wt __complete), so
+# they match the installed version's commands, flags and worktrees.
+set edit:completion:arg-completer[wt] = {|@words|
+  # words holds the command, its arguments and the word being completed
+  var lines = [(try { e:wt __complete $@words[1..] 2>$os:dev-null } catch { })]
+  for line $lines {
+    # The last line is the directive, e.g. ":4"; the rest are "value<TAB>description"
+    if (or (==s $line '') (str:has-prefix $line ':')) {
+      continue
+    }
+    var parts = [(str:split &max=2 "\t" $line)]
+    if (== (count $parts) 2) {
+      edit:complex-candidate $parts[0] &display=$parts[0]' ('$parts[1]')'
+    } else {
+      edit:complex-candidate $parts[0]
+    }
+  }
+}
+
+# eval runs in its own namespace, so make wt visible to the interactive shell
+edit:add-var wt~ $wt~
+`
+}
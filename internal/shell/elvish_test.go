@@ -0,0 +1,43 @@
+package shell
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestGenerateElvish(t *testing.T) {
+	script := GenerateElvish()
+
+	requiredStrings := []string{
+		"fn wt {|@args|",
+		"e:wt $@args",
+		"[create delete cleanup switch cd exit clone]",
+		"tmp E:WT_CD_FILE = $cdfile",
+		"cd $target",
+		"set edit:completion:arg-completer[wt]",
+		"edit:add-var wt~ $wt~",
+		"e:wt __complete",
+	}
+
+	for _, s := range requiredStrings {
+		if !strings.Contains(script, s) {
+			t.Errorf("elvish script missing required string: %q", s)
+		}
+	}
+}
+
+func TestElvishCdExecution(t *testing.T) {
+	if _, err := exec.LookPath("elvish"); err != nil {
+		t.Skip("elvish not installed")
+	}
+	repo, target, env := setupCdTest(t)
+
+	// The completion part uses the edit: module, which only exists in interactive
+	// shells, so elvish -c runs the wrapper alone
+	script, _, ok := strings.Cut(GenerateElvish(), "# Completions for wt.")
+	if !ok {
+		t.Fatal("elvish script has no completions section")
+	}
+	runCdTest(t, repo, target, env, "elvish", "-norc", "-c", script+"\nwt cd foo\necho $pwd\n")
+}
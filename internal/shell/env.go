@@ -0,0 +1,48 @@
+package shell
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EnvScript returns shell code that unsets the unset variables and exports set, for
+// eval (zsh, bash) or source (fish) by the shell integration
+func EnvScript(shell string, set map[string]string, unset []string) (string, error) {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	switch shell {
+	case "zsh", "bash":
+		for _, name := range unset {
+			fmt.Fprintf(&b, "unset %s\n", name)
+		}
+		for _, name := range names {
+			fmt.Fprintf(&b, "export %s=%s\n", name, quotePosix(set[name]))
+		}
+	case "fish":
+		for _, name := range unset {
+			fmt.Fprintf(&b, "set -e %s\n", name)
+		}
+		for _, name := range names {
+			fmt.Fprintf(&b, "set -gx %s %s\n", name, quoteFish(set[name]))
+		}
+	default:
+		return "", fmt.Errorf("unsupported shell: %s (supported: zsh, bash, fish)", shell)
+	}
+	return b.String(), nil
+}
+
+// quotePosix single-quotes s for sh-compatible shells
+func quotePosix(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish single-quotes s for fish, where backslash and quote are escaped inside quotes
+func quoteFish(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
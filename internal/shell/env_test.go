@@ -0,0 +1,53 @@
+package shell
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestEnvScript(t *testing.T) {
+	set := map[string]string{"B": "it's", "A": `back\slash`}
+	unset := []string{"OLD"}
+
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{"zsh", "unset OLD\nexport A='back\\slash'\nexport B='it'\\''s'\n"},
+		{"bash", "unset OLD\nexport A='back\\slash'\nexport B='it'\\''s'\n"},
+		{"fish", "set -e OLD\nset -gx A 'back\\\\slash'\nset -gx B 'it\\'s'\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			got, err := EnvScript(tt.shell, set, unset)
+			if err != nil {
+				t.Fatalf("EnvScript failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := EnvScript("tcsh", set, nil); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
+
+func TestEnvScriptEvalBash(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	value := "a 'quoted' $HOME `cmd` \"value\"\nline two"
+	script, err := EnvScript("bash", map[string]string{"WT_TEST_VALUE": value}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("bash", "-c", script+`printf %s "$WT_TEST_VALUE"`).Output()
+	if err != nil {
+		t.Fatalf("bash failed: %v", err)
+	}
+	if string(out) != value {
+		t.Errorf("got %q, want %q", out, value)
+	}
+}
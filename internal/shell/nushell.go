@@ -0,0 +1,53 @@
+package shell
+
+// GenerateNushell returns the Nushell shell integration script
+func GenerateNushell() string {
+	return `# wt shell integration for Nushell
+# Nushell can't source generated code directly, so save it once (and after upgrading wt):
+#   wt init nu | save -f ~/.config/nushell/wt.nu
+# Then add this to your config.nu: source ~/.config/nushell/wt.nu
+
+# Completions for wt. Candidates come from the binary (wt __complete), so
+# they match the installed version's commands, flags and worktrees.
+def "nu-complete wt" [context: string] {
+  let words = ($context | str trim --left | split row --regex '\s+' | skip 1)
+  let result = (^wt __complete ...$words | complete)
+  if $result.exit_code != 0 {
+    return []
+  }
+  # The last line is the directive, e.g. ":4"; the rest are "value<TAB>description"
+  $result.stdout
+  | lines
+  | where { |line| not ($line | str starts-with ":") and ($line | is-not-empty) }
+  | each { |line|
+      let parts = ($line | split row --number 2 "\t")
+      { value: ($parts | get 0), description: ($parts | get 1? | default "") }
+    }
+}
+
+def --env --wrapped wt [...args: string@"nu-complete wt"] {
+  if ($args | is-empty) or not ($args.0 in [create delete cleanup switch cd exit clone]) {
+    ^wt ...$args
+    return
+  }
+
+  # Use temp file to communicate cd target from Go. Errors are ignored so the cd
+  # still happens, and the exit code is raised after it.
+  let cdfile = (mktemp --tmpdir wt-cd.XXXXXX)
+  let exit_code = with-env { WT_CD_FILE: $cdfile } {
+    do --ignore-errors { ^wt ...$args }
+    $env.LAST_EXIT_CODE
+  }
+
+  # cd to path if one was written
+  let target = (open --raw $cdfile | str trim)
+  rm --force $cdfile
+  if ($target | is-not-empty) and ($target | path exists) {
+    cd $target
+  }
+  if $exit_code != 0 {
+    error make --unspanned { msg: $"wt exited with code ($exit_code)" }
+  }
+}
+`
+}
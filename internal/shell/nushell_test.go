@@ -0,0 +1,63 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateNushell(t *testing.T) {
+	script := GenerateNushell()
+
+	requiredStrings := []string{
+		"def --env --wrapped wt",
+		"^wt ...$args",
+		"[create delete cleanup switch cd exit clone]",
+		"WT_CD_FILE: $cdfile",
+		"cd $target",
+		"$env.LAST_EXIT_CODE",
+		"error make",
+		`def "nu-complete wt"`,
+		"^wt __complete",
+	}
+
+	for _, s := range requiredStrings {
+		if !strings.Contains(script, s) {
+			t.Errorf("nushell script missing required string: %q", s)
+		}
+	}
+}
+
+func TestNushellCdExecution(t *testing.T) {
+	if _, err := exec.LookPath("nu"); err != nil {
+		t.Skip("nu not installed")
+	}
+	repo, target, env := setupCdTest(t)
+	script := filepath.Join(t.TempDir(), "wt.nu")
+	if err := os.WriteFile(script, []byte(GenerateNushell()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runCdTest(t, repo, target, env, "nu", "--no-config-file", "-c", "source '"+script+"'; wt cd foo; print $env.PWD")
+}
+
+func TestNushellExitCode(t *testing.T) {
+	if _, err := exec.LookPath("nu"); err != nil {
+		t.Skip("nu not installed")
+	}
+	repo, _, env := setupCdTest(t)
+	script := filepath.Join(t.TempDir(), "wt.nu")
+	if err := os.WriteFile(script, []byte(GenerateNushell()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failing command still fails through the wrapper
+	cmd := exec.Command("nu", "--no-config-file", "-c", "source '"+script+"'; wt delete missing")
+	cmd.Dir = repo
+	cmd.Env = append(env, "WT_TEST_EXIT=3")
+	out, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "wt exited with code 3") {
+		t.Errorf("expected the exit code to be raised, got %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,72 @@
+package shell
+
+// GeneratePowerShell returns the PowerShell shell integration script
+func GeneratePowerShell() string {
+	return `# wt shell integration for PowerShell
+# Add this to your $PROFILE: Invoke-Expression (& wt init powershell | Out-String)
+
+# Completions for wt. Candidates come from the binary (wt __complete), so
+# they match the installed version's commands, flags and worktrees.
+Register-ArgumentCompleter -Native -CommandName wt -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $wtCommand = Get-Command -Name wt -CommandType Application -ErrorAction SilentlyContinue | Select-Object -First 1
+    if (-not $wtCommand) { return }
+
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    # The word being completed is the last element, unless it's still empty
+    if ($wordToComplete) { $words = @($words | Select-Object -SkipLast 1) }
+    # Before PowerShell 7.3 an empty argument isn't passed to native commands
+    $current = $wordToComplete
+    if (-not $current -and (-not $PSNativeCommandArgumentPassing -or $PSNativeCommandArgumentPassing -eq 'Legacy')) {
+        $current = '""'
+    }
+
+    $directive = 0
+    $results = @()
+    foreach ($line in @(& $wtCommand __complete @words $current 2>$null)) {
+        # The last line is the directive, e.g. ":4"
+        if ($line -like ':*') {
+            $directive = [int]$line.Substring(1)
+            continue
+        }
+        $value, $description = $line -split "\t", 2
+        if (-not $description) { $description = $value }
+        if ($value -like "$wordToComplete*") {
+            $results += [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+        }
+    }
+
+    # Directive bit 1 is an error; with no results PowerShell falls back to file names
+    if ($directive -band 1) { return }
+    $results
+}
+
+function global:wt {
+    $wtCommand = Get-Command -Name wt -CommandType Application -ErrorAction Stop | Select-Object -First 1
+
+    if ($args.Count -gt 0 -and $args[0] -in 'create', 'delete', 'cleanup', 'switch', 'cd', 'exit', 'clone') {
+        # Use temp file to communicate cd target from Go
+        $cdFile = [System.IO.Path]::GetTempFileName()
+        $previous = $env:WT_CD_FILE
+        try {
+            $env:WT_CD_FILE = $cdFile
+            & $wtCommand @args
+        } finally {
+            $env:WT_CD_FILE = $previous
+            # cd to path if one was written
+            $target = Get-Content -LiteralPath $cdFile -Raw -ErrorAction SilentlyContinue
+            Remove-Item -LiteralPath $cdFile -Force -ErrorAction SilentlyContinue
+            if ($target) {
+                $target = $target.Trim()
+                if ($target -and (Test-Path -LiteralPath $target -PathType Container)) {
+                    Set-Location -LiteralPath $target
+                }
+            }
+        }
+    } else {
+        & $wtCommand @args
+    }
+}
+`
+}
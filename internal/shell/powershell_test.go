@@ -0,0 +1,43 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePowerShell(t *testing.T) {
+	script := GeneratePowerShell()
+
+	requiredStrings := []string{
+		"function global:wt",
+		"Get-Command -Name wt -CommandType Application",
+		"'create', 'delete', 'cleanup', 'switch', 'cd', 'exit', 'clone'",
+		"$env:WT_CD_FILE = $cdFile",
+		"& $wtCommand @args",
+		"Set-Location -LiteralPath $target",
+		"Register-ArgumentCompleter -Native -CommandName wt",
+		"& $wtCommand __complete",
+	}
+
+	for _, s := range requiredStrings {
+		if !strings.Contains(script, s) {
+			t.Errorf("powershell script missing required string: %q", s)
+		}
+	}
+}
+
+func TestPowerShellCdExecution(t *testing.T) {
+	if _, err := exec.LookPath("pwsh"); err != nil {
+		t.Skip("pwsh not installed")
+	}
+	repo, target, env := setupCdTest(t)
+	script := filepath.Join(t.TempDir(), "wt.ps1")
+	if err := os.WriteFile(script, []byte(GeneratePowerShell()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	command := "Invoke-Expression (Get-Content -Raw '" + script + "'); wt cd foo; (Get-Location).Path"
+	runCdTest(t, repo, target, env, "pwsh", "-NoProfile", "-NonInteractive", "-Command", command)
+}
@@ -7,181 +7,84 @@ func GenerateZsh() string {
 	return `# wt shell integration for zsh
 # Add this to your ~/.zshrc: eval "$(wt init zsh)"
 
-# Completion function for wt
+# Completion function for wt. Candidates come from the binary (wt __complete), so
+# they match the installed version's commands, flags and worktrees.
 _wt() {
-  local curcontext="$curcontext" state line
-  typeset -A opt_args
-
-  _arguments -C \
-    '1: :->command' \
-    '*: :->args'
-
-  case $state in
-    command)
-      local commands=(
-        'create:Create a new worktree'
-        'delete:Delete a worktree'
-        'cd:Change to a worktree directory'
-        'info:Show detailed information about a worktree'
-        'list:List all worktrees'
-        'cleanup:Clean up merged worktrees'
-        'exit:Return to the main repository'
-        'init:Generate shell integration script'
-        'root:Print the main repository root path'
-        'completion:Generate shell completion script'
-        'version:Print the version number'
-        'help:Help about any command'
-      )
-      _describe 'command' commands
-      ;;
-    args)
-      case $words[2] in
-        cd|info)
-          # Only complete worktree names for the first argument
-          local has_name=false
-          for ((i=3; i < $CURRENT; i++)); do
-            if [[ ${words[$i]} != -* ]]; then
-              has_name=true
-              break
-            fi
-          done
-          if ! $has_name; then
-            local repo_root worktree_dir worktrees
-            repo_root=$(git rev-parse --show-toplevel 2>/dev/null)
-            if [[ -n "$repo_root" ]]; then
-              # Check if in worktree and get main repo
-              if [[ -f "$repo_root/.git" ]]; then
-                local gitdir=$(grep "^gitdir:" "$repo_root/.git" | cut -d' ' -f2)
-                if [[ -n "$gitdir" ]]; then
-                  repo_root=$(dirname $(dirname $(dirname "$gitdir")))
-                fi
-              fi
-              if [[ -f "$repo_root/.wt.yaml" ]]; then
-                worktree_dir=$(grep "^worktree_dir:" "$repo_root/.wt.yaml" | cut -d' ' -f2 | tr -d '"' | tr -d "'")
-                [[ -z "$worktree_dir" ]] && worktree_dir="worktrees"
-                if [[ -d "$repo_root/$worktree_dir" ]]; then
-                  worktrees=(${(f)"$(ls -1 "$repo_root/$worktree_dir" 2>/dev/null)"})
-                  _describe 'worktree' worktrees
-                fi
-              fi
-            fi
-          fi
-          ;;
-        delete)
-          # Check if a worktree name (non-flag argument) has already been provided
-          local has_name=false
-          for ((i=3; i < $CURRENT; i++)); do
-            if [[ ${words[$i]} != -* ]]; then
-              has_name=true
-              break
-            fi
-          done
-          if $has_name || [[ ${words[$CURRENT]} == -* ]]; then
-            # Worktree name already provided or typing a flag - complete flags
-            local -a flags=(
-              '-f:Force deletion'
-              '--force:Force deletion'
-              '-k:Keep the associated branch'
-              '--keep-branch:Keep the associated branch'
-            )
-            _describe 'flag' flags
-          else
-            # Complete worktree names
-            local repo_root worktree_dir worktrees
-            repo_root=$(git rev-parse --show-toplevel 2>/dev/null)
-            if [[ -n "$repo_root" ]]; then
-              if [[ -f "$repo_root/.git" ]]; then
-                local gitdir=$(grep "^gitdir:" "$repo_root/.git" | cut -d' ' -f2)
-                if [[ -n "$gitdir" ]]; then
-                  repo_root=$(dirname $(dirname $(dirname "$gitdir")))
-                fi
-              fi
-              if [[ -f "$repo_root/.wt.yaml" ]]; then
-                worktree_dir=$(grep "^worktree_dir:" "$repo_root/.wt.yaml" | cut -d' ' -f2 | tr -d '"' | tr -d "'")
-                [[ -z "$worktree_dir" ]] && worktree_dir="worktrees"
-                if [[ -d "$repo_root/$worktree_dir" ]]; then
-                  worktrees=(${(f)"$(ls -1 "$repo_root/$worktree_dir" 2>/dev/null)"})
-                  _describe 'worktree' worktrees
-                fi
-              fi
-            fi
-          fi
-          ;;
-        create)
-          _arguments \
-            '-b[Use existing branch]:branch:->branches' \
-            '--branch[Use existing branch]:branch:->branches'
-          if [[ $state == branches ]]; then
-            local branches
-            # Try --format first (Git 2.13+), fall back to parsing git branch output
-            branches=(${(f)"$(git branch --format='%(refname:short)' 2>/dev/null || git branch 2>/dev/null | sed 's/^[* ] //')"})
-            _describe 'branch' branches
-          fi
-          ;;
-        init|completion)
-          local shells=(zsh bash fish)
-          _describe 'shell' shells
-          ;;
-        cleanup)
-          _arguments \
-            '-f[Force deletion]' \
-            '--force[Force deletion]' \
-            '-k[Keep the associated branch]' \
-            '--keep-branch[Keep the associated branch]' \
-            '-n[Dry run - show what would be deleted]' \
-            '--dry-run[Dry run - show what would be deleted]'
-          ;;
-        list)
-          _arguments \
-            '-v[Show detailed status]' \
-            '--verbose[Show detailed status]'
-          ;;
-      esac
-      ;;
-  esac
+  local out line directive=0
+  local -a completions
+  out=$(command wt __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null) || return 1
+
+  for line in "${(@f)out}"; do
+    # The last line is the directive, e.g. ":4"
+    if [[ "$line" == :* ]]; then
+      directive=${line#:}
+      continue
+    fi
+    # "value<TAB>description" becomes "value:description"; colons in values are escaped
+    if [[ "$line" == *$'\t'* ]]; then
+      completions+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+    else
+      completions+=("${line//:/\\:}")
+    fi
+  done
+
+  # Directive bits: 1 error, 2 no space, 4 no file completion
+  (( directive & 1 )) && return 1
+  if (( ${#completions} > 0 )); then
+    if (( directive & 2 )); then
+      _describe -t wt 'wt' completions -S ''
+    else
+      _describe -t wt 'wt' completions
+    fi
+  elif (( ! (directive & 4) )); then
+    _files
+  fi
 }
 
 # Register completion for wt function
 compdef _wt wt
 
-wt() {
-  # Check if we're in a git repo with .wt.yaml
-  local repo_root
-  repo_root=$(git rev-parse --show-toplevel 2>/dev/null)
-
-  if [[ -z "$repo_root" ]]; then
-    # Not in a git repo - try to run wt anyway (might be a global command)
-    command wt "$@"
-    return $?
-  fi
-
-  # Check for .wt.yaml in repo root or if we're in a worktree, check main repo
-  local config_found=false
-  if [[ -f "$repo_root/.wt.yaml" ]]; then
-    config_found=true
-  else
-    # Check if this is a worktree and look for config in main repo
-    local git_file="$repo_root/.git"
-    if [[ -f "$git_file" ]]; then
-      local gitdir=$(grep "^gitdir:" "$git_file" | cut -d' ' -f2)
-      if [[ -n "$gitdir" ]]; then
-        local main_repo=$(dirname $(dirname $(dirname "$gitdir")))
-        if [[ -f "$main_repo/.wt.yaml" ]]; then
-          config_found=true
-          repo_root="$main_repo"
-        fi
-      fi
-    fi
+# Load the worktree environment (wt env) when entering a worktree and unload it on leaving.
+# wt only runs when the worktree holding $PWD (the nearest directory with a .git) changes,
+# or the loaded env file is newer than the last load, both checked without a process.
+_WT_ENV_STAMP="${TMPDIR:-/tmp}/wt-env-$$"
+_wt_env_hook() {
+  local dir="$PWD"
+  while [[ -n "$dir" && ! -e "$dir/.git" ]]; do
+    dir="${dir%/*}"
+  done
+  if [[ -n "${_WT_ENV_DIR+set}" && "$dir" == "$_WT_ENV_DIR" ]] && ! [[ -n "$_WT_ENV_FILE" && "$_WT_ENV_FILE" -nt "$_WT_ENV_STAMP" ]]; then
+    return
   fi
+  _WT_ENV_DIR="$dir"
+  { : >| "$_WT_ENV_STAMP"; } 2>/dev/null
+  eval "$(command wt env --shell zsh 2>/dev/null)"
+}
+_wt_env_cleanup() {
+  rm -f "$_WT_ENV_STAMP"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd _wt_env_hook
+add-zsh-hook zshexit _wt_env_cleanup
+
+# Prompt segment for the current worktree, e.g. "⎇ agent-3 [#3 ↑2 dirty]" (empty elsewhere).
+# Use in a prompt with: setopt PROMPT_SUBST; RPROMPT='$(wt_prompt_info)'
+wt_prompt_info() {
+  command wt prompt "$@" 2>/dev/null
+}
 
-  if [[ "$config_found" != "true" ]]; then
-    command wt "$@"
-    return $?
-  fi
+# Powerlevel10k segment: add "wt" to POWERLEVEL9K_LEFT_PROMPT_ELEMENTS or _RIGHT_
+prompt_wt() {
+  local info
+  info=$(wt_prompt_info)
+  [[ -n "$info" ]] && p10k segment -t "$info"
+}
 
-  # Commands that need cd handling
+wt() {
+  # Commands that may change directory write the target to $WT_CD_FILE. The binary
+  # finds the repository itself, whatever its layout, so the wrapper needn't.
   case "$1" in
-    create|delete|cleanup)
+    create|delete|cleanup|switch|cd|exit|clone)
       # Use temp file to communicate cd target from Go
       local cdfile=$(mktemp)
       trap "rm -f '$cdfile'" EXIT
@@ -196,36 +99,10 @@ wt() {
         fi
       fi
       rm -f "$cdfile"
+      _wt_env_hook
       trap - EXIT
       return $exit_code
       ;;
-    cd)
-      local output
-      output=$(command wt "$@" 2>&1)
-      local exit_code=$?
-
-      if [[ $exit_code -eq 0 ]]; then
-        local target="$output"
-        if [[ -d "$target" ]]; then
-          cd "$target"
-        else
-          echo "$output"
-        fi
-      else
-        echo "$output"
-      fi
-      return $exit_code
-      ;;
-    exit)
-      local target
-      target=$(command wt root 2>/dev/null)
-      if [[ -d "$target" ]]; then
-        cd "$target"
-      else
-        echo "Could not find repository root"
-        return 1
-      fi
-      ;;
     *)
       command wt "$@"
       ;;
@@ -239,138 +116,82 @@ func GenerateBash() string {
 	return `# wt shell integration for bash
 # Add this to your ~/.bashrc: eval "$(wt init bash)"
 
-# Completion function for wt
+# Completion function for wt. Candidates come from the binary (wt __complete), so
+# they match the installed version's commands, flags and worktrees.
 _wt_completions() {
-  local cur prev words cword
-  _init_completion 2>/dev/null || {
-    COMPREPLY=()
-    cur="${COMP_WORDS[COMP_CWORD]}"
-    prev="${COMP_WORDS[COMP_CWORD-1]}"
-  }
-
-  local commands="create delete cd info list cleanup exit init root completion version help"
+  local cur="${COMP_WORDS[COMP_CWORD]}"
+  local out line directive=0
+  out=$(command wt __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" "$cur" 2>/dev/null) || return 0
+
+  COMPREPLY=()
+  while IFS= read -r line; do
+    # The last line is the directive, e.g. ":4"
+    if [[ "$line" == :* ]]; then
+      directive=${line#:}
+      continue
+    fi
+    # Drop the description after the tab
+    line=${line%%$'\t'*}
+    [[ -n "$line" && "$line" == "$cur"* ]] && COMPREPLY+=("$line")
+  done <<< "$out"
 
-  if [[ $COMP_CWORD -eq 1 ]]; then
-    COMPREPLY=($(compgen -W "$commands" -- "$cur"))
+  # Directive bits: 1 error, 2 no space, 4 no file completion
+  if (( directive & 1 )); then
+    COMPREPLY=()
     return 0
   fi
-
-  local cmd="${COMP_WORDS[1]}"
-  case "$cmd" in
-    cd|info)
-      # Complete worktree names
-      local repo_root worktree_dir worktrees
-      repo_root=$(git rev-parse --show-toplevel 2>/dev/null)
-      if [[ -n "$repo_root" ]]; then
-        if [[ -f "$repo_root/.git" ]]; then
-          local gitdir=$(grep "^gitdir:" "$repo_root/.git" | cut -d' ' -f2)
-          if [[ -n "$gitdir" ]]; then
-            repo_root=$(dirname $(dirname $(dirname "$gitdir")))
-          fi
-        fi
-        if [[ -f "$repo_root/.wt.yaml" ]]; then
-          worktree_dir=$(grep "^worktree_dir:" "$repo_root/.wt.yaml" | cut -d' ' -f2 | tr -d '"' | tr -d "'")
-          [[ -z "$worktree_dir" ]] && worktree_dir="worktrees"
-          if [[ -d "$repo_root/$worktree_dir" ]]; then
-            worktrees=$(ls -1 "$repo_root/$worktree_dir" 2>/dev/null)
-            COMPREPLY=($(compgen -W "$worktrees" -- "$cur"))
-          fi
-        fi
-      fi
-      ;;
-    delete)
-      # Complete worktree names and flags
-      local repo_root worktree_dir worktrees
-      repo_root=$(git rev-parse --show-toplevel 2>/dev/null)
-      if [[ -n "$repo_root" ]]; then
-        if [[ -f "$repo_root/.git" ]]; then
-          local gitdir=$(grep "^gitdir:" "$repo_root/.git" | cut -d' ' -f2)
-          if [[ -n "$gitdir" ]]; then
-            repo_root=$(dirname $(dirname $(dirname "$gitdir")))
-          fi
-        fi
-        if [[ -f "$repo_root/.wt.yaml" ]]; then
-          worktree_dir=$(grep "^worktree_dir:" "$repo_root/.wt.yaml" | cut -d' ' -f2 | tr -d '"' | tr -d "'")
-          [[ -z "$worktree_dir" ]] && worktree_dir="worktrees"
-          if [[ -d "$repo_root/$worktree_dir" ]]; then
-            worktrees=$(ls -1 "$repo_root/$worktree_dir" 2>/dev/null)
-            COMPREPLY=($(compgen -W "$worktrees -f --force -k --keep-branch" -- "$cur"))
-          else
-            COMPREPLY=($(compgen -W "-f --force -k --keep-branch" -- "$cur"))
-          fi
-        else
-          COMPREPLY=($(compgen -W "-f --force -k --keep-branch" -- "$cur"))
-        fi
-      else
-        COMPREPLY=($(compgen -W "-f --force -k --keep-branch" -- "$cur"))
-      fi
-      ;;
-    create)
-      case "$prev" in
-        -b|--branch)
-          # Try --format first (Git 2.13+), fall back to parsing git branch output
-          local branches=$(git branch --format='%(refname:short)' 2>/dev/null || git branch 2>/dev/null | sed 's/^[* ] //')
-          COMPREPLY=($(compgen -W "$branches" -- "$cur"))
-          ;;
-        *)
-          COMPREPLY=($(compgen -W "-b --branch" -- "$cur"))
-          ;;
-      esac
-      ;;
-    init|completion)
-      COMPREPLY=($(compgen -W "zsh bash fish" -- "$cur"))
-      ;;
-    cleanup)
-      COMPREPLY=($(compgen -W "-n --dry-run -f --force -k --keep-branch" -- "$cur"))
-      ;;
-    list)
-      COMPREPLY=($(compgen -W "-v --verbose" -- "$cur"))
-      ;;
-  esac
+  if (( directive & 2 )); then
+    compopt -o nospace 2>/dev/null
+  fi
+  if (( ${#COMPREPLY[@]} == 0 && (directive & 4) == 0 )); then
+    COMPREPLY=($(compgen -f -- "$cur"))
+  fi
   return 0
 }
 
 # Register completion for wt function
 complete -F _wt_completions wt
 
-wt() {
-  # Check if we're in a git repo with .wt.yaml
-  local repo_root
-  repo_root=$(git rev-parse --show-toplevel 2>/dev/null)
-
-  if [[ -z "$repo_root" ]]; then
-    command wt "$@"
-    return $?
-  fi
-
-  # Check for .wt.yaml in repo root or if we're in a worktree, check main repo
-  local config_found=false
-  if [[ -f "$repo_root/.wt.yaml" ]]; then
-    config_found=true
-  else
-    local git_file="$repo_root/.git"
-    if [[ -f "$git_file" ]]; then
-      local gitdir=$(grep "^gitdir:" "$git_file" | cut -d' ' -f2)
-      if [[ -n "$gitdir" ]]; then
-        local main_repo=$(dirname $(dirname $(dirname "$gitdir")))
-        if [[ -f "$main_repo/.wt.yaml" ]]; then
-          config_found=true
-          repo_root="$main_repo"
-        fi
-      fi
-    fi
-  fi
-
-  if [[ "$config_found" != "true" ]]; then
-    command wt "$@"
-    return $?
+# Load the worktree environment (wt env) when entering a worktree and unload it on leaving.
+# wt only runs when the worktree holding $PWD (the nearest directory with a .git) changes,
+# or the loaded env file is newer than the last load, both checked without a process.
+_WT_ENV_STAMP="${TMPDIR:-/tmp}/wt-env-$$"
+_wt_env_hook() {
+  local dir="$PWD"
+  while [[ -n "$dir" && ! -e "$dir/.git" ]]; do
+    dir="${dir%/*}"
+  done
+  if [[ -n "${_WT_ENV_DIR+set}" && "$dir" == "$_WT_ENV_DIR" ]] && ! [[ -n "$_WT_ENV_FILE" && "$_WT_ENV_FILE" -nt "$_WT_ENV_STAMP" ]]; then
+    return
   fi
+  _WT_ENV_DIR="$dir"
+  { : >| "$_WT_ENV_STAMP"; } 2>/dev/null
+  eval "$(command wt env --shell bash 2>/dev/null)"
+}
+# Remove the stamp when the shell exits, running any EXIT trap already set after it
+_wt_exit_trap=$(trap -p EXIT)
+_wt_exit_trap=${_wt_exit_trap#trap -- }
+eval "_wt_exit_trap=${_wt_exit_trap% EXIT}"
+trap "rm -f \"\$_WT_ENV_STAMP\"${_wt_exit_trap:+; $_wt_exit_trap}" EXIT
+unset _wt_exit_trap
+if [[ ";${PROMPT_COMMAND:-};" != *";_wt_env_hook;"* ]]; then
+  PROMPT_COMMAND="_wt_env_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+
+# Prompt segment for the current worktree, e.g. "⎇ agent-3 [#3 ↑2 dirty]" (empty elsewhere).
+# Use in a prompt with: PS1='$(wt_prompt_info) \w\$ '
+wt_prompt_info() {
+  command wt prompt "$@" 2>/dev/null
+}
 
+wt() {
+  # Commands that may change directory write the target to $WT_CD_FILE. The binary
+  # finds the repository itself, whatever its layout, so the wrapper needn't.
   case "$1" in
-    create|delete|cleanup)
-      # Use temp file to communicate cd target from Go
-      local cdfile=$(mktemp)
-      trap "rm -f '$cdfile'" EXIT
+    create|delete|cleanup|switch|cd|exit|clone)
+      # Use temp file to communicate cd target from Go, restoring the EXIT trap afterwards
+      local cdfile=$(mktemp) exit_trap=$(trap -p EXIT)
+      trap "rm -f '$cdfile' \"\$_WT_ENV_STAMP\"" EXIT
       WT_CD_FILE="$cdfile" command wt "$@"
       local exit_code=$?
 
@@ -382,36 +203,10 @@ wt() {
         fi
       fi
       rm -f "$cdfile"
-      trap - EXIT
+      _wt_env_hook
+      eval "${exit_trap:-trap - EXIT}"
       return $exit_code
       ;;
-    cd)
-      local output
-      output=$(command wt "$@" 2>&1)
-      local exit_code=$?
-
-      if [[ $exit_code -eq 0 ]]; then
-        local target="$output"
-        if [[ -d "$target" ]]; then
-          cd "$target"
-        else
-          echo "$output"
-        fi
-      else
-        echo "$output"
-      fi
-      return $exit_code
-      ;;
-    exit)
-      local target
-      target=$(command wt root 2>/dev/null)
-      if [[ -d "$target" ]]; then
-        cd "$target"
-      else
-        echo "Could not find repository root"
-        return 1
-      fi
-      ;;
     *)
       command wt "$@"
       ;;
@@ -425,100 +220,71 @@ func GenerateFish() string {
 	return `# wt shell integration for fish
 # Add this to your ~/.config/fish/config.fish: wt init fish | source
 
-# Completions for wt
-complete -c wt -f  # Disable file completion by default
-
-# Subcommands
-complete -c wt -n "__fish_use_subcommand" -a "create" -d "Create a new worktree"
-complete -c wt -n "__fish_use_subcommand" -a "delete" -d "Delete a worktree"
-complete -c wt -n "__fish_use_subcommand" -a "cd" -d "Change to a worktree directory"
-complete -c wt -n "__fish_use_subcommand" -a "list" -d "List all worktrees"
-complete -c wt -n "__fish_use_subcommand" -a "info" -d "Show detailed information about a worktree"
-complete -c wt -n "__fish_use_subcommand" -a "cleanup" -d "Clean up merged worktrees"
-complete -c wt -n "__fish_use_subcommand" -a "exit" -d "Return to the main repository"
-complete -c wt -n "__fish_use_subcommand" -a "init" -d "Generate shell integration script"
-complete -c wt -n "__fish_use_subcommand" -a "root" -d "Print the main repository root path"
-complete -c wt -n "__fish_use_subcommand" -a "completion" -d "Generate shell completion script"
-complete -c wt -n "__fish_use_subcommand" -a "version" -d "Print the version number"
-complete -c wt -n "__fish_use_subcommand" -a "help" -d "Help about any command"
-
-# Helper function to get worktree names
-function __wt_worktrees
-  set -l repo_root (git rev-parse --show-toplevel 2>/dev/null)
-  if test -z "$repo_root"
-    return
-  end
-  if test -f "$repo_root/.git"
-    set -l gitdir (grep "^gitdir:" "$repo_root/.git" | cut -d' ' -f2)
-    if test -n "$gitdir"
-      set repo_root (dirname (dirname (dirname "$gitdir")))
+# Completions for wt. Candidates come from the binary (wt __complete), so
+# they match the installed version's commands, flags and worktrees.
+function __wt_complete
+  set -l args (commandline -opc)
+  set -e args[1]
+  set -l cur (commandline -ct)
+  set -l out (command wt __complete $args $cur 2>/dev/null)
+  or return
+
+  set -l directive 0
+  set -l found 0
+  for line in $out
+    # The last line is the directive, e.g. ":4"
+    if string match -q -- ':*' $line
+      set directive (string sub -s 2 -- $line)
+      continue
     end
+    # fish reads "value<TAB>description" natively
+    echo $line
+    set found 1
   end
-  if test -f "$repo_root/.wt.yaml"
-    set -l worktree_dir (grep "^worktree_dir:" "$repo_root/.wt.yaml" | cut -d' ' -f2 | tr -d '"' | tr -d "'")
-    test -z "$worktree_dir"; and set worktree_dir "worktrees"
-    if test -d "$repo_root/$worktree_dir"
-      ls -1 "$repo_root/$worktree_dir" 2>/dev/null
-    end
+
+  # Directive bits: 4 no file completion
+  if test $found -eq 0; and test (math "bitand($directive, 4)") -eq 0
+    __fish_complete_path $cur
   end
 end
 
-# Worktree name completion for cd, delete, and info
-complete -c wt -n "__fish_seen_subcommand_from cd delete info" -a "(__wt_worktrees)"
-
-# Branch completion for create --branch
-# Try --format first (Git 2.13+), fall back to parsing git branch output
-complete -c wt -n "__fish_seen_subcommand_from create" -s b -l branch -d "Use existing branch" -a "(git branch --format='%(refname:short)' 2>/dev/null; or git branch 2>/dev/null | sed 's/^[* ] //')"
-
-# Shell completion for init and completion commands
-complete -c wt -n "__fish_seen_subcommand_from init completion" -a "zsh bash fish"
-
-# Flags for delete
-complete -c wt -n "__fish_seen_subcommand_from delete" -s f -l force -d "Force deletion"
-complete -c wt -n "__fish_seen_subcommand_from delete" -s k -l keep-branch -d "Keep the associated branch"
-
-# Flags for cleanup
-complete -c wt -n "__fish_seen_subcommand_from cleanup" -s n -l dry-run -d "Show what would be deleted"
-complete -c wt -n "__fish_seen_subcommand_from cleanup" -s f -l force -d "Skip confirmation"
-complete -c wt -n "__fish_seen_subcommand_from cleanup" -s k -l keep-branch -d "Keep the associated branches"
-
-# Flags for list
-complete -c wt -n "__fish_seen_subcommand_from list" -s v -l verbose -d "Show detailed status"
+complete -c wt -f -a "(__wt_complete)"
 
-function wt
-  # Check if we're in a git repo
-  set -l repo_root (git rev-parse --show-toplevel 2>/dev/null)
-
-  if test -z "$repo_root"
-    command wt $argv
-    return $status
+# Load the worktree environment (wt env) when entering a worktree and unload it on leaving.
+# wt only runs when the worktree holding $PWD (the nearest directory with a .git) or the
+# loaded env file's modification time changes, both checked with builtins (path mtime
+# needs fish 3.5 or later).
+function __wt_env_hook --on-event fish_prompt
+  set -l dir $PWD
+  while test -n "$dir"; and not test -e "$dir/.git"
+    set dir (string replace -r '/[^/]*$' '' -- $dir)
   end
-
-  # Check for .wt.yaml
-  set -l config_found false
-  if test -f "$repo_root/.wt.yaml"
-    set config_found true
-  else
-    set -l git_file "$repo_root/.git"
-    if test -f "$git_file"
-      set -l gitdir (grep "^gitdir:" "$git_file" | cut -d' ' -f2)
-      if test -n "$gitdir"
-        set -l main_repo (dirname (dirname (dirname "$gitdir")))
-        if test -f "$main_repo/.wt.yaml"
-          set config_found true
-          set repo_root "$main_repo"
-        end
-      end
-    end
+  set -l mtime
+  if set -q _WT_ENV_FILE
+    set mtime (path mtime -- $_WT_ENV_FILE 2>/dev/null)
   end
-
-  if test "$config_found" != "true"
-    command wt $argv
-    return $status
+  if set -q __wt_env_dir; and test "$dir" = "$__wt_env_dir"; and test "$mtime" = "$__wt_env_mtime"
+    return
+  end
+  set -g __wt_env_dir $dir
+  command wt env --shell fish 2>/dev/null | source
+  set -g __wt_env_mtime
+  if set -q _WT_ENV_FILE
+    set __wt_env_mtime (path mtime -- $_WT_ENV_FILE 2>/dev/null)
   end
+end
 
+# Prompt segment for the current worktree, e.g. "⎇ agent-3 [#3 ↑2 dirty]" (empty elsewhere).
+# Call it from fish_prompt or fish_right_prompt
+function wt_prompt_info
+  command wt prompt $argv 2>/dev/null
+end
+
+function wt
+  # Commands that may change directory write the target to $WT_CD_FILE. The binary
+  # finds the repository itself, whatever its layout, so the wrapper needn't.
   switch $argv[1]
-    case create delete cleanup
+    case create delete cleanup cd exit switch clone
       # Use temp file to communicate cd target from Go
       set -l cdfile (mktemp)
       WT_CD_FILE="$cdfile" command wt $argv
@@ -532,32 +298,9 @@ function wt
         end
       end
       rm -f "$cdfile"
+      __wt_env_hook
       return $exit_code
 
-    case cd
-      set -l output (command wt $argv 2>&1)
-      set -l exit_code $status
-
-      if test $exit_code -eq 0
-        if test -d "$output"
-          cd "$output"
-        else
-          echo "$output"
-        end
-      else
-        echo "$output"
-      end
-      return $exit_code
-
-    case exit
-      set -l target (command wt root 2>/dev/null)
-      if test -d "$target"
-        cd "$target"
-      else
-        echo "Could not find repository root"
-        return 1
-      end
-
     case '*'
       command wt $argv
   end
@@ -574,7 +317,13 @@ func Generate(shell string) (string, error) {
 		return GenerateBash(), nil
 	case "fish":
 		return GenerateFish(), nil
+	case "powershell", "pwsh":
+		return GeneratePowerShell(), nil
+	case "nu", "nushell":
+		return GenerateNushell(), nil
+	case "elvish":
+		return GenerateElvish(), nil
 	default:
-		return "", fmt.Errorf("unsupported shell: %s (supported: zsh, bash, fish)", shell)
+		return "", fmt.Errorf("unsupported shell: %s (supported: zsh, bash, fish, powershell, nu, elvish)", shell)
 	}
 }
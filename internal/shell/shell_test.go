@@ -1,6 +1,9 @@
 package shell
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -11,12 +14,17 @@ func TestGenerateZsh(t *testing.T) {
 	// Check for required elements
 	requiredStrings := []string{
 		"wt()",
-		"git rev-parse --show-toplevel",
-		".wt.yaml",
 		"command wt",
-		"create)",
-		"exit)",
+		"command wt __complete",
+		"cd|exit|clone)",
+		"switch|cd|exit|clone)",
+		"WT_CD_FILE",
 		"cd \"$target\"",
+		"wt env --shell zsh",
+		"add-zsh-hook precmd _wt_env_hook",
+		"add-zsh-hook zshexit _wt_env_cleanup",
+		"wt_prompt_info()",
+		"prompt_wt()",
 	}
 
 	for _, s := range requiredStrings {
@@ -32,11 +40,14 @@ func TestGenerateBash(t *testing.T) {
 	// Check for required elements
 	requiredStrings := []string{
 		"wt()",
-		"git rev-parse --show-toplevel",
-		".wt.yaml",
 		"command wt",
-		"create)",
-		"exit)",
+		"command wt __complete",
+		"cd|exit|clone)",
+		"switch|cd|exit|clone)",
+		"WT_CD_FILE",
+		"wt env --shell bash",
+		"PROMPT_COMMAND=",
+		"wt_prompt_info()",
 	}
 
 	for _, s := range requiredStrings {
@@ -52,11 +63,12 @@ func TestGenerateFish(t *testing.T) {
 	// Check for required elements
 	requiredStrings := []string{
 		"function wt",
-		"git rev-parse --show-toplevel",
-		".wt.yaml",
 		"command wt",
-		"case create",
-		"case exit",
+		"case create delete cleanup cd exit switch clone",
+		"WT_CD_FILE",
+		"wt env --shell fish",
+		"--on-event fish_prompt",
+		"function wt_prompt_info",
 	}
 
 	for _, s := range requiredStrings {
@@ -74,6 +86,11 @@ func TestGenerate(t *testing.T) {
 		{"zsh", false},
 		{"bash", false},
 		{"fish", false},
+		{"powershell", false},
+		{"pwsh", false},
+		{"nu", false},
+		{"nushell", false},
+		{"elvish", false},
 		{"invalid", true},
 		{"", true},
 	}
@@ -98,7 +115,7 @@ func TestGenerate(t *testing.T) {
 }
 
 func TestShellScriptsHaveComments(t *testing.T) {
-	shells := []string{"zsh", "bash", "fish"}
+	shells := []string{"zsh", "bash", "fish", "powershell", "nu", "elvish"}
 
 	for _, sh := range shells {
 		t.Run(sh, func(t *testing.T) {
@@ -110,17 +127,247 @@ func TestShellScriptsHaveComments(t *testing.T) {
 	}
 }
 
-func TestShellScriptsHandleWorktreeDetection(t *testing.T) {
-	// All shell scripts should handle the case where we're in a worktree
-	// and need to find the main repo's .wt.yaml
-	shells := []string{"zsh", "bash", "fish"}
+func TestShellCompletionsDelegateToBinary(t *testing.T) {
+	// Completions come from wt __complete rather than parsing .wt.yaml in the shell
+	shells := []string{"zsh", "bash", "fish", "powershell", "nu", "elvish"}
 
 	for _, sh := range shells {
 		t.Run(sh, func(t *testing.T) {
 			script, _ := Generate(sh)
-			// Should check for gitdir in .git file (worktree indicator)
-			if !strings.Contains(script, "gitdir") {
-				t.Error("script should handle worktree detection via gitdir")
+			if !strings.Contains(script, "__complete") {
+				t.Error("script should complete through wt __complete")
+			}
+			if strings.Contains(script, "worktree_dir") {
+				t.Error("script should not read worktree_dir from .wt.yaml")
+			}
+		})
+	}
+}
+
+func TestShellScriptsLeaveDiscoveryToBinary(t *testing.T) {
+	// The binary finds the repository in any layout (linked worktrees, bare
+	// repositories, separate git dirs, submodules); the wrappers mustn't guess
+	shells := []string{"zsh", "bash", "fish", "powershell", "nu", "elvish"}
+
+	for _, sh := range shells {
+		t.Run(sh, func(t *testing.T) {
+			script, _ := Generate(sh)
+			for _, s := range []string{"gitdir", ".wt.yaml", "--show-toplevel"} {
+				if strings.Contains(script, s) {
+					t.Errorf("script should not look for the repository itself, found %q", s)
+				}
+			}
+		})
+	}
+}
+
+// setupCdTest creates a repository with a .wt.yaml and a fake wt binary on PATH that
+// writes a target directory to $WT_CD_FILE for "wt cd" and offers two worktrees to
+// "wt __complete". It returns the repository,
+// the target directory and the environment to run a shell with.
+func setupCdTest(t *testing.T) (repo, target string, env []string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	repo = filepath.Join(dir, "repo")
+	target = filepath.Join(dir, "target")
+	bin := filepath.Join(dir, "bin")
+	for _, d := range []string{repo, target, bin} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".wt.yaml"), []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := "#!/bin/sh\ncase \"$1\" in\n  cd) printf '%s\\n' \"$WT_TEST_TARGET\" > \"$WT_CD_FILE\" ;;\n  __complete) printf 'feature-a\\tfeature-a dirty\\nfix-b\\n:4\\n' ;;\n  env) [ -n \"$WT_TEST_ENV_LOG\" ] && echo env >> \"$WT_TEST_ENV_LOG\" && printf \"_WT_ENV_FILE='%s'\\n\" \"$WT_TEST_ENV_FILE\" ;;\nesac\nexit ${WT_TEST_EXIT:-0}\n"
+	if err := os.WriteFile(filepath.Join(bin, "wt"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	env = append(os.Environ(),
+		"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"WT_TEST_TARGET="+target,
+	)
+	return repo, target, env
+}
+
+// runCdTest runs a shell command line in the repository and checks that it printed
+// the target directory, i.e. the wrapper changed into it
+func runCdTest(t *testing.T, repo, target string, env []string, name string, args ...string) {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = repo
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s failed: %v\n%s", name, err, out)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	got, _ := filepath.EvalSymlinks(strings.TrimSpace(lines[len(lines)-1]))
+	want, _ := filepath.EvalSymlinks(target)
+	if got != want {
+		t.Errorf("directory after wt cd = %q, want %q\noutput:\n%s", got, want, out)
+	}
+}
+
+func TestBashCdExecution(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	repo, target, env := setupCdTest(t)
+	script := filepath.Join(t.TempDir(), "wt.bash")
+	if err := os.WriteFile(script, []byte(GenerateBash()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runCdTest(t, repo, target, env, "bash", "-c", "source "+script+" && wt cd foo && pwd")
+}
+
+func TestBashCdExecutionBareLayout(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	_, target, env := setupCdTest(t)
+
+	// A bare repository with .wt.yaml only in its default worktree, run from another
+	// worktree: the .git file points into repo.git, not a checkout with .wt.yaml
+	dir := t.TempDir()
+	upstream := filepath.Join(dir, "upstream")
+	bare := filepath.Join(dir, "proj", "repo.git")
+	feature := filepath.Join(dir, "proj", "feature")
+	for _, args := range [][]string{
+		{"init", "-q", upstream},
+		{"-C", upstream, "-c", "user.name=Test", "-c", "user.email=test@test.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"clone", "-q", "--bare", upstream, bare},
+		{"-C", bare, "worktree", "add", "-q", "-b", "feature", feature},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	script := filepath.Join(t.TempDir(), "wt.bash")
+	if err := os.WriteFile(script, []byte(GenerateBash()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runCdTest(t, feature, target, env, "bash", "-c", "source "+script+" && wt cd main && pwd")
+}
+
+func TestBashEnvHookCaching(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	repo, target, env := setupCdTest(t)
+	dir := t.TempDir()
+	envLog := filepath.Join(dir, "env.log")
+	envFile := filepath.Join(dir, "wt-env")
+	if err := os.WriteFile(envFile, []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	env = append(env, "WT_TEST_ENV_LOG="+envLog, "WT_TEST_ENV_FILE="+envFile, "TMPDIR="+dir)
+
+	script := filepath.Join(t.TempDir(), "wt.bash")
+	if err := os.WriteFile(script, []byte(GenerateBash()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	count := `echo "$(wc -l < "$WT_TEST_ENV_LOG")"`
+	commands := []string{
+		"source " + script,
+		// The first prompt loads; later prompts in the same worktree don't
+		"_wt_env_hook", "_wt_env_hook", count,
+		// Moving within the worktree doesn't reload
+		"cd sub", "_wt_env_hook", count,
+		// A change to the env file reloads
+		"sleep 0.05", "echo A=2 >> " + envFile, "_wt_env_hook", "_wt_env_hook", count,
+		// Leaving the worktree reloads
+		"cd " + target, "_wt_env_hook", count,
+	}
+	cmd := exec.Command("bash", "-c", strings.Join(commands, "\n"))
+	cmd.Dir = repo
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash failed: %v\n%s", err, out)
+	}
+	if got := strings.Fields(string(out)); strings.Join(got, " ") != "1 1 2 3" {
+		t.Errorf("wt env runs after each step = %v, want [1 1 2 3]\noutput:\n%s", got, out)
+	}
+}
+
+func TestBashEnvStampCleanup(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	repo, _, env := setupCdTest(t)
+	dir := t.TempDir()
+	env = append(env, "TMPDIR="+dir)
+
+	script := filepath.Join(t.TempDir(), "wt.bash")
+	if err := os.WriteFile(script, []byte(GenerateBash()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	commands := []string{
+		"trap 'echo user trap' EXIT",
+		"source " + script,
+		"_wt_env_hook",
+		`[[ -e "$_WT_ENV_STAMP" ]] && echo stamped`,
+		// wt's own EXIT trap while it runs mustn't drop the others
+		"wt cd foo",
+	}
+	cmd := exec.Command("bash", "-c", strings.Join(commands, "\n"))
+	cmd.Dir = repo
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash failed: %v\n%s", err, out)
+	}
+	if got := strings.Fields(string(out)); strings.Join(got, " ") != "stamped user trap" {
+		t.Errorf("output = %q, want the stamp to be written and the user's EXIT trap to run", out)
+	}
+	if stamps, _ := filepath.Glob(filepath.Join(dir, "wt-env-*")); len(stamps) != 0 {
+		t.Errorf("expected the stamp to be removed on exit, found %v", stamps)
+	}
+}
+
+func TestBashCompletionExecution(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	repo, _, env := setupCdTest(t)
+	script := filepath.Join(t.TempDir(), "wt.bash")
+	if err := os.WriteFile(script, []byte(GenerateBash()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		words string
+		want  string
+	}{
+		{`wt cd ""`, "feature-a fix-b"},
+		{`wt cd fe`, "feature-a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.words, func(t *testing.T) {
+			line := "source " + script + " && COMP_WORDS=(" + tt.words + ") && COMP_CWORD=2 && _wt_completions && echo \"${COMPREPLY[*]}\""
+			cmd := exec.Command("bash", "-c", line)
+			cmd.Dir = repo
+			cmd.Env = env
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("bash failed: %v\n%s", err, out)
+			}
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("completions = %q, want %q", got, tt.want)
 			}
 		})
 	}